@@ -1,10 +1,87 @@
 package main
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/goku/compiler/enums/bk"
 	"github.com/mebyus/ku/internal/ku/builder"
 )
 
-func build(path string) error {
-	r := builder.Build(&builder.Config{Unit: path})
-	return r.Error
+var buildButler = &butler.Butler{
+	Name: "build",
+
+	Short: "Build specified Ku unit into C code, object file or executable",
+	Usage: "[options] <unit>",
+
+	Params: butler.NewParams(
+		butler.Param{
+			Name:    "out",
+			Alias:   "o",
+			Desc:    "Path to output artifact",
+			Default: "",
+			Kind:    butler.String,
+		},
+		butler.Param{
+			Name:    "build-kind",
+			Alias:   "k",
+			Desc:    "Specifies build kind (debug, test, safe or fast)",
+			Default: bk.Debug.String(),
+			Kind:    butler.String,
+		},
+		butler.Param{
+			Name:    "phase",
+			Alias:   "p",
+			Desc:    "Specifies build output (c, obj or exe)",
+			Default: builder.PhaseObj.String(),
+			Kind:    butler.String,
+		},
+	),
+
+	Exec: execBuild,
+}
+
+func execBuild(r *butler.Butler, list []string) error {
+	if len(list) == 0 {
+		return errors.New("unit must be specified")
+	}
+	if len(list) != 1 {
+		return errors.New("only one unit may be specified")
+	}
+
+	unit := strings.TrimSpace(list[0])
+	if unit == "" {
+		return errors.New("empty unit path")
+	}
+
+	kind, err := bk.Parse(r.Params.Get("build-kind").Str())
+	if err != nil {
+		return err
+	}
+	phase, err := builder.ParsePhase(r.Params.Get("phase").Str())
+	if err != nil {
+		return err
+	}
+
+	out := r.Params.Get("out").Str()
+	if out == "." || out == ".." {
+		return errors.New("invalid output path")
+	}
+	if out != "" {
+		out = filepath.Clean(out)
+	}
+
+	res := builder.Build(&builder.Config{
+		Unit:      unit,
+		OutPath:   out,
+		Phase:     phase,
+		BuildKind: kind,
+		Output:    os.Stderr,
+
+		DiagFormat: diagFormat,
+	})
+	return res.Error
 }
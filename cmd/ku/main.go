@@ -1,34 +1,61 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/internal/ku/builder"
 	"github.com/mebyus/ku/internal/ku/parser"
 	"github.com/mebyus/ku/internal/ku/sx"
 )
 
+// Params accepted by all commands.
+var globals = butler.NewParams(
+	butler.Param{
+		Name:    "diag-format",
+		Desc:    "Specifies diagnostics output format (text or json)",
+		Default: "text",
+		Kind:    butler.String,
+	},
+)
+
+// Selected format of diagnostics output.
+var diagFormat sx.ErrorFormat
+
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Fprintf(os.Stderr, "target file of directory not specified\n")
-		os.Exit(1)
+	args, err := butler.ParseGlobal(globals, os.Args[1:])
+	if err == nil {
+		diagFormat, err = sx.ParseErrorFormat(globals.Get("diag-format").Str())
+	}
+	if err == nil && len(args) == 0 {
+		err = errors.New("command not specified")
+	}
+	if err == nil && len(args) == 1 {
+		err = errors.New("target file of directory not specified")
 	}
 
-	var err error
-	switch os.Args[1] {
-	case "parse":
-		paths := os.Args[2:]
-		err = parse(paths)
-	case "build":
-		paths := os.Args[2:]
-		err = build(paths[0])
-	case "walk":
-		path := os.Args[2]
-		err = walk(path)
+	if err == nil {
+		switch args[0] {
+		case "parse":
+			err = parse(args[1:])
+		case "build":
+			err = butler.Run(buildButler, os.Stderr, args[1:])
+		case "walk":
+			err = walk(args[1])
+		default:
+			err = fmt.Errorf("unknown command \"%s\"", args[0])
+		}
 	}
 
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s\n", err)
+		var r *builder.ReportError
+		if diagFormat == sx.TextErrors || !errors.As(err, &r) {
+			// in json format each reported diagnostic is already
+			// written as a separate record
+			sx.WriteError(nil, os.Stderr, diagFormat, &sx.Error{Short: err.Error()})
+		}
 		os.Exit(1)
 	}
 }
@@ -86,8 +113,11 @@ func parse(paths []string) error {
 	for _, x := range texts {
 		t := parser.ParseText(x)
 		for _, e := range t.Errors {
-			pos := pool.DecodePin(e.Pin)
-			fmt.Fprintf(os.Stderr, "%s: %s\n", pos, e.Short)
+			sx.WriteError(pool, os.Stderr, diagFormat, &sx.Error{
+				Short: e.Short,
+				Pin:   e.Pin,
+				Code:  "parse",
+			})
 			n += 1
 		}
 
@@ -32,6 +32,23 @@ type Butler struct {
 	// Template for parameters (flags) parsing.
 	Params ParamBox
 
+	// Params which are accepted by this command and all of its subcommands.
+	// Global params may be placed anywhere among arguments (before "--").
+	//
+	// Only root command global params are taken into account.
+	Global ParamBox
+
+	// Optional function which is called after global params are parsed,
+	// but before arguments are dispatched to subcommands.
+	//
+	// Only root command setup is taken into account.
+	Setup func(r *Butler) error
+
+	// Global params of root command.
+	//
+	// This field is calculated after init is called.
+	global ParamBox
+
 	// Contains command path elements. Each element in this slice is a subcommand name
 	// that leads to this command. Always starts from root command. Last element
 	// always contains name of this command.
@@ -77,8 +94,9 @@ func (r *Butler) GetSub(name string) *Butler {
 	return nil
 }
 
-func (r *Butler) init(elems []string) {
+func (r *Butler) init(elems []string, global ParamBox) {
 	r.elems = elems
+	r.global = global
 	if r.Name == "" {
 		panic(fmt.Sprintf("empty subcommand name in \"%s\"", r.path()))
 	}
@@ -97,7 +115,7 @@ func (r *Butler) init(elems []string) {
 		return
 	}
 	if len(r.Subs) == 1 {
-		r.Subs[0].init(r.elems)
+		r.Subs[0].init(r.elems, global)
 		return
 	}
 
@@ -110,7 +128,7 @@ func (r *Butler) init(elems []string) {
 		}
 		set[name] = struct{}{}
 
-		sub.init(r.elems)
+		sub.init(r.elems, global)
 	}
 }
 
@@ -122,10 +140,21 @@ func (r *Butler) debugTreePrintPath(out io.Writer) {
 }
 
 func Run(r *Butler, out io.Writer, args []string) error {
-	r.init(nil)
+	r.init(nil, r.Global)
 	if debug {
 		r.debugTreePrintPath(out)
 	}
+
+	args, err := ParseGlobal(r.Global, args)
+	if err != nil {
+		return fmt.Errorf("parse \"%s\" global args: %v", r.path(), err)
+	}
+	if r.Setup != nil {
+		err = r.Setup(r)
+		if err != nil {
+			return err
+		}
+	}
 	return r.run(out, args)
 }
 
@@ -213,15 +242,22 @@ func (r *Butler) execHelp() string {
 		buf.nl()
 	}
 
-	if r.Params == nil {
-		return buf.String()
+	if r.Params != nil {
+		writeParams(&buf, "Available options:", r.Params.Params())
+	}
+	if r.global != nil {
+		writeParams(&buf, "Global options:", r.global.Params())
 	}
-	params := r.Params.Params()
+
+	return buf.String()
+}
+
+func writeParams(buf *formatBuffer, title string, params []Param) {
 	if len(params) == 0 {
-		return buf.String()
+		return
 	}
 
-	buf.puts("Available options:")
+	buf.puts(title)
 	buf.nl()
 	buf.nl()
 	paramNamesColumnWidth := maxParamNameWidth(params) + 4
@@ -245,8 +281,6 @@ func (r *Butler) execHelp() string {
 		buf.nl()
 	}
 	buf.nl()
-
-	return buf.String()
 }
 
 func (r *Butler) displayHelp(out io.Writer) error {
@@ -289,6 +323,10 @@ func (r *Butler) help() string {
 	}
 
 	buf.nl()
+	if r.global != nil {
+		writeParams(&buf, "Global options:", r.global.Params())
+	}
+
 	buf.puts(fmt.Sprintf(`Use "%s help <command>" for more information about a specific command.`, r.path()))
 	buf.nl()
 
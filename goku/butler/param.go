@@ -1,6 +1,7 @@
 package butler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -104,7 +105,20 @@ func (p *Param) Bind(v string) error {
 			return fmt.Errorf("bad integer value \"%s\"", v)
 		}
 		p.val = n
-	// TODO: look into omnicube/go-lib/butler for this code
+	case List:
+		// each use of list param appends one more value
+		list, _ := p.val.([]string)
+		p.val = append(list, v)
+	case CommaList:
+		var list []string
+		for _, s := range strings.Split(v, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			list = append(list, s)
+		}
+		p.val = list
 	default:
 		panic(fmt.Sprintf("unxpected kind (=%d)", p.Kind))
 	}
@@ -201,6 +215,36 @@ func Parse(box ParamBox, args []string) ([]string, error) {
 	return unbound, nil
 }
 
+// ParseGlobal extracts params described by ParamBox container from supplied
+// arguments. Unlike Parse, params may be placed anywhere among other arguments
+// (before "--").
+//
+// If parsing was successful returns remaining args (in their original order)
+// and error otherwise. Arguments which are not recognized as params from
+// container are left intact.
+func ParseGlobal(box ParamBox, args []string) ([]string, error) {
+	r := parser{
+		args: args,
+		box:  box,
+	}
+	r.index()
+	if len(r.m) == 0 {
+		return args, nil
+	}
+
+	rest, err := r.extract()
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.apply()
+	if err != nil {
+		return nil, err
+	}
+
+	return rest, nil
+}
+
 type parser struct {
 	args []string
 
@@ -270,7 +314,7 @@ func (r *parser) bind() ([]string, error) {
 			return nil, nil
 		}
 		if arg == "" {
-			panic("empty arg")
+			return nil, errors.New("empty argument")
 		}
 
 		if arg == "--" {
@@ -294,6 +338,44 @@ func (r *parser) bind() ([]string, error) {
 	}
 }
 
+// extract binds known params and returns the rest of arguments.
+func (r *parser) extract() ([]string, error) {
+	var rest []string
+	for {
+		arg, ok := r.next()
+		if !ok {
+			return rest, nil
+		}
+
+		if arg == "--" {
+			// leave explicit end of param arguments
+			// for the subsequent parsing
+			rest = append(rest, arg)
+			return append(rest, r.tail()...), nil
+		}
+
+		suffix, ok := "", false
+		if arg != "" {
+			suffix, ok = ParseParamPrefix(arg)
+		}
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+		name, _, _ := strings.Cut(suffix, "=")
+		_, ok = r.m[name]
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+
+		err := r.bindSuffix(suffix)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
 func (r *parser) bindSuffix(suffix string) error {
 	j := strings.Index(suffix, "=")
 	if j < 0 {
@@ -328,7 +410,7 @@ func (r *parser) bindNextValueByParamName(name string) error {
 		return fmt.Errorf("no value provided for param \"%s\"", name)
 	}
 	if v == "" {
-		panic("empty arg")
+		return fmt.Errorf("empty value provided for param \"%s\"", name)
 	}
 
 	return bindParamAndValue(p, v)
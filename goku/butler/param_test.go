@@ -156,6 +156,18 @@ func TestParse(t *testing.T) {
 			want:    nil,
 			wantBox: &testBox2{b: true},
 		},
+		{
+			name:    "10 empty param value",
+			box:     &testBox2{},
+			args:    []string{"--a", ""},
+			wantErr: true,
+		},
+		{
+			name:    "11 empty argument",
+			box:     &testBox2{},
+			args:    []string{""},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -178,3 +190,119 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name string
+		kind ParamKind
+		args []string
+		want []string
+	}{
+		{
+			name: "1 default value",
+			kind: List,
+			args: nil,
+			want: []string{},
+		},
+		{
+			name: "2 single value",
+			kind: List,
+			args: []string{"--dir", "a"},
+			want: []string{"a"},
+		},
+		{
+			name: "3 repeated values",
+			kind: List,
+			args: []string{"--dir", "a", "--dir=b"},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "4 comma list",
+			kind: CommaList,
+			args: []string{"--dir", "a, b,,c"},
+			want: []string{"a", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := NewParams(Param{
+				Name:    "dir",
+				Default: []string{},
+				Kind:    tt.kind,
+			})
+			_, err := Parse(box, tt.args)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got := box.Get("dir").List()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGlobal(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantBox ParamBox
+		wantErr bool
+	}{
+		{
+			name:    "1 no args",
+			args:    nil,
+			want:    nil,
+			wantBox: &testBox2{},
+		},
+		{
+			name:    "2 no matching args",
+			args:    []string{"build", "--c", "x"},
+			want:    []string{"build", "--c", "x"},
+			wantBox: &testBox2{},
+		},
+		{
+			name:    "3 param after command",
+			args:    []string{"build", "--a=json", "x"},
+			want:    []string{"build", "x"},
+			wantBox: &testBox2{a: "json"},
+		},
+		{
+			name:    "4 param with separate value",
+			args:    []string{"--a", "json", "build", "--b", "x"},
+			want:    []string{"build", "x"},
+			wantBox: &testBox2{a: "json", b: true},
+		},
+		{
+			name:    "5 param after explicit end",
+			args:    []string{"run", "--", "--a=json"},
+			want:    []string{"run", "--", "--a=json"},
+			wantBox: &testBox2{},
+		},
+		{
+			name:    "6 no value",
+			args:    []string{"build", "--a"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := &testBox2{}
+			got, err := ParseGlobal(box, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseGlobal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGlobal() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(box, tt.wantBox) {
+				t.Errorf("ParamBox = %v, want %v", box, tt.wantBox)
+			}
+		})
+	}
+}
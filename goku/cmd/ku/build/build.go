@@ -31,6 +31,12 @@ var Butler = &butler.Butler{
 			Default: bk.Debug.String(),
 			Kind:    butler.String,
 		},
+		butler.Param{
+			Name:    "vm",
+			Desc:    "Build VM executable instead of object file or executable",
+			Default: false,
+			Kind:    butler.Boolean,
+		},
 		butler.Param{
 			Name:    "cc-include-dirs",
 			Desc:    "List of additional include directories for C compiler",
@@ -73,9 +79,15 @@ func exec(r *butler.Butler, list []string) error {
 		out = filepath.Clean(out)
 	}
 
+	var phase builder.Phase
+	if r.Params.Get("vm").Bool() {
+		phase = builder.PhaseVM
+	}
+
 	return builder.Build(&builder.Config{
 		Unit:      unit,
 		OutPath:   out,
+		Phase:     phase,
 		BuildKind: kind,
 	})
 }
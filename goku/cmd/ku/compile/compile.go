@@ -2,6 +2,7 @@ package compile
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 
 	"github.com/mebyus/ku/goku/butler"
@@ -18,10 +19,11 @@ var Butler = &butler.Butler{
 
 	Params: butler.NewParams(
 		butler.Param{
-			Name:  "out",
-			Alias: "o",
-			Desc:  "Path to output object file",
-			Kind:  butler.String,
+			Name:    "out",
+			Alias:   "o",
+			Desc:    "Path to output object file (derived from first file name if omitted)",
+			Default: "",
+			Kind:    butler.String,
 		},
 		butler.Param{
 			Name:    "build-kind",
@@ -49,9 +51,12 @@ func exec(r *butler.Butler, files []string) error {
 	if err != nil {
 		return err
 	}
-	out := filepath.Clean(r.Params.Get("out").Str())
-	if out == "" || out == "." || out == ".." {
-		return errors.New("empty or invalid output path")
+	out := r.Params.Get("out").Str()
+	if out != "" {
+		out = filepath.Clean(out)
+		if out == "." || out == ".." {
+			return errors.New("invalid output path")
+		}
 	}
 
 	return compile(&builder.CompileConfig{
@@ -66,6 +71,10 @@ func compile(c *builder.CompileConfig, files []string) error {
 
 	texts := make([]*sm.Text, 0, len(files))
 	for _, path := range files {
+		path = filepath.Clean(path)
+		if path == "." || path == ".." {
+			return fmt.Errorf("invalid source file path \"%s\"", path)
+		}
 		text, err := pool.Load(path)
 		if err != nil {
 			return err
@@ -73,5 +82,6 @@ func compile(c *builder.CompileConfig, files []string) error {
 		texts = append(texts, text)
 	}
 
+	c.Pool = pool
 	return builder.CompileTexts(c, texts)
 }
@@ -0,0 +1,120 @@
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/goku/compiler/builder"
+	"github.com/mebyus/ku/goku/compiler/enums/smk"
+	"github.com/mebyus/ku/goku/compiler/enums/tpk"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+)
+
+var Butler = &butler.Butler{
+	Name: "layout",
+
+	Short: "Print memory layout (size, alignment, field offsets and padding) of a type",
+	Usage: "[options] <unit> <type>",
+
+	Params: butler.NewParams(
+		butler.Param{
+			Name:    "target",
+			Alias:   "t",
+			Desc:    "Data model of compilation target (amd64, arm64 or 386)",
+			Default: stg.DefaultDataModel.Name,
+			Kind:    butler.String,
+		},
+	),
+
+	Exec: exec,
+}
+
+func exec(r *butler.Butler, list []string) error {
+	if len(list) != 2 {
+		return errors.New("unit and type name must be specified")
+	}
+
+	unit := strings.TrimSpace(list[0])
+	if unit == "" {
+		return errors.New("empty unit path")
+	}
+	unit = strings.TrimPrefix(unit, "./src/")
+
+	name := strings.TrimSpace(list[1])
+	if name == "" {
+		return errors.New("empty type name")
+	}
+
+	model, err := stg.LookupDataModel(r.Params.Get("target").Str())
+	if err != nil {
+		return err
+	}
+
+	bundle, err := builder.Load(&builder.Config{
+		Unit:  unit,
+		Model: model,
+	})
+	if err != nil {
+		return err
+	}
+
+	typ, err := lookupType(bundle, unit, name)
+	if err != nil {
+		return err
+	}
+	return Print(os.Stdout, name, typ)
+}
+
+func lookupType(b *builder.Bundle, unit string, name string) (*stg.Type, error) {
+	path := sm.Local(unit)
+	for _, u := range b.Units {
+		if u.Path != path {
+			continue
+		}
+
+		s := u.Scope.Get(name)
+		if s == nil {
+			return nil, fmt.Errorf("unit \"%s\" has no symbol \"%s\"", unit, name)
+		}
+		if s.Kind != smk.Type {
+			return nil, fmt.Errorf("symbol \"%s\" is %s, not a type", name, s.Kind)
+		}
+		def, ok := s.Def.(stg.SymDefType)
+		if !ok {
+			return nil, fmt.Errorf("type \"%s\" is not implemented", name)
+		}
+		return def.Type, nil
+	}
+	return nil, fmt.Errorf("unit \"%s\" not found", unit)
+}
+
+// Print writes layout of a given type into w.
+func Print(w io.Writer, name string, t *stg.Type) error {
+	var b strings.Builder
+
+	base := t
+	if t.Kind == tpk.Custom {
+		base = t.Def.(*stg.Custom).Type
+	}
+	fmt.Fprintf(&b, "type %s %s\n", name, base)
+	fmt.Fprintf(&b, "size=%d align=%d\n", t.Size, stg.AlignOf(t))
+
+	slots := t.Slots()
+	if len(slots) != 0 {
+		b.WriteString("\noffset  size  align  field\n")
+	}
+	for _, s := range slots {
+		fmt.Fprintf(&b, "%6d  %4d  %5d  %s: %s\n", s.Offset, s.Type.Size, stg.AlignOf(s.Type), s.Name, s.Type)
+		if s.Padding != 0 {
+			fmt.Fprintf(&b, "%6d  %4d         <padding>\n", s.Offset+s.Type.Size, s.Padding)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
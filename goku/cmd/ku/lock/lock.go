@@ -0,0 +1,26 @@
+package lock
+
+import (
+	"errors"
+
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/goku/compiler/pkgs"
+)
+
+var Butler = &butler.Butler{
+	Name: "lock",
+
+	Short: "Write content hashes of packages listed in pkg.ku into pkg.lock",
+	Usage: "[options]",
+
+	Exec: exec,
+}
+
+func exec(r *butler.Butler, list []string) error {
+	if len(list) != 0 {
+		return errors.New("command does not accept arguments")
+	}
+
+	// project root is the current directory
+	return pkgs.WriteLock(".")
+}
@@ -1,14 +1,16 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/mebyus/ku/goku/butler"
 	"github.com/mebyus/ku/goku/cmd/ku/build"
 	"github.com/mebyus/ku/goku/cmd/ku/compile"
+	"github.com/mebyus/ku/goku/cmd/ku/layout"
 	"github.com/mebyus/ku/goku/cmd/ku/lex"
+	"github.com/mebyus/ku/goku/cmd/ku/lock"
 	"github.com/mebyus/ku/goku/cmd/ku/test"
+	"github.com/mebyus/ku/goku/compiler/diag"
 )
 
 func main() {
@@ -17,9 +19,10 @@ func main() {
 	}
 	args := os.Args[1:]
 
+	diag.Color = diag.DetectColor(os.Stderr)
 	err := butler.Run(root, os.Stderr, args)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		diag.Print(os.Stderr, err)
 		os.Exit(1)
 	}
 }
@@ -30,10 +33,31 @@ var root = &butler.Butler{
 	Short: "Ku is a command line tool for managing Ku source code.",
 	Usage: "[command] [arguments]",
 
+	Global: butler.NewParams(
+		butler.Param{
+			Name:    "diag-format",
+			Desc:    "Specifies diagnostics output format (text or json)",
+			Default: diag.TextFormat.String(),
+			Kind:    butler.String,
+		},
+	),
+	Setup: setup,
+
 	Subs: []*butler.Butler{
 		lex.Butler,
 		compile.Butler,
 		build.Butler,
 		test.Butler,
+		lock.Butler,
+		layout.Butler,
 	},
 }
+
+func setup(r *butler.Butler) error {
+	format, err := diag.ParseOutputFormat(r.Global.Get("diag-format").Str())
+	if err != nil {
+		return err
+	}
+	diag.Output = format
+	return nil
+}
@@ -23,6 +23,12 @@ var Butler = &butler.Butler{
 			Default: bk.Debug.String(),
 			Kind:    butler.String,
 		},
+		butler.Param{
+			Name:    "vm",
+			Desc:    "Run tests on VM instead of native test executable",
+			Default: false,
+			Kind:    butler.Boolean,
+		},
 	),
 
 	Exec: exec,
@@ -51,8 +57,14 @@ func exec(r *butler.Butler, list []string) error {
 		return err
 	}
 
+	var phase builder.Phase
+	if r.Params.Get("vm").Bool() {
+		phase = builder.PhaseVM
+	}
+
 	return builder.Test(&builder.Config{
 		Unit:      unit,
+		Phase:     phase,
 		BuildKind: kind,
 	})
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/mebyus/ku/goku/butler"
 	"github.com/mebyus/ku/goku/compiler/enums/bk"
 	"github.com/mebyus/ku/goku/kub/builder"
+	"github.com/mebyus/ku/goku/kub/eval"
 )
 
 var Butler = &butler.Butler{
@@ -29,6 +30,18 @@ var Butler = &butler.Butler{
 			Default: bk.Debug.String(),
 			Kind:    butler.String,
 		},
+		butler.Param{
+			Name:    "target-os",
+			Desc:    "Specifies target operating system (host system by default)",
+			Default: "",
+			Kind:    butler.String,
+		},
+		butler.Param{
+			Name:    "target-arch",
+			Desc:    "Specifies target architecture (host architecture by default)",
+			Default: "",
+			Kind:    butler.String,
+		},
 	),
 
 	Exec: exec,
@@ -40,10 +53,14 @@ func exec(r *butler.Butler, modules []string) error {
 	}
 
 	name := modules[0]
-	return build(name, r.Params.Get("out").Str(), r.Params.Get("build-kind").Str())
+	target := eval.Target{
+		OS:   r.Params.Get("target-os").Str(),
+		Arch: r.Params.Get("target-arch").Str(),
+	}
+	return build(name, r.Params.Get("out").Str(), r.Params.Get("build-kind").Str(), target)
 }
 
-func build(name string, out string, kind string) error {
+func build(name string, out string, kind string, target eval.Target) error {
 	k, err := bk.Parse(kind)
 	if err != nil {
 		return err
@@ -54,6 +71,7 @@ func build(name string, out string, kind string) error {
 			ModuleName:      name,
 			BuildKind:       k,
 			PackageFilePath: "pkg.kub",
+			Target:          target,
 		},
 		OutputPath: out,
 	})
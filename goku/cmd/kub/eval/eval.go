@@ -5,6 +5,8 @@ import (
 
 	"github.com/mebyus/ku/goku/butler"
 	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/enums/bk"
+	"github.com/mebyus/ku/goku/compiler/enums/bm"
 	"github.com/mebyus/ku/goku/compiler/sm"
 	"github.com/mebyus/ku/goku/kub/eval"
 	"github.com/mebyus/ku/goku/kub/parser"
@@ -16,6 +18,21 @@ var Butler = &butler.Butler{
 	Short: "eval a given unit build file and print results",
 	Usage: "[options] [file]",
 
+	Params: butler.NewParams(
+		butler.Param{
+			Name:    "target-os",
+			Desc:    "Specifies target operating system (host system by default)",
+			Default: "",
+			Kind:    butler.String,
+		},
+		butler.Param{
+			Name:    "target-arch",
+			Desc:    "Specifies target architecture (host architecture by default)",
+			Default: "",
+			Kind:    butler.String,
+		},
+	),
+
 	Exec: exec,
 }
 
@@ -25,10 +42,14 @@ func exec(r *butler.Butler, files []string) error {
 	}
 
 	path := files[0]
-	return evalFile(path)
+	target := eval.Target{
+		OS:   r.Params.Get("target-os").Str(),
+		Arch: r.Params.Get("target-arch").Str(),
+	}
+	return evalFile(path, target)
 }
 
-func evalFile(path string) error {
+func evalFile(path string, target eval.Target) error {
 	pool := sm.New()
 	text, err := pool.Load(path)
 	if err != nil {
@@ -40,7 +61,10 @@ func evalFile(path string) error {
 	if err != nil {
 		return diag.Format(pool, err.(diag.Error))
 	}
-	u, err := eval.EvalUnit(nil, unit)
+	env := eval.NewEnv(target)
+	env.BuildKind = bk.Debug
+	env.BuildMode = bm.Exe
+	u, err := eval.EvalUnit(env, unit)
 	if err != nil {
 		return diag.Format(pool, err.(diag.Error))
 	}
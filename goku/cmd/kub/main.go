@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/mebyus/ku/goku/butler"
@@ -12,6 +11,7 @@ import (
 	"github.com/mebyus/ku/goku/cmd/kub/parse"
 	"github.com/mebyus/ku/goku/cmd/kub/run"
 	"github.com/mebyus/ku/goku/cmd/kub/test"
+	"github.com/mebyus/ku/goku/compiler/diag"
 )
 
 func main() {
@@ -20,9 +20,10 @@ func main() {
 	}
 	args := os.Args[1:]
 
+	diag.Color = diag.DetectColor(os.Stderr)
 	err := butler.Run(root, os.Stderr, args)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		diag.Print(os.Stderr, err)
 		os.Exit(1)
 	}
 }
@@ -33,6 +34,16 @@ var root = &butler.Butler{
 	Short: "Kub is a command line tool for managing restricted set of Ku source code.",
 	Usage: "[command] [arguments]",
 
+	Global: butler.NewParams(
+		butler.Param{
+			Name:    "diag-format",
+			Desc:    "Specifies diagnostics output format (text or json)",
+			Default: diag.TextFormat.String(),
+			Kind:    butler.String,
+		},
+	),
+	Setup: setup,
+
 	Subs: []*butler.Butler{
 		lex.Butler,
 		parse.Butler,
@@ -43,3 +54,12 @@ var root = &butler.Butler{
 		run.Butler,
 	},
 }
+
+func setup(r *butler.Butler) error {
+	format, err := diag.ParseOutputFormat(r.Global.Get("diag-format").Str())
+	if err != nil {
+		return err
+	}
+	diag.Output = format
+	return nil
+}
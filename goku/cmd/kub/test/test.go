@@ -6,6 +6,7 @@ import (
 	"github.com/mebyus/ku/goku/butler"
 	"github.com/mebyus/ku/goku/compiler/enums/bk"
 	"github.com/mebyus/ku/goku/kub/builder"
+	"github.com/mebyus/ku/goku/kub/eval"
 )
 
 var Butler = &butler.Butler{
@@ -24,7 +25,19 @@ var Butler = &butler.Butler{
 		},
 		butler.Param{
 			Name:    "name",
-			Desc:    "Specifies test name",
+			Desc:    "Specifies test name (exact, glob pattern or prefix) to run",
+			Default: "",
+			Kind:    butler.String,
+		},
+		butler.Param{
+			Name:    "target-os",
+			Desc:    "Specifies target operating system (host system by default)",
+			Default: "",
+			Kind:    butler.String,
+		},
+		butler.Param{
+			Name:    "target-arch",
+			Desc:    "Specifies target architecture (host architecture by default)",
 			Default: "",
 			Kind:    butler.String,
 		},
@@ -39,10 +52,14 @@ func run(r *butler.Butler, units []string) error {
 	}
 
 	path := units[0]
-	return test(path, r.Params.Get("build-kind").Str(), r.Params.Get("name").Str())
+	target := eval.Target{
+		OS:   r.Params.Get("target-os").Str(),
+		Arch: r.Params.Get("target-arch").Str(),
+	}
+	return test(path, r.Params.Get("build-kind").Str(), r.Params.Get("name").Str(), target)
 }
 
-func test(name string, kind string, test string) error {
+func test(name string, kind string, test string, target eval.Target) error {
 	k, err := bk.Parse(kind)
 	if err != nil {
 		return err
@@ -53,6 +70,7 @@ func test(name string, kind string, test string) error {
 			ModuleName:      name,
 			BuildKind:       k,
 			PackageFilePath: "pkg.kub",
+			Target:          target,
 		},
 		TestName: test,
 	})
@@ -0,0 +1,92 @@
+package asm
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/vm/asm"
+	"github.com/mebyus/ku/goku/vm/kvx"
+)
+
+var Butler = &butler.Butler{
+	Name: "asm",
+
+	Short: "Assemble VM program from .kasm file into .kvx executable",
+	Usage: "[options] <file>",
+
+	Params: butler.NewParams(
+		butler.Param{
+			Name:    "out",
+			Alias:   "o",
+			Desc:    "Path to output executable, defaults to input path with .kvx extension",
+			Default: "",
+			Kind:    butler.String,
+		},
+		butler.Param{
+			Name:    "strip",
+			Alias:   "s",
+			Desc:    "Do not include symbol table and source map into executable",
+			Default: false,
+			Kind:    butler.Boolean,
+		},
+	),
+
+	Exec: exec,
+}
+
+func exec(r *butler.Butler, files []string) error {
+	if len(files) == 0 {
+		return errors.New("file must be specified")
+	}
+	if len(files) != 1 {
+		return errors.New("only one file may be specified")
+	}
+	path := files[0]
+
+	prog, err := Assemble(path)
+	if err != nil {
+		return err
+	}
+	if r.Params.Get("strip").Bool() {
+		prog.Symbols = nil
+		prog.Source = nil
+	}
+
+	out := r.Params.Get("out").Str()
+	if out == "" {
+		out = strings.TrimSuffix(path, filepath.Ext(path)) + ".kvx"
+	}
+	return kvx.Save(out, prog)
+}
+
+// Assemble compiles program from .kasm file.
+func Assemble(path string) (*kvx.Program, error) {
+	pool := sm.New()
+	text, err := pool.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	prog, err := asm.CompileText(text)
+	if err != nil {
+		var e diag.Error
+		if errors.As(err, &e) {
+			return nil, diag.Format(pool, e)
+		}
+		return nil, err
+	}
+	return prog, nil
+}
+
+// Load loads program from a given file. Files with .kasm extension
+// are assembled, other files are decoded as .kvx executables.
+func Load(path string) (*kvx.Program, error) {
+	if filepath.Ext(path) == ".kasm" {
+		return Assemble(path)
+	}
+	return kvx.Load(path)
+}
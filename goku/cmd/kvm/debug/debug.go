@@ -0,0 +1,38 @@
+package debug
+
+import (
+	"errors"
+	"os"
+
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/goku/cmd/kvm/asm"
+	"github.com/mebyus/ku/goku/vm"
+)
+
+var Butler = &butler.Butler{
+	Name: "debug",
+
+	Short: "Start interactive debugger for VM program from .kvx or .kasm file",
+	Usage: "[options] <file>",
+
+	Exec: exec,
+}
+
+func exec(r *butler.Butler, files []string) error {
+	if len(files) == 0 {
+		return errors.New("file must be specified")
+	}
+	if len(files) != 1 {
+		return errors.New("only one file may be specified")
+	}
+
+	prog, err := asm.Load(files[0])
+	if err != nil {
+		return err
+	}
+	d, err := vm.NewDebugger(prog)
+	if err != nil {
+		return err
+	}
+	return Run(os.Stdin, os.Stdout, d)
+}
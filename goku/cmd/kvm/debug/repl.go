@@ -0,0 +1,307 @@
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mebyus/ku/goku/vm"
+	"github.com/mebyus/ku/goku/vm/disasm"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+const help = `commands:
+  s, step [n]          execute n instructions (default 1)
+  c, continue          run until breakpoint, watchpoint or halt
+  b, break <loc>       set breakpoint at offset, function or "fun@.label"
+  d, delete <loc>      remove breakpoint
+  w, watch <reg>       stop when register value changes
+  unwatch <reg>        remove watchpoint
+  r, regs              print registers
+  f, frames            print call frames
+  x <ptr> [n]          print n bytes of memory at pointer (default 16)
+  l, list [n]          disassemble n instructions at ip (default 5)
+  t, trace [n]         print n last executed instructions (default 10)
+  restart              reload program, keep breakpoints and watchpoints
+  h, help              print this help
+  q, quit              exit debugger
+`
+
+// Run reads debugger commands line by line from input and prints
+// results to output until quit command or end of input.
+func Run(in io.Reader, out io.Writer, d *vm.Debugger) error {
+	r := repl{
+		w: bufio.NewWriter(out),
+		d: d,
+	}
+	defer r.w.Flush()
+
+	r.where()
+	sc := bufio.NewScanner(in)
+	for {
+		r.printf("(kvm) ")
+		err := r.w.Flush()
+		if err != nil {
+			return err
+		}
+		if !sc.Scan() {
+			r.printf("\n")
+			return sc.Err()
+		}
+
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "q" || fields[0] == "quit" {
+			return nil
+		}
+		err = r.exec(fields[0], fields[1:])
+		if err != nil {
+			r.printf("error: %v\n", err)
+		}
+	}
+}
+
+type repl struct {
+	w *bufio.Writer
+	d *vm.Debugger
+}
+
+func (r *repl) printf(format string, args ...any) {
+	fmt.Fprintf(r.w, format, args...)
+}
+
+func (r *repl) exec(cmd string, args []string) error {
+	switch cmd {
+	case "s", "step":
+		n, err := count(args, 1)
+		if err != nil {
+			return err
+		}
+		r.stop(r.d.Step(n))
+	case "c", "continue":
+		r.stop(r.d.Continue())
+	case "b", "break":
+		offset, err := r.loc(args)
+		if err != nil {
+			return err
+		}
+		err = r.d.Break(offset)
+		if err != nil {
+			return err
+		}
+		r.printf("breakpoint at 0x%08X (%s)\n", offset, r.d.Symbolize(uint64(offset)))
+	case "d", "delete":
+		offset, err := r.loc(args)
+		if err != nil {
+			return err
+		}
+		r.d.Unbreak(offset)
+	case "w", "watch", "unwatch":
+		if len(args) != 1 {
+			return fmt.Errorf("register must be specified")
+		}
+		reg, err := parseReg(args[0])
+		if err != nil {
+			return err
+		}
+		if cmd == "unwatch" {
+			r.d.Unwatch(reg)
+			return nil
+		}
+		return r.d.Watch(reg)
+	case "r", "regs":
+		r.regs()
+	case "f", "frames":
+		r.frames()
+	case "x":
+		return r.memory(args)
+	case "l", "list":
+		n, err := count(args, 5)
+		if err != nil {
+			return err
+		}
+		r.list(r.d.Machine().Registers().IP, n)
+	case "t", "trace":
+		n, err := count(args, 10)
+		if err != nil {
+			return err
+		}
+		r.trace(n)
+	case "restart":
+		err := r.d.Restart()
+		if err != nil {
+			return err
+		}
+		r.where()
+	case "h", "help":
+		r.printf("%s", help)
+	default:
+		return fmt.Errorf("unknown command \"%s\", type \"help\" for list of commands", cmd)
+	}
+	return nil
+}
+
+func (r *repl) stop(s vm.Stop) {
+	switch s.Reason {
+	case vm.StopWatch:
+		r.printf("watchpoint: %s 0x%X -> 0x%X\n", s.Reg, s.Old, s.New)
+	case vm.StopBreak:
+		r.printf("breakpoint: 0x%08X (%s)\n", s.IP, r.d.Symbolize(s.IP))
+	case vm.StopHalt:
+		exit := r.d.Machine().State()
+		r.printf("%s\n", exit)
+		if exit.Error != nil {
+			exit.Error.Render(r.w, r.d.Program())
+		}
+		return
+	}
+	r.where()
+}
+
+// print current instruction
+func (r *repl) where() {
+	r.list(r.d.Machine().Registers().IP, 1)
+}
+
+func (r *repl) list(ip uint64, n uint64) {
+	text := r.d.Program().Text
+	offset := uint32(ip)
+	for range n {
+		if offset >= uint32(len(text)) {
+			return
+		}
+		s, err := disasm.Decode(text, offset)
+		if err != nil {
+			r.printf("0x%08X  %-16s  %v\n", offset, r.d.Symbolize(uint64(offset)), err)
+			return
+		}
+		r.printf("0x%08X  %-16s  %s\n", offset, r.d.Symbolize(uint64(offset)), s.String())
+		offset += s.Size
+	}
+}
+
+func (r *repl) regs() {
+	regs := r.d.Machine().Registers()
+	r.printf("ip    0x%016X  %s\n", regs.IP, r.d.Symbolize(regs.IP))
+	r.printf("sp    0x%016X\n", regs.SP)
+	r.printf("fp    0x%016X\n", regs.FP)
+	r.printf("sc    0x%016X\n", regs.SC)
+	r.printf("cf    0x%016X\n", regs.CF)
+	r.printf("clock %d\n", regs.Clock)
+
+	// print general-purpose registers up to the last one with non-zero value
+	last := -1
+	for i, v := range regs.R {
+		if v != 0 {
+			last = i
+		}
+	}
+	for i := 0; i <= last; i += 1 {
+		r.printf("%-5s 0x%016X\n", opc.Register(i), regs.R[i])
+	}
+}
+
+func (r *repl) frames() {
+	frames := r.d.Machine().Frames()
+	regs := r.d.Machine().Registers()
+	r.printf("#%-3d 0x%08X  %s\n", 0, regs.IP, r.d.Symbolize(regs.IP))
+	for i := len(frames) - 1; i >= 0; i -= 1 {
+		f := frames[i]
+		r.printf("#%-3d 0x%08X  %s\n", len(frames)-i, f.Ret, r.d.Symbolize(uint64(f.Ret)))
+	}
+}
+
+func (r *repl) memory(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("pointer must be specified")
+	}
+	ptr, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return fmt.Errorf("bad pointer \"%s\"", args[0])
+	}
+	n, err := count(args[1:], 16)
+	if err != nil {
+		return err
+	}
+	if n == 0 || n > 1<<16 {
+		return fmt.Errorf("bad memory size %d", n)
+	}
+	b, rerr := r.d.Machine().Memory(ptr, uint32(n))
+	if rerr != nil {
+		return rerr
+	}
+	for i := 0; i < len(b); i += 16 {
+		r.printf("0x%016X ", ptr+uint64(i))
+		for _, c := range b[i:min(i+16, len(b))] {
+			r.printf(" %02X", c)
+		}
+		r.printf("\n")
+	}
+	return nil
+}
+
+func (r *repl) trace(n uint64) {
+	trace := r.d.Trace()
+	if uint64(len(trace)) > n {
+		trace = trace[uint64(len(trace))-n:]
+	}
+	text := r.d.Program().Text
+	for _, e := range trace {
+		s, err := disasm.Decode(text, uint32(e.IP))
+		line := s.String()
+		if err != nil {
+			line = err.Error()
+		}
+		r.printf("%8d  0x%08X  %-16s  %s\n", e.Clock, e.IP, r.d.Symbolize(e.IP), line)
+	}
+}
+
+func (r *repl) loc(args []string) (uint32, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("location must be specified")
+	}
+	return r.d.Resolve(args[0])
+}
+
+func count(args []string, def uint64) (uint64, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	if len(args) > 1 {
+		return 0, fmt.Errorf("too many arguments")
+	}
+	n, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad number \"%s\"", args[0])
+	}
+	return n, nil
+}
+
+func parseReg(s string) (opc.Register, error) {
+	s = strings.TrimPrefix(s, "#:")
+	switch s {
+	case "ip":
+		return opc.RegIP, nil
+	case "sp":
+		return opc.RegSP, nil
+	case "fp":
+		return opc.RegFP, nil
+	case "sc":
+		return opc.RegSC, nil
+	case "cf":
+		return opc.RegCF, nil
+	case "clock":
+		return opc.RegClock, nil
+	}
+	if strings.HasPrefix(s, "r") {
+		n, err := strconv.ParseUint(s[1:], 10, 8)
+		if err == nil && n < 64 {
+			return opc.Register(n), nil
+		}
+	}
+	return 0, fmt.Errorf("bad register \"%s\"", s)
+}
@@ -0,0 +1,47 @@
+package disasm
+
+import (
+	"errors"
+	"os"
+
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/goku/cmd/kvm/asm"
+	"github.com/mebyus/ku/goku/vm/disasm"
+)
+
+var Butler = &butler.Butler{
+	Name: "disasm",
+
+	Short: "Disassemble VM program from .kvx or .kasm file into assembly",
+	Usage: "[options] <file>",
+
+	Params: butler.NewParams(
+		butler.Param{
+			Name:    "listing",
+			Alias:   "l",
+			Desc:    "Print instruction offsets and raw bytes instead of assembly",
+			Default: false,
+			Kind:    butler.Boolean,
+		},
+	),
+
+	Exec: exec,
+}
+
+func exec(r *butler.Butler, files []string) error {
+	if len(files) == 0 {
+		return errors.New("file must be specified")
+	}
+	if len(files) != 1 {
+		return errors.New("only one file may be specified")
+	}
+
+	prog, err := asm.Load(files[0])
+	if err != nil {
+		return err
+	}
+	if r.Params.Get("listing").Bool() {
+		return disasm.Listing(os.Stdout, prog)
+	}
+	return disasm.Disassemble(os.Stdout, prog)
+}
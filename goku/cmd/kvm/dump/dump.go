@@ -0,0 +1,85 @@
+package dump
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/goku/vm/kvx"
+)
+
+var Butler = &butler.Butler{
+	Name: "dump",
+
+	Short: "Print header and segment sizes of .kvx executable",
+	Usage: "[options] <file>",
+
+	Exec: exec,
+}
+
+func exec(r *butler.Butler, files []string) error {
+	if len(files) == 0 {
+		return errors.New("file must be specified")
+	}
+	if len(files) != 1 {
+		return errors.New("only one file may be specified")
+	}
+
+	f, err := kvx.LoadFile(files[0])
+	if err != nil {
+		return err
+	}
+	return dump(os.Stdout, f)
+}
+
+func dump(w io.Writer, f *kvx.File) error {
+	h := f.Header
+	p := f.Program
+
+	var symbols string
+	if p.Symbols == nil {
+		symbols = "none"
+	} else {
+		symbols = fmt.Sprintf("%d function(s), %d label(s)", len(p.Symbols.Funs), len(p.Symbols.Labels))
+	}
+
+	var source string
+	if p.Source == nil {
+		source = "none"
+	} else {
+		source = fmt.Sprintf("%s, %d line(s)", p.Source.Path, len(p.Source.Lines))
+	}
+
+	entry := fmt.Sprintf("0x%08X", h.EntryPoint)
+	if p.Symbols != nil {
+		s, ok := p.Symbols.FindFun(h.EntryPoint)
+		if ok && s.Offset == h.EntryPoint {
+			entry += " (" + s.Name + ")"
+		}
+	}
+
+	_, err := fmt.Fprintf(w, `version: %d
+entry:   %s
+
+segment   offset      size        flags
+text      0x%08X  0x%08X  0x%08X
+data      0x%08X  0x%08X  0x%08X
+global    -           0x%08X  0x%08X
+symbols   0x%08X  0x%08X  0x%08X
+source    0x%08X  0x%08X  0x%08X
+
+symbols: %s
+source:  %s
+`,
+		h.Version, entry,
+		h.Text.Offset, h.Text.Size, h.Text.Flags,
+		h.Data.Offset, h.Data.Size, h.Data.Flags,
+		h.Global.Size, h.Global.Flags,
+		h.Symbols.Offset, h.Symbols.Size, h.Symbols.Flags,
+		h.Source.Offset, h.Source.Size, h.Source.Flags,
+		symbols, source,
+	)
+	return err
+}
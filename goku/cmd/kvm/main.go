@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/goku/cmd/kvm/asm"
+	"github.com/mebyus/ku/goku/cmd/kvm/debug"
+	"github.com/mebyus/ku/goku/cmd/kvm/disasm"
+	"github.com/mebyus/ku/goku/cmd/kvm/dump"
+	"github.com/mebyus/ku/goku/cmd/kvm/run"
+	"github.com/mebyus/ku/goku/compiler/diag"
+)
+
+func main() {
+	if len(os.Args) == 0 {
+		panic("os args are empty")
+	}
+	args := os.Args[1:]
+
+	err := butler.Run(root, os.Stderr, args)
+	if err != nil {
+		diag.Print(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+var root = &butler.Butler{
+	Name: "kvm",
+
+	Short: "Kvm is a command line tool for assembling, running and inspecting Ku VM programs.",
+	Usage: "[command] [arguments]",
+
+	Global: butler.NewParams(
+		butler.Param{
+			Name:    "diag-format",
+			Desc:    "Specifies diagnostics output format (text or json)",
+			Default: diag.TextFormat.String(),
+			Kind:    butler.String,
+		},
+	),
+	Setup: setup,
+
+	Subs: []*butler.Butler{
+		asm.Butler,
+		run.Butler,
+		dump.Butler,
+		disasm.Butler,
+		debug.Butler,
+	},
+}
+
+func setup(r *butler.Butler) error {
+	format, err := diag.ParseOutputFormat(r.Global.Get("diag-format").Str())
+	if err != nil {
+		return err
+	}
+	diag.Output = format
+	return nil
+}
@@ -0,0 +1,53 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mebyus/ku/goku/butler"
+	"github.com/mebyus/ku/goku/cmd/kvm/asm"
+	"github.com/mebyus/ku/goku/vm"
+)
+
+var Butler = &butler.Butler{
+	Name: "run",
+
+	Short: "Run VM program from .kvx or .kasm file and print its exit state",
+	Usage: "[options] <file>",
+
+	Exec: exec,
+}
+
+func exec(r *butler.Butler, files []string) error {
+	if len(files) == 0 {
+		return errors.New("file must be specified")
+	}
+	if len(files) != 1 {
+		return errors.New("only one file may be specified")
+	}
+
+	prog, err := asm.Load(files[0])
+	if err != nil {
+		return err
+	}
+
+	var m vm.Machine
+	exit := m.Exec(prog)
+	err = exit.Render(os.Stdout)
+	if err != nil {
+		return err
+	}
+	if exit.Error != nil {
+		err = exit.Error.Render(os.Stderr, prog)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("program exited abnormally")
+	}
+	if exit.Status != 0 {
+		// propagate program exit status to the process
+		os.Exit(int(exit.Status))
+	}
+	return nil
+}
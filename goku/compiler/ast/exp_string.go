@@ -12,6 +12,10 @@ type String struct {
 	Val string
 
 	Pin sm.Pin
+
+	// Length of string literal in source text. Differs from
+	// value length when literal contains escape sequences.
+	Len uint32
 }
 
 // Explicit interface implementation check.
@@ -22,7 +26,7 @@ func (String) Kind() exk.Kind {
 }
 
 func (s String) Span() sm.Span {
-	return sm.Span{Pin: s.Pin, Len: uint32(len(s.Val)) + 2}
+	return sm.Span{Pin: s.Pin, Len: s.Len}
 }
 
 func (s String) String() string {
@@ -2,13 +2,20 @@ package builder
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
+	"github.com/mebyus/ku/goku/compiler/cc"
 	"github.com/mebyus/ku/goku/compiler/diag"
 	"github.com/mebyus/ku/goku/compiler/enums/bk"
 	"github.com/mebyus/ku/goku/compiler/enums/bm"
+	"github.com/mebyus/ku/goku/compiler/genc"
+	"github.com/mebyus/ku/goku/compiler/genvm"
+	"github.com/mebyus/ku/goku/compiler/pkgs"
 	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+	"github.com/mebyus/ku/goku/vm/kvx"
 )
 
 // Phase specifies build or compilation phase.
@@ -16,7 +23,7 @@ type Phase uint8
 
 const (
 	// Intermediate code (we use C for now) generation phase.
-	PhaseGenC = iota + 1
+	PhaseGenC Phase = iota + 1
 
 	// Object file compilation phase.
 	PhaseObj
@@ -26,6 +33,10 @@ const (
 
 	// Test executable output.
 	PhaseTest
+
+	// VM executable output. Does not require C compiler. In test mode
+	// tests are run on VM instead of producing test executable.
+	PhaseVM
 )
 
 func (p Phase) String() string {
@@ -40,6 +51,8 @@ func (p Phase) String() string {
 		return "exe"
 	case PhaseTest:
 		return "test"
+	case PhaseVM:
+		return "vm"
 	default:
 		panic(fmt.Sprintf("unexpected phase (=%d)", p))
 	}
@@ -58,6 +71,8 @@ func (p Phase) Suffix() string {
 		return ""
 	case PhaseTest:
 		return ""
+	case PhaseVM:
+		return ".kvx"
 	default:
 		panic(fmt.Sprintf("unexpected phase (=%d)", p))
 	}
@@ -77,7 +92,7 @@ type Config struct {
 	// Default value ".kub" will be used if empty.
 	GenDir string
 
-	// Path to output translated C code, object file or executable.
+	// Path to output translated C code, object file, executable or VM executable.
 	// Default value will be used if empty.
 	OutPath string
 
@@ -91,6 +106,14 @@ type Config struct {
 	BuildKind bk.Kind
 
 	Mode bm.Mode
+
+	// Data model of compilation target. Determines sizes, alignments and
+	// layout of types. Default data model will be used if nil.
+	Model *stg.DataModel
+
+	// Output for test results and output of failed tests.
+	// Default value os.Stdout will be used if nil.
+	Output io.Writer
 }
 
 // SetDefaults set default values for empty fields and check provided values.
@@ -112,16 +135,6 @@ func (c *Config) SetDefaults(mode bm.Mode) error {
 		c.RootDir = dir
 	}
 
-	if c.Phase == 0 {
-		switch mode {
-		case bm.TestExe:
-			c.Phase = PhaseTest
-		case bm.Exe:
-			c.Phase = PhaseExe
-		default:
-			c.Phase = PhaseObj
-		}
-	}
 	if c.BuildKind == 0 {
 		c.BuildKind = bk.Debug
 	}
@@ -132,14 +145,49 @@ func (c *Config) SetDefaults(mode bm.Mode) error {
 	if c.SourceDir == "" {
 		c.SourceDir = "src"
 	}
+	if c.Output == nil {
+		c.Output = os.Stdout
+	}
+
+	c.Mode = mode
+	if mode != bm.Auto {
+		c.setPhase()
+	}
+	return nil
+}
+
+// resolveAuto selects build mode based on main function existence.
+// Does nothing if build mode was specified explicitly.
+func (c *Config) resolveAuto(hasMain bool) {
+	if c.Mode != bm.Auto {
+		return
+	}
+
+	if hasMain {
+		c.Mode = bm.Exe
+	} else {
+		c.Mode = bm.Obj
+	}
+	c.setPhase()
+}
+
+// setPhase sets default phase and output path based on build mode.
+func (c *Config) setPhase() {
+	if c.Phase == 0 {
+		switch c.Mode {
+		case bm.TestExe:
+			c.Phase = PhaseTest
+		case bm.Exe:
+			c.Phase = PhaseExe
+		default:
+			c.Phase = PhaseObj
+		}
+	}
 
 	if c.OutPath == "" {
 		name := filepath.Base(c.Unit)
 		c.OutPath = filepath.Join(c.GenDir, c.Phase.String(), name+c.Phase.Suffix())
 	}
-
-	c.Mode = mode
-	return nil
 }
 
 // GetRootDir determine Ku root directory based on currently running executable.
@@ -188,18 +236,39 @@ func std(s string) QueueItem {
 	}
 }
 
-func getInitItems(start string) []QueueItem {
+func getInitItems(c *Config) []QueueItem {
 	items := make([]QueueItem, 0, 4)
 
 	items = append(items, std("mem"))
 	items = append(items, std("fmt"))
 	items = append(items, std("stf"))
-	items = append(items, QueueItem{Path: sm.Local(start)})
+	items = append(items, QueueItem{
+		Path: sm.Local(c.Unit),
+
+		// tests are collected only from the unit being tested
+		IncludeTestFiles: c.Mode == bm.TestExe,
+	})
 
 	return items
 }
 
-func build(c *Config) error {
+// Load walks and translates a given unit with all its imports without
+// producing any output. Prelude units are not loaded unless imported.
+func Load(c *Config) (*Bundle, error) {
+	err := c.SetDefaults(bm.Auto)
+	if err != nil {
+		return nil, err
+	}
+	return load(c, QueueItem{Path: sm.Local(c.Unit)})
+}
+
+func load(c *Config, items ...QueueItem) (*Bundle, error) {
+	// project root directory contains source directory
+	resolver, pkgErr := pkgs.NewResolver(filepath.Dir(c.SourceDir))
+	if pkgErr != nil {
+		return nil, pkgErr
+	}
+
 	pool := sm.New()
 	bundle, err := Walk(WalkConfig{
 		pool: pool,
@@ -207,19 +276,119 @@ func build(c *Config) error {
 			Std: filepath.Join(c.RootDir, "src/std"),
 			Loc: c.SourceDir,
 		},
-	}, getInitItems(c.Unit)...)
+		Pkg: resolver,
+	}, items...)
 	if err != nil {
-		return diag.Format(pool, err)
+		return nil, diag.Format(pool, err)
 	}
 
+	bundle.Common.Model = c.Model
 	err = CompileBundle(bundle)
 	if err != nil {
-		return diag.Format(pool, err)
+		return nil, diag.Format(pool, err)
 	}
+	return bundle, nil
+}
 
-	if c.Mode == bm.TestExe {
-		// TODO: generate test driver code, compile it into executable and run
+func build(c *Config) error {
+	bundle, err := load(c, getInitItems(c)...)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	c.resolveAuto(bundle.Main != nil)
+	if c.Phase == PhaseVM && c.Mode == bm.TestExe {
+		return testVM(c, bundle)
+	}
+	if (c.Phase == PhaseExe || c.Phase == PhaseVM) && bundle.Main == nil {
+		return fmt.Errorf("unit \"%s\" has no main function", c.Unit)
+	}
+	if c.Phase == PhaseTest {
+		return test(c, bundle)
+	}
+	if c.Phase == PhaseVM {
+		return outputVM(c, bundle)
+	}
+
+	return output(c, bundle)
+}
+
+// output generates C code for a given bundle and compiles it according to
+// configured phase.
+func output(c *Config, b *Bundle) error {
+	p := genc.Program{Units: b.Order}
+	switch c.Phase {
+	case PhaseExe:
+		p.Main = b.Main.Scope.Get("main")
+	case PhaseTest:
+		err := b.Prune(true)
+		if err != nil {
+			return diag.Format(b.Pool, err)
+		}
+		p.Tests = b.Tests()
+	}
+
+	src := c.OutPath
+	if c.Phase != PhaseGenC {
+		src = filepath.Join(c.GenDir, PhaseGenC.String(), filepath.Base(c.Unit)+PhaseGenC.Suffix())
+	}
+	err := genFile(src, &p)
+	if err != nil {
+		d, ok := err.(diag.Error)
+		if ok {
+			return diag.Format(b.Pool, d)
+		}
+		return err
+	}
+
+	switch c.Phase {
+	case PhaseGenC:
+		return nil
+	case PhaseObj:
+		err = os.MkdirAll(filepath.Dir(c.OutPath), 0o755)
+		if err != nil {
+			return err
+		}
+		return cc.CompileObj(c.OutPath, src, c.BuildKind, nil)
+	case PhaseExe, PhaseTest:
+		err = os.MkdirAll(filepath.Dir(c.OutPath), 0o755)
+		if err != nil {
+			return err
+		}
+		return cc.CompileExe(c.OutPath, src, c.BuildKind, nil)
+	default:
+		panic(fmt.Sprintf("unexpected %s phase", c.Phase))
+	}
+}
+
+// outputVM generates VM executable with entrypoint which calls main function.
+func outputVM(c *Config, b *Bundle) error {
+	p, err := genvm.Generate(&genvm.Program{
+		Units: b.Order,
+		Main:  b.Main.Scope.Get("main"),
+	})
+	if err != nil {
+		return diag.Format(b.Pool, err)
+	}
+
+	mkErr := os.MkdirAll(filepath.Dir(c.OutPath), 0o755)
+	if mkErr != nil {
+		return mkErr
+	}
+	return kvx.Save(c.OutPath, p)
+}
+
+func genFile(path string, p *genc.Program) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return genc.Generate(f, p)
 }
@@ -0,0 +1,69 @@
+package builder
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// Ku root directory of this repository. Programs in tests are built
+// against its standard library, since prelude units are always loaded.
+var rootDir = filepath.Join("..", "..", "..")
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name  string
+		phase Phase
+
+		// test requires C compiler
+		cc bool
+	}{
+		{
+			name:  "1 C code",
+			phase: PhaseGenC,
+		},
+		{
+			name:  "2 executable",
+			phase: PhaseExe,
+			cc:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cc {
+				_, err := exec.LookPath("cc")
+				if err != nil {
+					t.Skip("C compiler is not available")
+				}
+			}
+
+			dir := t.TempDir()
+			out := filepath.Join(dir, "app")
+			c := &Config{
+				RootDir:   rootDir,
+				SourceDir: filepath.Join("testdata", "00005"),
+				GenDir:    dir,
+				OutPath:   out,
+				Unit:      "entry/app",
+				Phase:     tt.phase,
+			}
+			err := Build(c)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			if tt.phase != PhaseExe {
+				_, err = os.Stat(out)
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			err = exec.Command(out).Run()
+			if err != nil {
+				t.Errorf("run executable: %v", err)
+			}
+		})
+	}
+}
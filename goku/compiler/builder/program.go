@@ -7,10 +7,14 @@ import (
 	"github.com/mebyus/ku/goku/compiler/diag"
 	"github.com/mebyus/ku/goku/compiler/parser"
 	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/typer"
 	"github.com/mebyus/ku/goku/compiler/typer/stg"
 	"github.com/mebyus/ku/goku/graphs"
 )
 
+// Enables printing of bundle statistics to stdout.
+const debug = false
+
 // ParserSet collection of parsers for selected unit source texts.
 type ParserSet []*parser.Parser
 
@@ -25,6 +29,11 @@ type Bundle struct {
 	// List of all program units sorted by import path.
 	Units []*stg.Unit
 
+	// List of all program units in order of translation. Imported units
+	// always go before units which import them.
+	// Filled by CompileBundle.
+	Order []*stg.Unit
+
 	// Index in this slice corresponds to Unit.DiscoveryIndex.
 	// Every parser in this slice has only its header parsed.
 	Source []ParserSet
@@ -59,12 +68,14 @@ func (b *Bundle) makeGraph() *graphs.Cycle {
 	return s.RankOrFindCycle(&b.Graph)
 }
 
+// CompileBundle translates all bundle units into STG and prunes symbols
+// which are not reachable from exported symbols and main function.
 func CompileBundle(b *Bundle) diag.Error {
 	b.Common.Init(b.Pool)
-	p := stg.NewPool(&b.Common)
 
 	exportCount := 0
 	testCount := 0
+	b.Order = make([]*stg.Unit, 0, len(b.Units))
 	for _, cohort := range b.Graph.Cohorts {
 		for _, i := range cohort {
 			unit := b.Units[i]
@@ -72,47 +83,124 @@ func CompileBundle(b *Bundle) diag.Error {
 			if err != nil {
 				return err
 			}
-			typer := p.Get(unit)
-			err = typer.Translate(texts)
-			p.Put(typer)
+			if b.isPrelude(unit) {
+				err = typer.CompilePrelude(&b.Common, unit, texts)
+			} else {
+				err = typer.Compile(&b.Common, unit, texts)
+			}
+			if err != nil {
+				return err
+			}
+			err = b.setMain(unit)
 			if err != nil {
 				return err
 			}
 
+			if unit == b.Prelude.Test {
+				b.Common.SetTestPrelude(unit)
+			}
+
+			b.Order = append(b.Order, unit)
 			exportCount += len(unit.Export)
 			testCount += len(unit.Tests)
 		}
 	}
 
-	if testCount != 0 {
+	if debug {
 		fmt.Printf("found %d test function(s)\n", testCount)
+		fmt.Printf("found %d exported symbol(s)\n", exportCount)
 	}
 
-	if exportCount == 0 {
-		fmt.Println("stop: no exported symbols found")
-		return nil
-	}
+	return b.Prune(false)
+}
+
+// Returns true for units which are loaded implicitly, regardless of
+// program imports.
+func (b *Bundle) isPrelude(unit *stg.Unit) bool {
+	p := &b.Prelude
+	return unit == p.Format || unit == p.Memory || unit == p.Test
+}
 
-	fmt.Printf("found %d exported symbol(s)\n", exportCount)
-	exported := make([]*stg.Symbol, 0, exportCount)
+// Prune marks symbols which are not reachable from exported symbols and
+// main function for skipping during code generation. If tests flag is set
+// then tests from local units are reachable as well and main function is not.
+//
+// Returns deferred errors (see typer.CompilePrelude) of prelude symbols
+// which are reachable.
+func (b *Bundle) Prune(tests bool) diag.Error {
+	var roots []*stg.Symbol
 	for _, u := range b.Units {
-		exported = append(exported, u.Export...)
+		roots = append(roots, u.Export...)
+	}
+	if tests {
+		roots = append(roots, b.Tests()...)
+	} else if b.Main != nil {
+		roots = append(roots, b.Main.Scope.Get("main"))
+	}
+
+	stg.Prune(b.Units, roots)
+
+	var report diag.Report
+	for _, u := range b.Order {
+		if len(u.Deferred) == 0 {
+			continue
+		}
+		for _, s := range u.Scope.Symbols {
+			err, ok := u.Deferred[s]
+			if ok && !s.ShouldSkip() {
+				report.Add(err)
+			}
+		}
+	}
+	return report.Err()
+}
+
+// Tests returns list of test symbols from local units in order of
+// their translation.
+func (b *Bundle) Tests() []*stg.Symbol {
+	var tests []*stg.Symbol
+	for _, u := range b.Order {
+		if u.Path.Origin == sm.Std {
+			continue
+		}
+		tests = append(tests, u.Tests...)
 	}
+	return tests
+}
 
-	stg.Prune(exported)
+func (b *Bundle) setMain(unit *stg.Unit) diag.Error {
+	if unit.Path.Origin == sm.Std || !unit.HasMain() {
+		return nil
+	}
+	if b.Main != nil && b.Main != unit {
+		return &diag.SimpleMessageError{
+			Pin:  unit.Scope.Get("main").Pin,
+			Text: fmt.Sprintf("main function found in multiple units: \"%s\" and \"%s\"", b.Main.Path.Import, unit.Path.Import),
+		}
+	}
 
+	b.Main = unit
 	return nil
 }
 
+// ParseTexts parses all unit texts. Errors from all texts are gathered
+// into a single report.
 func ParseTexts(s ParserSet) ([]*ast.Text, diag.Error) {
+	var report diag.Report
 	texts := make([]*ast.Text, 0, len(s))
 	for _, p := range s {
 		t, err := p.Nodes()
 		if err != nil {
-			return nil, err
+			report.Add(err)
+			continue
 		}
 		texts = append(texts, t)
 	}
+
+	err := report.Err()
+	if err != nil {
+		return nil, err
+	}
 	return texts, nil
 }
 
@@ -145,12 +233,33 @@ func (b *Bundle) mapGraphNodes() {
 			b.Graph.Nodes[u.Index].AddDes(uint32(i))
 		}
 
-		if len(unit.Imports) == 0 {
+		if b.needsTestPrelude(unit) {
+			// local units may contain tests which depend on types
+			// from testing framework unit
+			t := b.Prelude.Test.Index
+			b.Graph.Nodes[i].AddAnc(t)
+			b.Graph.Nodes[t].AddDes(uint32(i))
+		}
+
+		if len(b.Graph.Nodes[i].Anc) == 0 {
 			b.Graph.Roots = append(b.Graph.Roots, uint32(i))
 		}
 	}
 }
 
+// Returns true if unit must be translated after testing framework unit.
+func (b *Bundle) needsTestPrelude(unit *stg.Unit) bool {
+	if b.Prelude.Test == nil || unit.Path.Origin == sm.Std {
+		return false
+	}
+	for _, s := range unit.Imports {
+		if s.Path == b.Prelude.Test.Path {
+			return false
+		}
+	}
+	return true
+}
+
 func convertImportCycle(c *graphs.Cycle, units []*stg.Unit) []sm.ImportSite {
 	if len(c.Nodes) < 2 {
 		panic("bad cycle data")
@@ -0,0 +1,140 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/genvm"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+	"github.com/mebyus/ku/goku/vm"
+	"github.com/mebyus/ku/goku/vm/kvx"
+)
+
+// Maximum amount of time a single test is allowed to run.
+const testTimeout = 30 * time.Second
+
+// test builds test executable from a given bundle and runs all tests from
+// local units one by one. Each test runs in a separate process.
+func test(c *Config, b *Bundle) error {
+	tests := b.Tests()
+	if len(tests) == 0 {
+		fmt.Fprintln(c.Output, "no tests found")
+		return nil
+	}
+
+	err := output(c, b)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i, s := range tests {
+		err := runTest(c.Output, c.OutPath, i, s, b.Pool)
+		if err != nil {
+			failed += 1
+		}
+	}
+
+	fmt.Fprintf(c.Output, "\n%d passed, %d failed\n", len(tests)-failed, failed)
+	if failed != 0 {
+		return fmt.Errorf("%d test(s) failed", failed)
+	}
+	return nil
+}
+
+// runTest runs test with a given index from test executable and reports
+// its result to w.
+func runTest(w io.Writer, path string, i int, s *stg.Symbol, m sm.PinMap) error {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, strconv.Itoa(i))
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timeout after %s", testTimeout)
+	}
+	return reportTest(w, s, m, err, out.Bytes())
+}
+
+// reportTest prints result of a given test along with its output.
+func reportTest(w io.Writer, s *stg.Symbol, m sm.PinMap, err error, out []byte) error {
+	name := strings.TrimPrefix(s.Name, "test.")
+	pos, perr := m.DecodePin(s.Pin)
+	if perr != nil {
+		panic(perr)
+	}
+
+	if err == nil {
+		fmt.Fprintf(w, "ok    %s\n", name)
+		return nil
+	}
+
+	fmt.Fprintf(w, "FAIL  %s (%s): %v\n", name, pos, err)
+	if len(out) != 0 {
+		w.Write(out)
+	}
+	return err
+}
+
+// testVM runs all tests from local units one by one on VM. Each test
+// is compiled into a separate VM executable.
+//
+// Note that VM does not limit execution time, thus test which never
+// finishes blocks the whole run.
+func testVM(c *Config, b *Bundle) error {
+	tests := b.Tests()
+	if len(tests) == 0 {
+		fmt.Fprintln(c.Output, "no tests found")
+		return nil
+	}
+
+	err := b.Prune(true)
+	if err != nil {
+		return diag.Format(b.Pool, err)
+	}
+	progs := make([]*kvx.Program, 0, len(tests))
+	for _, s := range tests {
+		p, err := genvm.Generate(&genvm.Program{
+			Units: b.Order,
+			Test:  s,
+		})
+		if err != nil {
+			return diag.Format(b.Pool, err)
+		}
+		progs = append(progs, p)
+	}
+
+	failed := 0
+	for i, s := range tests {
+		var m vm.Machine
+		exit := m.Exec(progs[i])
+
+		var err error
+		if exit.Error != nil {
+			err = fmt.Errorf("%s", exit.Error.Code)
+		}
+		err = reportTest(c.Output, s, b.Pool, err, nil)
+		if err != nil {
+			failed += 1
+		}
+	}
+
+	fmt.Fprintf(c.Output, "\n%d passed, %d failed\n", len(tests)-failed, failed)
+	if failed != 0 {
+		return fmt.Errorf("%d test(s) failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package builder
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTest(t *testing.T) {
+	_, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("C compiler is not available")
+	}
+
+	var out bytes.Buffer
+	base := filepath.Join("testdata", "00004")
+	c := &Config{
+		RootDir:   rootDir,
+		SourceDir: base,
+		GenDir:    t.TempDir(),
+		Unit:      "entry/calc",
+		Output:    &out,
+	}
+
+	// unit has one passing and one failing test, both are placed
+	// in test file, which must be loaded when building tests
+	err = Test(c)
+	if err == nil {
+		t.Fatal("Test() error = nil, want failed test")
+	}
+	const want = "1 test(s) failed"
+	if err.Error() != want {
+		t.Errorf("Test() error = %v, want %s", err, want)
+	}
+
+	// failed test exit reason depends on how trap is implemented
+	// on the host machine
+	got := out.String()
+	prefix := "ok    add\nFAIL  add_wrong (testdata/00004/entry/calc/calc.test.ku:5:6): "
+	suffix := "\n\n1 passed, 1 failed\n"
+	if !strings.HasPrefix(got, prefix) || !strings.HasSuffix(got, suffix) {
+		t.Errorf("Test() output =\n%s\nwant prefix\n%s\nand suffix\n%s", got, prefix, suffix)
+	}
+}
+
+func TestTestVM(t *testing.T) {
+	var out bytes.Buffer
+	base := filepath.Join("testdata", "00004")
+	c := &Config{
+		RootDir:   rootDir,
+		SourceDir: base,
+		GenDir:    t.TempDir(),
+		Unit:      "entry/calc",
+		Phase:     PhaseVM,
+		Output:    &out,
+	}
+
+	// same tests as above, but C compiler is not needed to run them
+	err := Test(c)
+	if err == nil {
+		t.Fatal("Test() error = nil, want failed test")
+	}
+	const want = "1 test(s) failed"
+	if err.Error() != want {
+		t.Errorf("Test() error = %v, want %s", err, want)
+	}
+
+	const wantOut = "ok    add\n" +
+		"FAIL  add_wrong (testdata/00004/entry/calc/calc.test.ku:5:6): trap\n" +
+		"\n1 passed, 1 failed\n"
+	if out.String() != wantOut {
+		t.Errorf("Test() output =\n%s\nwant\n%s", out.String(), wantOut)
+	}
+}
@@ -5,11 +5,15 @@ import (
 
 	"github.com/mebyus/ku/goku/compiler/diag"
 	"github.com/mebyus/ku/goku/compiler/parser"
+	"github.com/mebyus/ku/goku/compiler/pkgs"
 	"github.com/mebyus/ku/goku/compiler/sm"
 	"github.com/mebyus/ku/goku/compiler/typer/stg"
 )
 
 func Walk(cfg WalkConfig, init ...QueueItem) (*Bundle, diag.Error) {
+	if cfg.pool == nil {
+		cfg.pool = sm.New()
+	}
 	w := Walker{WalkConfig: cfg}
 	w.Bundle.Pool = w.pool
 
@@ -39,6 +43,10 @@ type BaseDirs struct {
 type WalkConfig struct {
 	Dir BaseDirs
 
+	// Resolves units with "pkg" origin. Such units cannot be imported
+	// if left nil.
+	Pkg *pkgs.Resolver
+
 	pool *sm.Pool
 }
 
@@ -101,6 +109,7 @@ func (w *Walker) AnalyzeUnit(item QueueItem) (*stg.Unit, diag.Error) {
 
 	dir, err := w.Resolve(path)
 	if err != nil {
+		err.SetFallbackSpan(sm.Span{Pin: item.Pin})
 		return nil, err
 	}
 	files, loadErr := w.pool.LoadDir(dir, &sm.DirScanParams{IncludeTestFiles: item.IncludeTestFiles})
@@ -176,7 +185,16 @@ func (w *Walker) Resolve(path sm.UnitPath) (string, diag.Error) {
 	case sm.Std:
 		return w.Dir.Std + "/" + path.Import, nil
 	case sm.Pkg:
-		panic("not implemented")
+		if w.Pkg == nil {
+			return "", &diag.SimpleMessageError{
+				Text: fmt.Sprintf("unable to resolve unit \"%s\" without package manifest", path.String()),
+			}
+		}
+		dir, err := w.Pkg.Resolve(path.Import)
+		if err != nil {
+			return "", &diag.SimpleMessageError{Text: err.Error()}
+		}
+		return dir, nil
 	case sm.Loc:
 		return w.Dir.Loc + "/" + path.Import, nil
 	default:
@@ -1,7 +1,9 @@
 package cc
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/mebyus/ku/goku/compiler/enums/bk"
 )
@@ -53,6 +55,22 @@ func (g *ArgsBuilder) srcExe(path string) {
 	g.src(path)
 }
 
+func (g *ArgsBuilder) includeDirs(dirs []string) error {
+	for _, dir := range dirs {
+		if strings.TrimSpace(dir) == "" {
+			return errors.New("empty include directory")
+		}
+		g.add("-I", dir)
+	}
+	return nil
+}
+
+// Linker arguments must be placed after source file, otherwise
+// symbols from static archives will not be resolved.
+func (g *ArgsBuilder) links(entries []string) {
+	g.add(LinkArgs(entries)...)
+}
+
 func (g *ArgsBuilder) optimizations(o string) {
 	g.add("-O" + o)
 }
@@ -16,19 +16,27 @@ func InvokeCompiler(args []string) error {
 	return cmd.Run()
 }
 
-func CompileObj(out, src string, k bk.Kind) error {
+// CompileObj compiles object file from a single C source file.
+// Include directories are searched for header files in the order they are listed.
+func CompileObj(out, src string, k bk.Kind, includeDirs []string) error {
 	var g ArgsBuilder
 	g.Init()
 
 	g.common()
 	g.optimizationsAndDebugInfo(k)
+	err := g.includeDirs(includeDirs)
+	if err != nil {
+		return err
+	}
 	g.out(out)
 	g.srcObj(src)
 
 	return InvokeCompiler(g.Args())
 }
 
-func CompileExe(out, src string, k bk.Kind) error {
+// CompileExe compiles and links executable from a single C source file.
+// Links contains list of link entries, see LinkArgs for details on their format.
+func CompileExe(out, src string, k bk.Kind, links []string) error {
 	var g ArgsBuilder
 	g.Init()
 
@@ -36,6 +44,7 @@ func CompileExe(out, src string, k bk.Kind) error {
 	g.optimizationsAndDebugInfo(k)
 	g.out(out)
 	g.srcExe(src)
+	g.links(links)
 
 	return InvokeCompiler(g.Args())
 }
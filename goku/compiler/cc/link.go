@@ -0,0 +1,64 @@
+package cc
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// LinkArgs transforms list of link entries (as specified in package file)
+// into a list of linker arguments.
+//
+// Each entry is translated according to the following rules:
+//
+//   - entry which starts with "-" is passed to linker as is ("-L/opt/lib", "-lz")
+//   - entry which looks like a path to archive, object or shared library
+//     file ("lib/libfoo.a", "foo.o", "/usr/lib/libbar.so") is passed as is
+//   - all other entries are treated as library names ("z" becomes "-lz")
+//
+// Empty entries are skipped. Returned list does not contain duplicate arguments,
+// first occurrence of argument determines its position in the list.
+func LinkArgs(entries []string) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	args := make([]string, 0, len(entries))
+	set := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+
+		arg := linkArg(e)
+		_, ok := set[arg]
+		if ok {
+			continue
+		}
+		set[arg] = struct{}{}
+		args = append(args, arg)
+	}
+	return args
+}
+
+func linkArg(entry string) string {
+	if strings.HasPrefix(entry, "-") {
+		return entry
+	}
+	if isLinkFile(entry) {
+		return entry
+	}
+	return "-l" + entry
+}
+
+func isLinkFile(entry string) bool {
+	if strings.ContainsRune(entry, '/') {
+		return true
+	}
+
+	switch filepath.Ext(entry) {
+	case ".a", ".o", ".so":
+		return true
+	}
+	return strings.Contains(entry, ".so.")
+}
@@ -0,0 +1,53 @@
+package cc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLinkArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []string
+	}{
+		{
+			name:    "1 empty",
+			entries: nil,
+			want:    nil,
+		},
+		{
+			name:    "2 library name",
+			entries: []string{"z"},
+			want:    []string{"-lz"},
+		},
+		{
+			name:    "3 raw flags",
+			entries: []string{"-L/opt/lib", "-lsqlite3"},
+			want:    []string{"-L/opt/lib", "-lsqlite3"},
+		},
+		{
+			name:    "4 static archive",
+			entries: []string{"libfoo.a", "vendor/lib/libbar.a"},
+			want:    []string{"libfoo.a", "vendor/lib/libbar.a"},
+		},
+		{
+			name:    "5 shared library file",
+			entries: []string{"libbaz.so", "libbaz.so.1"},
+			want:    []string{"libbaz.so", "libbaz.so.1"},
+		},
+		{
+			name:    "6 duplicates and blanks",
+			entries: []string{"z", " ", "m", "-lz", "z"},
+			want:    []string{"-lz", "-lm"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LinkArgs(tt.entries)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("LinkArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package diag
+
+import (
+	"os"
+	"strings"
+)
+
+// Color enables ANSI color escape sequences in rendered diagnostics.
+// Commands should set it upon startup, usually via DetectColor.
+var Color = false
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[1;31m"
+	ansiBlue  = "\x1b[1;34m"
+	ansiCyan  = "\x1b[1;36m"
+)
+
+// DetectColor reports whether diagnostics written to a given file
+// should be colored. This is the case when file is a terminal and
+// NO_COLOR environment variable is not set.
+func DetectColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint writes string wrapped into a given color escape sequence
+// if colors are enabled. Otherwise string is written as is.
+func paint(b *strings.Builder, color string, s string) {
+	if !Color {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(color)
+	b.WriteString(s)
+	b.WriteString(ansiReset)
+}
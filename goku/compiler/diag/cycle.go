@@ -0,0 +1,67 @@
+package diag
+
+import (
+	"io"
+	"strings"
+
+	"github.com/mebyus/ku/goku/compiler/sm"
+)
+
+// CycleSite describes one symbol on a definition cycle path.
+type CycleSite struct {
+	Name string
+	Pin  sm.Pin
+}
+
+// DefinitionCycleError reports symbols which depend on each other by value,
+// without any indirection (pointer, reference, span, etc.) along the cycle.
+// Such types would have infinite size.
+//
+// Last site in the list repeats the first one to close the cycle.
+type DefinitionCycleError struct {
+	Sites []CycleSite
+}
+
+var _ Error = &DefinitionCycleError{}
+
+func (e *DefinitionCycleError) Error() string {
+	names := make([]string, 0, len(e.Sites))
+	for _, s := range e.Sites {
+		names = append(names, s.Name)
+	}
+	return "recursive definition without indirection: " + strings.Join(names, " -> ")
+}
+
+func (e *DefinitionCycleError) Render(w io.Writer, m sm.PinMap) error {
+	_, err := io.WriteString(w, e.Error())
+	if err != nil {
+		return err
+	}
+
+	// closing site repeats the first one, no need to render it twice
+	for _, s := range e.Sites[:len(e.Sites)-1] {
+		pos, err := m.DecodePin(s.Pin)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, "\n    ")
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, pos.String())
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, ": ")
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, s.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *DefinitionCycleError) SetFallbackSpan(span sm.Span) {}
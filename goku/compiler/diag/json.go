@@ -0,0 +1,229 @@
+package diag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/token"
+)
+
+// OutputFormat specifies how diagnostics are written to command output.
+type OutputFormat uint8
+
+const (
+	// Human readable text, possibly with source snippets.
+	TextFormat OutputFormat = iota
+
+	// One JSON object per line for each diagnostic.
+	JSONFormat
+)
+
+var outputFormatText = [...]string{
+	TextFormat: "text",
+	JSONFormat: "json",
+}
+
+func (f OutputFormat) String() string {
+	return outputFormatText[f]
+}
+
+// ParseOutputFormat returns output format by its name.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch s {
+	case "text":
+		return TextFormat, nil
+	case "json":
+		return JSONFormat, nil
+	default:
+		return 0, fmt.Errorf("unknown diagnostics format \"%s\"", s)
+	}
+}
+
+// Output selects format of diagnostics produced by Format and Print.
+// Commands should set it upon startup, usually from "diag-format" option.
+var Output = TextFormat
+
+// Error codes which identify kind of diagnostic in machine-readable output.
+const (
+	CodeGeneric          = "generic"
+	CodeUnexpectedToken  = "unexpected-token"
+	CodeUnknownOrigin    = "unknown-origin"
+	CodeImportCycle      = "import-cycle"
+	CodeDefinitionCycle  = "definition-cycle"
+	CodeUndefinedSymbol  = "undefined-symbol"
+	CodeIncompatibleType = "incompatible-type"
+	CodeBinaryTypes      = "binary-types"
+	CodeUnsupported      = "unsupported"
+)
+
+// Record is a machine-readable form of a single diagnostic.
+//
+// Line and column are one-based, they are equal to 0 (along with
+// empty file) if diagnostic is not attributed to a place in source code.
+// Len equals 0 if length of source span is unknown.
+type Record struct {
+	File     string `json:"file"`
+	Line     uint32 `json:"line"`
+	Column   uint32 `json:"column"`
+	Offset   uint32 `json:"offset"`
+	Len      uint32 `json:"len"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Note     string `json:"note"`
+	Code     string `json:"code"`
+}
+
+// Records transforms error into a list of machine-readable records.
+// Each error from report produces a separate record.
+func Records(m sm.PinMap, e Error) []Record {
+	r, ok := e.(*Report)
+	if !ok {
+		return []Record{record(m, e)}
+	}
+
+	list := make([]Record, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		list = append(list, Records(m, e)...)
+	}
+	return list
+}
+
+func record(m sm.PinMap, e Error) Record {
+	r := Record{
+		Severity: "error",
+		Message:  e.Error(),
+		Code:     CodeGeneric,
+	}
+
+	var span sm.Span
+	switch e := e.(type) {
+	case *SimpleMessageError:
+		span.Pin = e.Pin
+	case *UnexpectedTokenError:
+		span = sm.Span{Pin: e.Token.Pin, Len: tokenLen(e.Token)}
+		r.Code = CodeUnexpectedToken
+	case *UnknownOriginError:
+		span = sm.Span{Pin: e.Name.Pin, Len: uint32(len(e.Name.Str))}
+		r.Code = CodeUnknownOrigin
+	case *Diagnostic:
+		span = e.Primary.Span
+		r.Note = strings.Join(e.Notes, "\n")
+		if e.Code != "" {
+			r.Code = e.Code
+		}
+	case *DefinitionCycleError:
+		first := e.Sites[0]
+		span = sm.Span{Pin: first.Pin, Len: uint32(len(first.Name))}
+		r.Note = formatSites(m, len(e.Sites)-1, func(i int) (sm.Pin, string) {
+			return e.Sites[i].Pin, e.Sites[i].Name
+		})
+		r.Code = CodeDefinitionCycle
+	case *ImportCycleError:
+		if len(e.Sites) != 0 {
+			span.Pin = e.Sites[0].Pin
+		}
+		r.Note = formatSites(m, len(e.Sites), func(i int) (sm.Pin, string) {
+			s := e.Sites[i]
+			return s.Pin, s.Name + " => \"" + s.Path.String() + "\""
+		})
+		r.Code = CodeImportCycle
+	}
+
+	if span.Pin == 0 {
+		return r
+	}
+	pos, err := m.DecodePin(span.Pin)
+	if err != nil {
+		return r
+	}
+	r.File = pos.Path
+	r.Line = pos.Pos.Line + 1
+	r.Column = pos.Pos.Column + 1
+	r.Offset = span.Pin.Pos().Offset
+	r.Len = span.Len
+	return r
+}
+
+// formatSites lists n sites (one per line) with their positions.
+func formatSites(m sm.PinMap, n int, site func(i int) (sm.Pin, string)) string {
+	var b strings.Builder
+	for i := range n {
+		if i != 0 {
+			b.WriteString("\n")
+		}
+		pin, s := site(i)
+		pos, err := m.DecodePin(pin)
+		if err == nil {
+			b.WriteString(pos.String())
+			b.WriteString(": ")
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+func tokenLen(t token.Token) uint32 {
+	switch {
+	case t.Kind == token.EOF:
+		return 0
+	case t.Kind == token.Word:
+		return uint32(len(t.Data))
+	case t.Kind == token.String || t.Kind == token.CString:
+		return uint32(t.Val)
+	case t.Kind.HasStaticLiteral():
+		return uint32(len(t.Kind.String()))
+	default:
+		// literal of other tokens may differ from source text
+		return 0
+	}
+}
+
+// WriteJSON writes records to output, one JSON object per line.
+func WriteJSON(w io.Writer, records []Record) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, r := range records {
+		err := enc.Encode(r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodedError holds diagnostics which were already transformed
+// into machine-readable records.
+type EncodedError struct {
+	Records []Record
+}
+
+func (e *EncodedError) Error() string {
+	var b strings.Builder
+	WriteJSON(&b, e.Records)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Print writes command error to output in selected output format.
+//
+// In JSON format errors which do not carry diagnostics are written
+// as records without position.
+func Print(w io.Writer, err error) error {
+	if Output == TextFormat {
+		_, err = fmt.Fprintln(w, err)
+		return err
+	}
+
+	var e *EncodedError
+	if errors.As(err, &e) {
+		return WriteJSON(w, e.Records)
+	}
+	return WriteJSON(w, []Record{{
+		Severity: "error",
+		Message:  err.Error(),
+		Code:     CodeGeneric,
+	}})
+}
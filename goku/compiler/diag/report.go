@@ -1,18 +1,143 @@
 package diag
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"strings"
 
 	"github.com/mebyus/ku/goku/compiler/sm"
 )
 
 // Report combines several compilation errors into one report.
+//
+// Report itself implements Error interface, thus it can be returned
+// from compilation phases as is. Use Err method to obtain report
+// as a single value.
 type Report struct {
 	Errors []Error
 }
 
+var _ Error = &Report{}
+
+// Add error to the report. Errors from nested report are merged
+// into this one. Nil errors are ignored.
+func (r *Report) Add(e Error) {
+	switch e := e.(type) {
+	case nil:
+		return
+	case *Report:
+		r.Errors = append(r.Errors, e.Errors...)
+	default:
+		r.Errors = append(r.Errors, e)
+	}
+}
+
+// Len returns number of errors in the report.
+func (r *Report) Len() int {
+	return len(r.Errors)
+}
+
+// Sort orders report errors by their position in source code.
+// Errors without position are placed at the end.
+func (r *Report) Sort() {
+	slices.SortStableFunc(r.Errors, func(a, b Error) int {
+		p := PinOf(a)
+		q := PinOf(b)
+		if p == q {
+			return 0
+		}
+		if p == 0 {
+			return 1
+		}
+		if q == 0 {
+			return -1
+		}
+		return cmp.Compare(p, q)
+	})
+}
+
+// Err returns nil if report is empty. If report contains a single error
+// then that error is returned. Otherwise returns sorted report itself.
+func (r *Report) Err() Error {
+	switch len(r.Errors) {
+	case 0:
+		return nil
+	case 1:
+		return r.Errors[0]
+	}
+	r.Sort()
+	return r
+}
+
+func (r *Report) Error() string {
+	switch len(r.Errors) {
+	case 0:
+		return "no errors"
+	case 1:
+		return r.Errors[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", r.Errors[0].Error(), len(r.Errors)-1)
+}
+
+// Render writes each error from report on a separate line.
+func (r *Report) Render(w io.Writer, m sm.PinMap) error {
+	for i, e := range r.Errors {
+		if i != 0 {
+			_, err := io.WriteString(w, "\n")
+			if err != nil {
+				return err
+			}
+		}
+		err := e.Render(w, m)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Report) SetFallbackSpan(span sm.Span) {
+	for _, e := range r.Errors {
+		e.SetFallbackSpan(span)
+	}
+}
+
+// PinOf returns source position of a given error.
+// Returns zero pin if error does not have position.
+func PinOf(e Error) sm.Pin {
+	switch e := e.(type) {
+	case *SimpleMessageError:
+		return e.Pin
+	case *UnexpectedTokenError:
+		return e.Token.Pin
+	case *UnknownOriginError:
+		return e.Name.Pin
+	case *Diagnostic:
+		return e.Primary.Span.Pin
+	case *DefinitionCycleError:
+		return e.Sites[0].Pin
+	case *ImportCycleError:
+		if len(e.Sites) == 0 {
+			return 0
+		}
+		return e.Sites[0].Pin
+	case *Report:
+		var pin sm.Pin
+		for _, e := range e.Errors {
+			p := PinOf(e)
+			if p != 0 && (pin == 0 || p < pin) {
+				pin = p
+			}
+		}
+		return pin
+	default:
+		return 0
+	}
+}
+
 // Error augments standard Go error with source position information.
 //
 // This interface is a container for any error related to compilation
@@ -31,18 +156,17 @@ type Error interface {
 	SetFallbackSpan(span sm.Span)
 }
 
+// Format transforms compilation error into a regular Go error
+// according to selected output format.
 func Format(m sm.PinMap, e Error) error {
+	if Output == JSONFormat {
+		return &EncodedError{Records: Records(m, e)}
+	}
 	return errors.New(Stringify(m, e))
 }
 
 func RenderReport(w io.Writer, m sm.PinMap, r Report) error {
-	for _, e := range r.Errors {
-		err := e.Render(w, m)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return r.Render(w, m)
 }
 
 func Stringify(m sm.PinMap, e Error) string {
@@ -0,0 +1,232 @@
+package diag
+
+import (
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mebyus/ku/goku/compiler/char"
+	"github.com/mebyus/ku/goku/compiler/sm"
+)
+
+// Label attaches a short message to a portion of source text.
+type Label struct {
+	// May be empty, in that case only span marker is rendered.
+	Text string
+
+	Span sm.Span
+}
+
+// Diagnostic is an error which points to primary span in source text,
+// may have additional (secondary) labeled spans and help notes.
+//
+// When source texts are available to renderer, diagnostic is rendered
+// with offending source lines and markers under each span:
+//
+//	src/main.ku:15:6 binary operation on incompatible types u32 and str
+//	   |
+//	15 |    ret a + "s";
+//	   |        ^ has type u32
+//	   |            --- has type str
+//	   = note: operands must have the same type
+type Diagnostic struct {
+	// Short message which describes the problem.
+	Text string
+
+	Primary Label
+
+	Secondary []Label
+
+	// Help notes, each note is rendered on a separate line
+	// after source snippet.
+	Notes []string
+
+	// Identifies kind of diagnostic in machine-readable output.
+	// Generic code is used if this field is empty.
+	Code string
+}
+
+var _ Error = &Diagnostic{}
+
+func (d *Diagnostic) Error() string {
+	return d.Text
+}
+
+func (d *Diagnostic) Render(w io.Writer, m sm.PinMap) error {
+	var b strings.Builder
+
+	pin := d.Primary.Span.Pin
+	if pin != 0 {
+		pos, err := m.DecodePin(pin)
+		if err != nil {
+			return err
+		}
+		paint(&b, ansiBold, pos.String())
+		b.WriteString(" ")
+	}
+	paint(&b, ansiRed, d.Text)
+
+	tm, ok := m.(sm.TextMap)
+	if ok && pin != 0 {
+		d.renderSnippets(&b, tm)
+	}
+
+	for _, note := range d.Notes {
+		b.WriteString("\n")
+		paint(&b, ansiBlue, "  = ")
+		paint(&b, ansiCyan, "note:")
+		b.WriteString(" ")
+		b.WriteString(note)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (d *Diagnostic) SetFallbackSpan(span sm.Span) {
+	if d.Primary.Span.Pin == 0 {
+		d.Primary.Span = span
+	}
+}
+
+// marker describes placement of label inside source text.
+type marker struct {
+	label Label
+
+	// Zero-based line number.
+	line uint32
+
+	// Byte offset of span start inside its line.
+	col uint32
+
+	primary bool
+}
+
+// renderSnippets writes source lines with markers under them for each
+// text referenced by diagnostic labels. Labels which point to texts
+// other than primary one are rendered in separate snippets.
+func (d *Diagnostic) renderSnippets(b *strings.Builder, m sm.TextMap) {
+	labels := make([]Label, 0, 1+len(d.Secondary))
+	labels = append(labels, d.Primary)
+	for _, l := range d.Secondary {
+		if l.Span.Pin != 0 {
+			labels = append(labels, l)
+		}
+	}
+
+	// group markers by text preserving order of first appearance
+	var texts []*sm.Text
+	var groups [][]marker
+	for i, l := range labels {
+		text := m.PinText(l.Span.Pin)
+		if text == nil {
+			continue
+		}
+		offset := l.Span.Pin.Pos().Offset
+		if offset > uint32(len(text.Data)) {
+			continue
+		}
+
+		k := slices.Index(texts, text)
+		if k < 0 {
+			k = len(texts)
+			texts = append(texts, text)
+			groups = append(groups, nil)
+		}
+		start := sm.FindLineOffset(text.Data, offset)
+		groups[k] = append(groups[k], marker{
+			label:   l,
+			line:    sm.FindLineNumberAtOffset(text.Data, start),
+			col:     offset - start,
+			primary: i == 0,
+		})
+	}
+
+	for k, text := range texts {
+		renderSnippet(b, m, text, groups[k], k != 0)
+	}
+}
+
+func renderSnippet(b *strings.Builder, m sm.TextMap, text *sm.Text, markers []marker, location bool) {
+	slices.SortStableFunc(markers, func(x, y marker) int {
+		if x.line != y.line {
+			return int(x.line) - int(y.line)
+		}
+		return int(x.col) - int(y.col)
+	})
+
+	last := markers[len(markers)-1].line
+	pad := strings.Repeat(" ", len(strconv.FormatUint(uint64(last)+1, 10)))
+
+	if location {
+		pos, err := m.DecodePin(markers[0].label.Span.Pin)
+		if err == nil {
+			b.WriteString("\n")
+			paint(b, ansiBlue, pad+"--> ")
+			b.WriteString(pos.String())
+		}
+	}
+	b.WriteString("\n")
+	paint(b, ansiBlue, pad+" |")
+
+	for i := 0; i < len(markers); {
+		line := markers[i].line
+		if i != 0 && line > markers[i-1].line+1 {
+			b.WriteString("\n")
+			paint(b, ansiBlue, "...")
+		}
+
+		window := sm.FindTargetLineWindow(text.Data, sm.WindowParams{
+			Offset: markers[i].label.Span.Pin.Pos().Offset,
+		})
+		src := window.Lines[window.Target]
+
+		num := strconv.FormatUint(uint64(line)+1, 10)
+		b.WriteString("\n")
+		paint(b, ansiBlue, num+strings.Repeat(" ", len(pad)-len(num))+" | ")
+		b.Write(src)
+
+		for ; i < len(markers) && markers[i].line == line; i += 1 {
+			b.WriteString("\n")
+			paint(b, ansiBlue, pad+" | ")
+			renderMarker(b, src, markers[i])
+		}
+	}
+}
+
+// renderMarker writes marker line which underlines label span inside
+// a given source line.
+func renderMarker(b *strings.Builder, src []byte, m marker) {
+	col := min(m.col, uint32(len(src)))
+
+	// repeat tabs from source line to keep marker aligned
+	// regardless of tab width
+	for _, c := range src[:col] {
+		if c == '\t' {
+			b.WriteByte('\t')
+		} else if char.IsCodePointStart(c) {
+			b.WriteByte(' ')
+		}
+	}
+
+	end := min(col+m.label.Span.Len, uint32(len(src)))
+	width := 0
+	for _, c := range src[col:end] {
+		if char.IsCodePointStart(c) {
+			width += 1
+		}
+	}
+	width = max(width, 1)
+
+	color := ansiBlue
+	s := strings.Repeat("-", width)
+	if m.primary {
+		color = ansiRed
+		s = strings.Repeat("^", width)
+	}
+	if m.label.Text != "" {
+		s += " " + m.label.Text
+	}
+	paint(b, color, s)
+}
@@ -0,0 +1,21 @@
+package diag
+
+import (
+	"fmt"
+
+	"github.com/mebyus/ku/goku/compiler/sm"
+)
+
+// UndefinedSymbol creates diagnostic for a name which refers to undefined
+// symbol. Argument what describes how the name is used, for example
+// "name" or "type name".
+func UndefinedSymbol(what string, name string, pin sm.Pin) *Diagnostic {
+	return &Diagnostic{
+		Text: fmt.Sprintf("%s \"%s\" refers to undefined symbol", what, name),
+		Primary: Label{
+			Text: "not defined",
+			Span: sm.Span{Pin: pin, Len: uint32(len(name))},
+		},
+		Code: CodeUndefinedSymbol,
+	}
+}
@@ -0,0 +1,17 @@
+package diag
+
+import (
+	"github.com/mebyus/ku/goku/compiler/sm"
+)
+
+// Unsupported creates diagnostic for a valid language construct
+// which compiler does not support yet.
+func Unsupported(what string, span sm.Span) *Diagnostic {
+	return &Diagnostic{
+		Text: what + " is not supported yet",
+		Primary: Label{
+			Span: span,
+		},
+		Code: CodeUnsupported,
+	}
+}
@@ -0,0 +1,375 @@
+package genc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mebyus/ku/goku/compiler/char"
+	"github.com/mebyus/ku/goku/compiler/enums/bok"
+	"github.com/mebyus/ku/goku/compiler/enums/smk"
+	"github.com/mebyus/ku/goku/compiler/enums/tpk"
+	"github.com/mebyus/ku/goku/compiler/enums/uok"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+)
+
+func (g *Gen) exp(exp stg.Exp) {
+	switch e := exp.(type) {
+	case *stg.Integer:
+		g.integer(e)
+	case *stg.Rune:
+		g.putn(uint64(e.Val))
+	case stg.String:
+		g.str(e.Val)
+	case *stg.Boolean:
+		g.boolean(e.Val)
+	case *stg.BoolExp:
+		g.puts("((void)(")
+		g.exp(e.Exp)
+		g.puts("), ")
+		g.boolean(e.Val)
+		g.puts(")")
+	case *stg.Nil:
+		g.puts("nil")
+	case *stg.SymExp:
+		g.puts(g.symName(e.Symbol))
+	case *stg.Unary:
+		g.unary(e)
+	case *stg.Binary:
+		g.binary(e)
+	case *stg.Call:
+		g.call(e)
+	case *stg.Cast:
+		g.cast(g.typeName(e.Type()), e.Exp)
+	case *stg.SelectField:
+		g.exp(e.Exp)
+		g.puts(".")
+		g.puts(e.Field.Name)
+	case *stg.DerefSelectField:
+		g.exp(e.Exp)
+		g.puts("->")
+		g.puts(e.Field.Name)
+	case *stg.SelectSpanLen:
+		g.exp(e.Exp)
+		g.puts(".len")
+	case *stg.SelectSpanPtr:
+		g.exp(e.Exp)
+		g.puts(".ptr")
+	case *stg.SpanIndex:
+		g.exp(e.Exp)
+		g.puts(".ptr[")
+		g.exp(e.Index)
+		g.puts("]")
+	case *stg.DerefIndex:
+		g.exp(e.Exp)
+		g.puts("[")
+		g.exp(e.Index)
+		g.puts("]")
+	case *stg.SliceArrayRef:
+		g.puts("(")
+		g.exp(e.Exp)
+		g.puts(" + ")
+		g.exp(e.Index)
+		g.puts(")")
+	case *stg.MakeSpan:
+		g.makeSpan(e)
+	case *stg.SpanSlice:
+		g.spanSlice(e)
+	case *stg.Pack:
+		g.pack(e)
+	default:
+		panic(fmt.Sprintf("unexpected (%T) expression", e))
+	}
+}
+
+func (g *Gen) integer(n *stg.Integer) {
+	if n.Neg {
+		g.puts("(-")
+		g.putn(n.Val)
+		g.puts(")")
+		return
+	}
+
+	g.putn(n.Val)
+	if n.Val > 0x7FFFFFFF {
+		g.puts("ull")
+	}
+}
+
+func (g *Gen) boolean(v bool) {
+	if v {
+		g.puts("true")
+	} else {
+		g.puts("false")
+	}
+}
+
+func (g *Gen) str(s string) {
+	if g.global {
+		g.objstr(s)
+		return
+	}
+
+	if s == "" {
+		g.puts("empty_str")
+		return
+	}
+
+	g.puts("make_str(")
+	g.cstr(s)
+	g.puts(", ")
+	g.putn(uint64(len(s)))
+	g.putb(')')
+}
+
+// Produces string in form suitable for static initializers.
+func (g *Gen) objstr(s string) {
+	if s == "" {
+		g.puts("{}")
+		return
+	}
+
+	g.puts("{.ptr = ")
+	g.cstr(s)
+	g.puts(", .len = ")
+	g.putn(uint64(len(s)))
+	g.puts("}")
+}
+
+func (g *Gen) cstr(s string) {
+	g.puts("(u8*)(\"")
+	g.puts(char.Escape(s))
+	g.puts("\")")
+}
+
+// Generates expression with explicit cast to a given type.
+func (g *Gen) cast(typ string, exp stg.Exp) {
+	g.puts("(")
+	g.puts(typ)
+	g.puts(")(")
+	g.exp(exp)
+	g.puts(")")
+}
+
+func (g *Gen) unary(u *stg.Unary) {
+	switch u.Op.Kind {
+	case uok.Not:
+		g.puts("!(")
+		g.exp(u.Exp)
+		g.puts(")")
+	case uok.Minus:
+		g.puts("(")
+		g.typ(u.Type())
+		g.puts(")(-(")
+		g.exp(u.Exp)
+		g.puts("))")
+	case uok.BitNot:
+		g.puts("(")
+		g.typ(u.Type())
+		g.puts(")(~(")
+		g.exp(u.Exp)
+		g.puts("))")
+	default:
+		panic(fmt.Sprintf("unexpected %s (=%d) unary operator", u.Op.Kind, u.Op.Kind))
+	}
+}
+
+func (g *Gen) binary(b *stg.Binary) {
+	k := b.Op.Kind
+	arith := isArithOp(k)
+	if arith {
+		// integer promotion in C may produce wider type than
+		// operands have, thus we need to cast result back
+		g.puts("(")
+		g.typ(b.Type())
+		g.puts(")")
+	}
+
+	g.puts("(")
+	g.exp(b.A)
+	g.space()
+	switch k {
+	case bok.BitAndNot:
+		g.puts("& ~")
+	case bok.In:
+		g.unsupported("operator \"in\"", sm.Span{Pin: b.Op.Pin, Len: uint32(len(k.String()))})
+	default:
+		g.puts(k.String())
+		g.space()
+	}
+	g.exp(b.B)
+	g.puts(")")
+}
+
+func isArithOp(k bok.Kind) bool {
+	switch k {
+	case bok.Add, bok.Sub, bok.Mul, bok.Div, bok.Mod,
+		bok.Xor, bok.BitAnd, bok.BitOr, bok.BitAndNot, bok.LeftShift, bok.RightShift:
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *Gen) call(c *stg.Call) {
+	s := c.Symbol
+	switch s.Kind {
+	case smk.Fun, smk.Method:
+		g.puts(g.symName(s))
+	case smk.BgenFunInst:
+		g.puts(g.instName(s))
+	default:
+		panic(fmt.Sprintf("unexpected call to %s (=%d) symbol \"%s\"", s.Kind, s.Kind, s.Name))
+	}
+
+	g.args(c.Args)
+}
+
+func (g *Gen) args(args []stg.Exp) {
+	if len(args) == 0 {
+		g.puts("()")
+		return
+	}
+
+	g.puts("(")
+	g.exp(args[0])
+	for _, a := range args[1:] {
+		g.puts(", ")
+		g.exp(a)
+	}
+	g.puts(")")
+}
+
+// Pack is generated as compound literal of tuple struct type.
+func (g *Gen) pack(p *stg.Pack) {
+	g.puts("(")
+	g.typ(p.Type())
+	g.puts("){")
+	for i, e := range p.List {
+		if i != 0 {
+			g.puts(", ")
+		}
+		g.puts(".")
+		g.puts(tupleField(i))
+		g.puts(" = ")
+		g.exp(e)
+	}
+	g.puts("}")
+}
+
+func (g *Gen) makeSpan(s *stg.MakeSpan) {
+	g.puts("(")
+	g.typ(s.Type())
+	g.puts("){.ptr = ")
+	if s.Start == nil {
+		g.exp(s.Exp)
+	} else {
+		g.puts("(")
+		g.exp(s.Exp)
+		g.puts(" + ")
+		g.exp(s.Start)
+		g.puts(")")
+	}
+	g.puts(", .len = ")
+	g.spanLen(s.Start, s.End, nil)
+	g.puts("}")
+}
+
+// Span expression is evaluated more than once if end of slice is omitted.
+func (g *Gen) spanSlice(s *stg.SpanSlice) {
+	g.puts("(")
+	g.typ(s.Type())
+	g.puts("){.ptr = ")
+	g.exp(s.Exp)
+	g.puts(".ptr")
+	if s.Start != nil {
+		g.puts(" + ")
+		g.exp(s.Start)
+	}
+	g.puts(", .len = ")
+	g.spanLen(s.Start, s.End, s.Exp)
+	g.puts("}")
+}
+
+func (g *Gen) spanLen(start, end, span stg.Exp) {
+	g.puts("(uint)(")
+	if end == nil {
+		g.exp(span)
+		g.puts(".len")
+	} else {
+		g.exp(end)
+	}
+	if start != nil {
+		g.puts(" - ")
+		g.exp(start)
+	}
+	g.puts(")")
+}
+
+// Returns name of builtin generic function instance. Instance definition
+// is remembered and generated later.
+func (g *Gen) instName(s *stg.Symbol) string {
+	spec := s.Def.(stg.UniformParamsSpec)
+	name := "ku_" + s.Name + "_" + strconv.FormatUint(uint64(spec.Num), 10) + "_" + mangleTypeName(g.typeName(spec.Type))
+
+	_, ok := g.instset[s]
+	if !ok {
+		g.instset[s] = struct{}{}
+		g.insts = append(g.insts, s)
+	}
+	return name
+}
+
+// Generates definitions for all used builtin generic function instances.
+func (g *Gen) instances() {
+	for _, s := range g.insts {
+		switch s.Name {
+		case "min":
+			g.minInstance(s)
+		default:
+			panic(fmt.Sprintf("unexpected builtin generic function \"%s\"", s.Name))
+		}
+	}
+}
+
+func (g *Gen) minInstance(s *stg.Symbol) {
+	spec := s.Def.(stg.UniformParamsSpec)
+	if spec.Type.Kind != tpk.Integer {
+		panic(fmt.Sprintf("unexpected %s type in min instance", spec.Type))
+	}
+	typ := g.typeName(spec.Type)
+
+	g.puts("static ")
+	g.puts(typ)
+	g.nl()
+	g.puts(g.instName(s))
+	g.puts("(")
+	for i := range spec.Num {
+		if i != 0 {
+			g.puts(", ")
+		}
+		g.puts(typ)
+		g.puts(" a")
+		g.putn(uint64(i))
+	}
+	g.puts(") {")
+	g.nl()
+	g.puts("\t")
+	g.puts(typ)
+	g.puts(" m = a0;")
+	g.nl()
+	for i := uint(1); i < spec.Num; i += 1 {
+		n := strconv.FormatUint(uint64(i), 10)
+		g.puts("\tif (a" + n + " < m) {")
+		g.nl()
+		g.puts("\t\tm = a" + n + ";")
+		g.nl()
+		g.puts("\t}")
+		g.nl()
+	}
+	g.puts("\treturn m;")
+	g.nl()
+	g.puts("}")
+	g.nl()
+	g.nl()
+}
@@ -0,0 +1,168 @@
+package genc
+
+import (
+	"fmt"
+
+	"github.com/mebyus/ku/goku/compiler/enums/smk"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+)
+
+// Returns true if symbol must be present in generated code.
+func isEmitted(s *stg.Symbol) bool {
+	switch s.Kind {
+	case smk.Fun, smk.Method, smk.Test, smk.Var:
+		return !s.ShouldSkip()
+	default:
+		return false
+	}
+}
+
+func (g *Gen) unitVars(u *stg.Unit) {
+	for _, s := range u.Scope.Symbols {
+		if s.Kind != smk.Var || !isEmitted(s) {
+			continue
+		}
+
+		g.topVar(s)
+	}
+}
+
+func (g *Gen) topVar(s *stg.Symbol) {
+	g.puts("static ")
+	g.typ(s.Type)
+	g.space()
+	g.puts(g.symName(s))
+	g.puts(" = ")
+
+	var exp stg.Exp
+	def, ok := s.Def.(stg.StaticValue)
+	if ok {
+		exp = def.Exp
+	}
+	if exp == nil {
+		g.puts(zeroValue(s.Type))
+	} else {
+		g.global = true
+		g.exp(exp)
+		g.global = false
+	}
+	g.semi()
+	g.nl()
+	g.nl()
+}
+
+func (g *Gen) unitFunDecls(u *stg.Unit) {
+	for _, s := range u.Scope.Symbols {
+		if s.Kind == smk.Var || !isEmitted(s) {
+			continue
+		}
+
+		g.funHead(s)
+		g.semi()
+		g.nl()
+		g.nl()
+	}
+}
+
+func (g *Gen) unitFuns(u *stg.Unit) {
+	for _, s := range u.Scope.Symbols {
+		if s.Kind == smk.Var || s.IsStub() || !isEmitted(s) {
+			continue
+		}
+
+		g.fun(s)
+		g.nl()
+		g.nl()
+	}
+}
+
+func (g *Gen) fun(s *stg.Symbol) {
+	clear(g.declared)
+
+	g.funHead(s)
+	g.space()
+	g.block(&s.Def.(*stg.Fun).Body)
+}
+
+func (g *Gen) funHead(s *stg.Symbol) {
+	fun := s.Def.(*stg.Fun)
+
+	if !s.IsStub() && !s.IsExport() {
+		g.puts("static ")
+	}
+	if fun.Never {
+		g.puts("_Noreturn ")
+	}
+	if fun.Result == nil {
+		g.puts("void")
+	} else {
+		g.typ(fun.Result)
+	}
+	g.nl()
+	g.puts(g.symName(s))
+	g.params(fun)
+}
+
+func (g *Gen) params(fun *stg.Fun) {
+	params := getParams(fun)
+	if len(params) == 0 {
+		g.puts("(void)")
+		return
+	}
+
+	g.puts("(")
+	g.param(params[0])
+	for _, p := range params[1:] {
+		g.puts(", ")
+		g.param(p)
+	}
+	g.puts(")")
+}
+
+func (g *Gen) param(p *stg.Symbol) {
+	g.typ(p.Type)
+	g.space()
+	g.puts(g.symName(p))
+}
+
+// Returns list of function parameter symbols in the same order as
+// call arguments. For methods receiver goes first.
+func getParams(fun *stg.Fun) []*stg.Symbol {
+	n := len(fun.Params)
+	if fun.Receiver != nil {
+		n += 1
+	}
+	if n == 0 {
+		return nil
+	}
+
+	params := make([]*stg.Symbol, 0, n)
+	for _, s := range fun.Body.Scope.Symbols {
+		if s.Kind == smk.Receiver {
+			params = append(params, s)
+		}
+	}
+	for _, s := range fun.Body.Scope.Symbols {
+		if s.Kind == smk.Param {
+			params = append(params, s)
+		}
+	}
+	if len(params) != n {
+		panic(fmt.Sprintf("function has %d parameter symbol(s), but signature lists %d", len(params), n))
+	}
+	return params
+}
+
+// Generates C entrypoint which calls program main function.
+func (g *Gen) entry(main *stg.Symbol) {
+	g.puts(Entry(g.symName(main), main.Def.(*stg.Fun).Result != nil))
+}
+
+// Entry returns C entrypoint which calls main function with a given
+// name. If main function has result, it becomes process exit status.
+func Entry(name string, result bool) string {
+	if result {
+		return "int\nmain(void) {\n\treturn (int)" + name + "();\n}\n"
+	}
+	return "int\nmain(void) {\n\t" + name + "();\n\treturn 0;\n}\n"
+}
@@ -0,0 +1,206 @@
+// Package genc implements C code generation from typed and pruned program
+// graph (STG).
+//
+// In contrast with kub/genc which translates raw AST, generator in this package
+// relies on resolved types, link names and symbol skip flags produced by
+// typer and pruning phases.
+package genc
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+)
+
+// Program describes what should be placed into generated C code.
+type Program struct {
+	// Program units in order of their appearance in generated code.
+	// Units must be typed and pruned beforehand.
+	Units []*stg.Unit
+
+	// Main function of the program. If not nil, C entrypoint which calls
+	// this function is generated.
+	Main *stg.Symbol
+
+	// Test functions of the program. If not nil, C entrypoint of test
+	// executable is generated instead of the one which calls main function.
+	Tests []*stg.Symbol
+}
+
+// Generate writes C code for a given program into w. Returns diagnostic
+// (without writing anything) if program uses constructs which are not
+// supported by generator.
+func Generate(w io.Writer, p *Program) error {
+	var g Gen
+	g.init()
+	g.Program(p)
+	if g.err != nil {
+		return g.err
+	}
+	_, err := g.WriteTo(w)
+	return err
+}
+
+// Gen keeps C code generator state and output buffer.
+type Gen struct {
+	// Output buffer.
+	buf []byte
+
+	// Indentation buffer.
+	//
+	// Stores sequence of bytes which is used for indenting current line
+	// in output. When a new line starts this buffer is used to add indentation.
+	ib []byte
+
+	// Maps unit-level symbol to its link name.
+	names map[*stg.Symbol]string
+
+	// Maps type to its name in generated code.
+	types map[*stg.Type]string
+
+	// List of types which require definition in generated code.
+	// Listed in order of discovery.
+	defs []*stg.Type
+
+	// Definition state of types from defs list.
+	states map[*stg.Type]defState
+
+	// Custom struct and union types which were forward declared.
+	forwards map[*stg.Type]struct{}
+
+	// List of builtin generic function instances used in generated code.
+	insts []*stg.Symbol
+
+	// Set of instances from insts list.
+	instset map[*stg.Symbol]struct{}
+
+	// Local variables already declared inside current function.
+	declared map[*stg.Symbol]struct{}
+
+	// Number of anonymous aggregate types with generated names.
+	anons uint64
+
+	// Number of temporary variables with generated names.
+	temps uint64
+
+	// True when generator emits initializers of unit-level variables.
+	global bool
+
+	// First unsupported construct found during generation. Generator
+	// continues after it, but produced code is unusable.
+	err diag.Error
+}
+
+func (g *Gen) init() {
+	g.names = make(map[*stg.Symbol]string)
+	g.types = make(map[*stg.Type]string)
+	g.states = make(map[*stg.Type]defState)
+	g.forwards = make(map[*stg.Type]struct{})
+	g.instset = make(map[*stg.Symbol]struct{})
+	g.declared = make(map[*stg.Symbol]struct{})
+}
+
+// Program generates code for the whole program.
+//
+// Code is generated in two passes. First pass generates declarations and
+// definitions of functions and variables, collecting types and generic
+// instances along the way. Second pass generates type definitions and
+// instances which are then placed before the code from first pass.
+func (g *Gen) Program(p *Program) {
+	for _, u := range p.Units {
+		g.bindNames(u)
+	}
+
+	for _, u := range p.Units {
+		g.unitVars(u)
+	}
+	for _, u := range p.Units {
+		g.unitFunDecls(u)
+	}
+	for _, u := range p.Units {
+		g.unitFuns(u)
+	}
+	if p.Tests != nil {
+		g.testDriver(p.Tests)
+	} else if p.Main != nil {
+		g.entry(p.Main)
+	}
+
+	code := g.buf
+	g.buf = nil
+
+	g.puts(prelude)
+	g.typedefs()
+	g.instances()
+	g.put(code)
+}
+
+// unsupported records error about construct which is not supported
+// by generator yet. Only the first error is kept.
+func (g *Gen) unsupported(what string, span sm.Span) {
+	if g.err == nil {
+		g.err = diag.Unsupported(what+" in C backend", span)
+	}
+}
+
+func (g *Gen) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(g.Bytes())
+	return int64(n), err
+}
+
+func (g *Gen) Output() string {
+	return string(g.Bytes())
+}
+
+func (g *Gen) Bytes() []byte {
+	return g.buf
+}
+
+// put decimal formatted integer into output buffer
+func (g *Gen) putn(n uint64) {
+	g.puts(strconv.FormatUint(n, 10))
+}
+
+// put string into output buffer
+func (g *Gen) puts(s string) {
+	g.buf = append(g.buf, s...)
+}
+
+// put single byte into output buffer
+func (g *Gen) putb(b byte) {
+	g.buf = append(g.buf, b)
+}
+
+func (g *Gen) put(b []byte) {
+	g.buf = append(g.buf, b...)
+}
+
+func (g *Gen) nl() {
+	g.putb('\n')
+}
+
+func (g *Gen) space() {
+	g.putb(' ')
+}
+
+func (g *Gen) semi() {
+	g.putb(';')
+}
+
+// increment indentation by one level.
+func (g *Gen) inc() {
+	g.ib = append(g.ib, '\t')
+}
+
+// decrement indentation by one level.
+func (g *Gen) dec() {
+	g.ib = g.ib[:len(g.ib)-1]
+}
+
+// add indentation to current line.
+func (g *Gen) indent() {
+	g.put(g.ib)
+}
@@ -0,0 +1,71 @@
+package genc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mebyus/ku/goku/compiler/char"
+	"github.com/mebyus/ku/goku/compiler/enums/smk"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+)
+
+// Binds link names to unit-level symbols which can be present in generated code.
+func (g *Gen) bindNames(u *stg.Unit) {
+	prefix := unitPrefix(u.Path)
+	for _, s := range u.Scope.Symbols {
+		switch s.Kind {
+		case smk.Fun, smk.Method, smk.Test, smk.Var, smk.Type:
+			g.names[s] = linkName(prefix, s)
+		}
+	}
+}
+
+// Returns link name of a given unit-level symbol.
+//
+// Stubs and exported functions keep their source names (unless link name
+// was specified explicitly), other symbols are mangled with unit prefix.
+func linkName(prefix string, s *stg.Symbol) string {
+	if s.Link != "" {
+		return s.Link
+	}
+	if s.IsStub() || s.IsExport() {
+		return s.Name
+	}
+
+	// methods and tests contain period in their names
+	return prefix + strings.ReplaceAll(s.Name, ".", "__")
+}
+
+// Returns link name prefix for symbols of the unit with a given path.
+//
+//	<loc> foo/bar => "ku_loc_foo_bar_"
+func unitPrefix(p sm.UnitPath) string {
+	var b strings.Builder
+	b.WriteString("ku_")
+	b.WriteString(p.Origin.String())
+	b.WriteByte('_')
+	for i := range len(p.Import) {
+		c := p.Import[i]
+		if char.IsAlphanum(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	b.WriteByte('_')
+	return b.String()
+}
+
+// Returns name of a given symbol in generated code.
+func (g *Gen) symName(s *stg.Symbol) string {
+	name, ok := g.names[s]
+	if ok {
+		return name
+	}
+	if s.IsLocal() {
+		return s.Name
+	}
+
+	panic(fmt.Sprintf("%s symbol \"%s\" does not have link name", s.Kind, s.Name))
+}
@@ -0,0 +1,77 @@
+package genc
+
+// Code placed at the start of each generated file. Contains definitions
+// of builtin types and helpers which generated code relies upon.
+const prelude = `// Code generated by ku compiler. DO NOT EDIT.
+
+typedef unsigned char      u8;
+typedef unsigned short int u16;
+typedef unsigned int       u32;
+typedef unsigned long int  u64;
+typedef __uint128_t        u128;
+
+typedef signed char      s8;
+typedef signed short int s16;
+typedef signed int       s32;
+typedef signed long int  s64;
+typedef __int128_t       s128;
+
+typedef u64 uint;
+typedef s64 sint;
+
+typedef u32 rune;
+
+typedef float      f32;
+typedef double     f64;
+typedef __float128 f128;
+
+#if !defined(__STDC_VERSION__) || __STDC_VERSION__ < 202311L
+typedef _Bool bool;
+#define true  1
+#define false 0
+#endif
+
+#define nil 0
+
+typedef struct {
+	u8* ptr;
+	uint len;
+} str;
+
+#define empty_str ((str){})
+
+typedef uint errid;
+
+typedef struct {
+	errid id;
+	void* ptr;
+} error;
+
+static str
+make_str(u8* ptr, uint len) {
+	str s = {};
+	if (len == 0) {
+		return s;
+	}
+	s.ptr = ptr;
+	s.len = len;
+	return s;
+}
+
+static _Noreturn void
+ku_trap(void) {
+	__builtin_trap();
+}
+
+// declared here to avoid including libc headers into generated code
+long write(int fd, const void* buf, unsigned long n);
+
+static _Noreturn void
+ku_panic(str msg) {
+	write(2, "panic: ", 7);
+	write(2, msg.ptr, msg.len);
+	write(2, "\n", 1);
+	ku_trap();
+}
+
+`
@@ -0,0 +1,274 @@
+package genc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mebyus/ku/goku/compiler/enums/smk"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+)
+
+func (g *Gen) block(block *stg.Block) {
+	if len(block.Nodes) == 0 {
+		g.puts("{}")
+		return
+	}
+
+	g.puts("{")
+	g.nl()
+	g.inc()
+	for _, s := range block.Nodes {
+		g.indent()
+		g.node(s)
+		g.nl()
+	}
+	g.dec()
+	g.indent()
+	g.puts("}")
+}
+
+func (g *Gen) node(stm stg.Statement) {
+	switch s := stm.(type) {
+	case *stg.Block:
+		g.block(s)
+	case *stg.Ret:
+		g.ret(s)
+	case *stg.Var:
+		g.localVar(s)
+	case *stg.Assign:
+		g.assign(s)
+	case *stg.OpAssign:
+		g.opAssign(s)
+	case *stg.Invoke:
+		g.exp(s.Call)
+		g.semi()
+	case *stg.If:
+		g.ifStm(s)
+	case *stg.While:
+		g.puts("while (")
+		g.exp(s.Exp)
+		g.puts(") ")
+		g.block(&s.Body)
+	case *stg.Loop:
+		g.puts("while (true) ")
+		g.block(&s.Body)
+	case *stg.ForRange:
+		g.forRange(s)
+	case *stg.MatchInteger:
+		g.match(s)
+	case *stg.Break:
+		g.puts("break;")
+	case *stg.Gonext:
+		g.puts("continue;")
+	case *stg.Must:
+		g.puts("if (!(")
+		g.exp(s.Exp)
+		g.puts(")) {")
+		g.nl()
+		g.inc()
+		g.indent()
+		g.puts("ku_trap();")
+		g.nl()
+		g.dec()
+		g.indent()
+		g.puts("}")
+	case *stg.Panic:
+		g.puts("ku_panic(")
+		g.exp(s.Exp)
+		g.puts(");")
+	case *stg.Stub, *stg.Never:
+		g.puts("ku_trap();")
+	case *stg.DeferCall:
+		g.unsupported("defer", s.Call.Span())
+	default:
+		panic(fmt.Sprintf("unexpected (%T) statement", s))
+	}
+}
+
+func (g *Gen) ret(r *stg.Ret) {
+	if r.Exp == nil {
+		g.puts("return;")
+		return
+	}
+
+	g.puts("return ")
+	g.exp(r.Exp)
+	g.semi()
+}
+
+func (g *Gen) localVar(v *stg.Var) {
+	g.declared[v.Symbol] = struct{}{}
+
+	g.typ(v.Symbol.Type)
+	g.space()
+	g.puts(g.symName(v.Symbol))
+	g.puts(" = ")
+	if v.Exp == nil {
+		g.puts(zeroValue(v.Symbol.Type))
+	} else {
+		g.exp(v.Exp)
+	}
+	g.semi()
+}
+
+func (g *Gen) assign(a *stg.Assign) {
+	switch t := a.Target.(type) {
+	case *stg.SymExp:
+		if g.isImplicitDef(t.Symbol) {
+			// first assignment to implicitly defined variable
+			// is its declaration
+			g.localVar(&stg.Var{Symbol: t.Symbol, Exp: a.Exp})
+			return
+		}
+	case *stg.Pack:
+		g.packAssign(t, a.Exp)
+		return
+	}
+
+	g.exp(a.Target)
+	g.puts(" = ")
+	g.exp(a.Exp)
+	g.semi()
+}
+
+// Operation assignment uses compound assignment operator from C,
+// thus target expression is evaluated only once. Result of integer
+// promotion is implicitly converted back to target type.
+func (g *Gen) opAssign(a *stg.OpAssign) {
+	g.exp(a.Target)
+	g.space()
+	g.puts(a.Op.Kind.String())
+	g.puts("= ")
+	g.exp(a.Exp)
+	g.semi()
+}
+
+// Reports whether assignment to a given symbol declares local variable.
+func (g *Gen) isImplicitDef(s *stg.Symbol) bool {
+	_, ok := g.declared[s]
+	return s.Kind == smk.Var && s.IsLocal() && !ok
+}
+
+// Multiple assignment stores tuple value in temporary variable first,
+// so that all values are evaluated before any of the targets is changed.
+// This allows swaps like "a, b = b, a".
+func (g *Gen) packAssign(p *stg.Pack, exp stg.Exp) {
+	g.temps += 1
+	name := "ku_pack_" + strconv.FormatUint(g.temps, 10)
+
+	g.typ(exp.Type())
+	g.space()
+	g.puts(name)
+	g.puts(" = ")
+	g.exp(exp)
+	g.semi()
+
+	for i, target := range p.List {
+		g.nl()
+		g.indent()
+
+		s, ok := target.(*stg.SymExp)
+		if ok && g.isImplicitDef(s.Symbol) {
+			g.declared[s.Symbol] = struct{}{}
+			g.typ(s.Symbol.Type)
+			g.space()
+		}
+		g.exp(target)
+		g.puts(" = ")
+		g.puts(name)
+		g.puts(".")
+		g.puts(tupleField(i))
+		g.semi()
+	}
+}
+
+func (g *Gen) ifStm(f *stg.If) {
+	g.puts("if (")
+	g.exp(f.Branches[0].Exp)
+	g.puts(") ")
+	g.block(&f.Branches[0].Block)
+
+	for _, b := range f.Branches[1:] {
+		g.puts(" else if (")
+		g.exp(b.Exp)
+		g.puts(") ")
+		g.block(&b.Block)
+	}
+
+	if f.Else != nil {
+		g.puts(" else ")
+		g.block(f.Else)
+	}
+}
+
+func (g *Gen) forRange(r *stg.ForRange) {
+	name := g.symName(r.Var)
+	typ := g.typeName(r.Var.Type)
+
+	g.puts("for (")
+	g.puts(typ)
+	g.space()
+	g.puts(name)
+	g.puts(" = ")
+	if r.Start == nil {
+		g.puts("0")
+	} else {
+		g.cast(typ, r.Start)
+	}
+	g.puts("; ")
+	g.puts(name)
+	g.puts(" < ")
+	g.cast(typ, r.End)
+	g.puts("; ")
+	g.puts(name)
+	g.puts(" += 1) ")
+	g.block(&r.Body)
+}
+
+// Match is generated as if-else chain instead of C switch statement,
+// since break inside switch would not reach surrounding loop.
+func (g *Gen) match(m *stg.MatchInteger) {
+	g.temps += 1
+	name := "ku_match_" + strconv.FormatUint(g.temps, 10)
+
+	g.puts("{")
+	g.nl()
+	g.inc()
+	g.indent()
+	g.typ(m.Exp.Type())
+	g.space()
+	g.puts(name)
+	g.puts(" = ")
+	g.exp(m.Exp)
+	g.semi()
+	g.nl()
+
+	g.indent()
+	for i, c := range m.Cases {
+		if i != 0 {
+			g.puts(" else ")
+		}
+		g.puts("if (")
+		for j, e := range c.List {
+			if j != 0 {
+				g.puts(" || ")
+			}
+			g.puts(name)
+			g.puts(" == ")
+			g.exp(e)
+		}
+		g.puts(") ")
+		g.block(&c.Body)
+	}
+	if m.Else != nil {
+		if len(m.Cases) != 0 {
+			g.puts(" else ")
+		}
+		g.block(m.Else)
+	}
+	g.nl()
+
+	g.dec()
+	g.indent()
+	g.puts("}")
+}
@@ -0,0 +1,104 @@
+package genc
+
+import (
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+)
+
+// Generates C entrypoint of test executable. Test is selected by its index
+// in the list, index is passed as the only command line argument.
+//
+// Executable exits with zero status if selected test passes. Failed test
+// traps or exits with non-zero status. Status 2 is reserved for invalid
+// command line arguments.
+func (g *Gen) testDriver(tests []*stg.Symbol) {
+	g.puts("int")
+	g.nl()
+	g.puts("main(int argc, char** argv) {")
+	g.nl()
+	g.inc()
+
+	g.indent()
+	g.puts("if (argc != 2) {")
+	g.nl()
+	g.indent()
+	g.puts("\treturn 2;")
+	g.nl()
+	g.indent()
+	g.puts("}")
+	g.nl()
+	g.nl()
+
+	g.indent()
+	g.puts("uint n = 0;")
+	g.nl()
+	g.indent()
+	g.puts("for (char* s = argv[1]; *s != 0; s += 1) {")
+	g.nl()
+	g.indent()
+	g.puts("\tif (*s < '0' || *s > '9') {")
+	g.nl()
+	g.indent()
+	g.puts("\t\treturn 2;")
+	g.nl()
+	g.indent()
+	g.puts("\t}")
+	g.nl()
+	g.indent()
+	g.puts("\tn = n * 10 + (uint)(*s - '0');")
+	g.nl()
+	g.indent()
+	g.puts("}")
+	g.nl()
+	g.nl()
+
+	if len(tests) != 0 {
+		g.indent()
+		g.typ(testContextType(tests[0]))
+		g.puts(" t = {};")
+		g.nl()
+		g.nl()
+	}
+
+	g.indent()
+	g.puts("switch (n) {")
+	g.nl()
+	for i, s := range tests {
+		g.indent()
+		g.puts("case ")
+		g.putn(uint64(i))
+		g.puts(":")
+		g.nl()
+		g.indent()
+		g.puts("\t")
+		g.puts(g.symName(s))
+		g.puts("(&t);")
+		g.nl()
+		g.indent()
+		g.puts("\tbreak;")
+		g.nl()
+	}
+	g.indent()
+	g.puts("default:")
+	g.nl()
+	g.indent()
+	g.puts("\treturn 2;")
+	g.nl()
+	g.indent()
+	g.puts("}")
+	g.nl()
+	g.nl()
+
+	g.indent()
+	g.puts("return 0;")
+	g.nl()
+
+	g.dec()
+	g.puts("}")
+	g.nl()
+}
+
+// Returns type of context which is passed to test function by reference.
+func testContextType(s *stg.Symbol) *stg.Type {
+	t := s.Def.(*stg.Fun).Params[0]
+	return t.Def.(stg.Ref).Type
+}
@@ -0,0 +1,394 @@
+package genc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mebyus/ku/goku/compiler/enums/tpk"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+)
+
+// defState indicates whether type definition was already placed into output.
+type defState uint8
+
+const (
+	defNone defState = iota
+
+	// Definition of the type is being generated right now.
+	defProgress
+
+	defDone
+)
+
+func (g *Gen) typ(t *stg.Type) {
+	g.puts(g.typeName(t))
+}
+
+// Returns name of a given type in generated code. Types which need definition
+// are remembered and defined later.
+func (g *Gen) typeName(t *stg.Type) string {
+	if t == nil {
+		panic("nil type")
+	}
+
+	name, ok := g.types[t]
+	if ok {
+		return name
+	}
+
+	name = g.newTypeName(t)
+	g.types[t] = name
+	if needsDef(t) {
+		g.defs = append(g.defs, t)
+	}
+	return name
+}
+
+func (g *Gen) newTypeName(t *stg.Type) string {
+	switch t.Kind {
+	case tpk.Void:
+		return "void"
+	case tpk.Boolean:
+		return "bool"
+	case tpk.String:
+		return "str"
+	case tpk.Rune:
+		return "rune"
+	case tpk.ErrId:
+		return "errid"
+	case tpk.Error:
+		return "error"
+	case tpk.Integer, tpk.Enum:
+		return integerTypeName(t)
+	case tpk.Float:
+		return floatTypeName(t)
+	case tpk.VoidPointer, tpk.VoidRef:
+		return "void*"
+	case tpk.Pointer:
+		return g.typeName(t.Def.(stg.Pointer).Type) + "*"
+	case tpk.Ref:
+		return g.typeName(t.Def.(stg.Ref).Type) + "*"
+	case tpk.ArrayPointer:
+		return g.typeName(t.Def.(stg.ArrayPointer).Type) + "*"
+	case tpk.ArrayRef:
+		return g.typeName(t.Def.(stg.ArrayRef).Type) + "*"
+	case tpk.Span:
+		return "ku_span_" + mangleTypeName(g.typeName(t.Def.(stg.Span).Type))
+	case tpk.Array:
+		a := t.Def.(stg.Array)
+		return "ku_array_" + strconv.FormatUint(uint64(a.Len), 10) + "_" + mangleTypeName(g.typeName(a.Type))
+	case tpk.Custom:
+		return g.symName(t.Def.(*stg.Custom).Symbol)
+	case tpk.Struct:
+		g.anons += 1
+		return "ku_struct_" + strconv.FormatUint(g.anons, 10)
+	case tpk.Union:
+		g.anons += 1
+		return "ku_union_" + strconv.FormatUint(g.anons, 10)
+	case tpk.Tuple:
+		g.anons += 1
+		return "ku_tuple_" + strconv.FormatUint(g.anons, 10)
+	default:
+		panic(fmt.Sprintf("%s (=%d) type not implemented", t.Kind, t.Kind))
+	}
+}
+
+func integerTypeName(t *stg.Type) string {
+	if t.Size == 0 {
+		// unsized static integer
+		return "sint"
+	}
+
+	var s string
+	switch t.Size {
+	case 1:
+		s = "8"
+	case 2:
+		s = "16"
+	case 4:
+		s = "32"
+	case 8:
+		s = "64"
+	case 16:
+		s = "128"
+	default:
+		panic(fmt.Sprintf("unexpected integer size (=%d)", t.Size))
+	}
+	if t.IsSigned() {
+		return "s" + s
+	}
+	return "u" + s
+}
+
+func floatTypeName(t *stg.Type) string {
+	switch t.Size {
+	case 0, 8:
+		return "f64"
+	case 4:
+		return "f32"
+	case 16:
+		return "f128"
+	default:
+		panic(fmt.Sprintf("unexpected float size (=%d)", t.Size))
+	}
+}
+
+// Transforms type name into a form suitable for inclusion into other names.
+func mangleTypeName(name string) string {
+	b := make([]byte, 0, len(name))
+	for i := range len(name) {
+		c := name[i]
+		if c == '*' {
+			b = append(b, "_ptr"...)
+		} else {
+			b = append(b, c)
+		}
+	}
+	return string(b)
+}
+
+// Returns true for types which must be defined in generated code before usage.
+func needsDef(t *stg.Type) bool {
+	switch t.Kind {
+	case tpk.Custom, tpk.Span, tpk.Array, tpk.Struct, tpk.Union, tpk.Tuple:
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns initializer which sets value of a given type to zero.
+func zeroValue(t *stg.Type) string {
+	if t.Kind == tpk.Custom {
+		t = t.Def.(*stg.Custom).Type
+	}
+	switch t.Kind {
+	case tpk.Struct, tpk.Union, tpk.Array, tpk.Span, tpk.String, tpk.Tuple, tpk.Error:
+		return "{}"
+	default:
+		return "0"
+	}
+}
+
+// Returns true for custom types with struct or union as base type.
+func isCustomAggregate(t *stg.Type) bool {
+	if t.Kind != tpk.Custom {
+		return false
+	}
+	k := t.Def.(*stg.Custom).Type.Kind
+	return k == tpk.Struct || k == tpk.Union
+}
+
+// Generates definitions for all types used in generated code.
+// Definitions are placed in order of their value dependencies.
+func (g *Gen) typedefs() {
+	// list of types may grow while we generate definitions
+	for i := 0; i < len(g.defs); i += 1 {
+		g.define(g.defs[i])
+	}
+}
+
+func (g *Gen) define(t *stg.Type) {
+	switch g.states[t] {
+	case defDone:
+		return
+	case defProgress:
+		panic(fmt.Sprintf("type %s has recursive definition by value", t))
+	}
+	g.states[t] = defProgress
+
+	name := g.typeName(t)
+	switch t.Kind {
+	case tpk.Custom:
+		g.defineCustom(name, t)
+	case tpk.Span:
+		elem := t.Def.(stg.Span).Type
+		g.declare(elem)
+		g.puts("typedef struct {")
+		g.nl()
+		g.puts("\t")
+		g.typ(elem)
+		g.puts("* ptr;")
+		g.nl()
+		g.puts("\tuint len;")
+		g.nl()
+		g.puts("} ")
+		g.puts(name)
+		g.puts(";")
+		g.nl()
+		g.nl()
+	case tpk.Array:
+		a := t.Def.(stg.Array)
+		g.dep(a.Type)
+		g.puts("typedef struct {")
+		g.nl()
+		g.puts("\t")
+		g.typ(a.Type)
+		g.puts(" arr[")
+		g.putn(uint64(a.Len))
+		g.puts("];")
+		g.nl()
+		g.puts("} ")
+		g.puts(name)
+		g.puts(";")
+		g.nl()
+		g.nl()
+	case tpk.Struct:
+		fields := t.Def.(*stg.Struct).Fields
+		g.fieldDeps(fields)
+		g.puts("typedef struct ")
+		g.fields(fields)
+		g.space()
+		g.puts(name)
+		g.puts(";")
+		g.nl()
+		g.nl()
+	case tpk.Union:
+		fields := t.Def.(*stg.Union).Fields
+		g.fieldDeps(fields)
+		g.puts("typedef union ")
+		g.fields(fields)
+		g.space()
+		g.puts(name)
+		g.puts(";")
+		g.nl()
+		g.nl()
+	case tpk.Tuple:
+		types := t.Def.(stg.Tuple).Types
+		for _, elem := range types {
+			g.dep(elem)
+		}
+		g.puts("typedef struct {")
+		g.nl()
+		for i, elem := range types {
+			g.puts("\t")
+			g.typ(elem)
+			g.space()
+			g.puts(tupleField(i))
+			g.puts(";")
+			g.nl()
+		}
+		g.puts("} ")
+		g.puts(name)
+		g.puts(";")
+		g.nl()
+		g.nl()
+	default:
+		panic(fmt.Sprintf("unexpected %s (=%d) type", t.Kind, t.Kind))
+	}
+
+	g.states[t] = defDone
+}
+
+func (g *Gen) defineCustom(name string, t *stg.Type) {
+	base := t.Def.(*stg.Custom).Type
+	switch base.Kind {
+	case tpk.Struct:
+		fields := base.Def.(*stg.Struct).Fields
+		g.forward(t)
+		g.fieldDeps(fields)
+		g.puts("struct ")
+		g.puts(name)
+		g.space()
+		g.fields(fields)
+		g.puts(";")
+	case tpk.Union:
+		fields := base.Def.(*stg.Union).Fields
+		g.forward(t)
+		g.fieldDeps(fields)
+		g.puts("union ")
+		g.puts(name)
+		g.space()
+		g.fields(fields)
+		g.puts(";")
+	default:
+		g.dep(base)
+		g.puts("typedef ")
+		g.typ(base)
+		g.space()
+		g.puts(name)
+		g.puts(";")
+	}
+	g.nl()
+	g.nl()
+}
+
+// Generates forward declaration of custom struct or union type.
+// Does nothing if type was already declared.
+func (g *Gen) forward(t *stg.Type) {
+	_, ok := g.forwards[t]
+	if ok {
+		return
+	}
+	g.forwards[t] = struct{}{}
+
+	keyword := "struct"
+	if t.Def.(*stg.Custom).Type.Kind == tpk.Union {
+		keyword = "union"
+	}
+	name := g.typeName(t)
+
+	g.puts("typedef ")
+	g.puts(keyword)
+	g.space()
+	g.puts(name)
+	g.space()
+	g.puts(name)
+	g.puts(";")
+	g.nl()
+	g.nl()
+}
+
+func (g *Gen) fields(fields []stg.Field) {
+	g.puts("{")
+	g.nl()
+	for _, f := range fields {
+		g.puts("\t")
+		g.typ(f.Type)
+		g.space()
+		g.puts(f.Name)
+		g.puts(";")
+		g.nl()
+	}
+	g.puts("}")
+}
+
+// Returns name of struct field which holds tuple element with a given index.
+func tupleField(i int) string {
+	return "f" + strconv.Itoa(i)
+}
+
+func (g *Gen) fieldDeps(fields []stg.Field) {
+	for _, f := range fields {
+		g.dep(f.Type)
+	}
+}
+
+// Ensures that a given type can be used by value in code which follows.
+func (g *Gen) dep(t *stg.Type) {
+	switch t.Kind {
+	case tpk.Pointer:
+		g.declare(t.Def.(stg.Pointer).Type)
+	case tpk.Ref:
+		g.declare(t.Def.(stg.Ref).Type)
+	case tpk.ArrayPointer:
+		g.declare(t.Def.(stg.ArrayPointer).Type)
+	case tpk.ArrayRef:
+		g.declare(t.Def.(stg.ArrayRef).Type)
+	default:
+		g.typeName(t)
+		if needsDef(t) {
+			g.define(t)
+		}
+	}
+}
+
+// Ensures that a given type can be used behind a pointer in code which follows.
+func (g *Gen) declare(t *stg.Type) {
+	if !isCustomAggregate(t) {
+		g.dep(t)
+		return
+	}
+
+	g.forward(t)
+}
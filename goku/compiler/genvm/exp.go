@@ -0,0 +1,259 @@
+package genvm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/enums/bok"
+	"github.com/mebyus/ku/goku/compiler/enums/tpk"
+	"github.com/mebyus/ku/goku/compiler/enums/uok"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+	"github.com/mebyus/ku/goku/vm/ssa"
+)
+
+func (g *Gen) exp(exp stg.Exp) (*ssa.Value, diag.Error) {
+	switch e := exp.(type) {
+	case *stg.Integer:
+		if e.Neg {
+			return g.b.Const(-e.Val), nil
+		}
+		return g.b.Const(e.Val), nil
+	case *stg.Rune:
+		return g.b.Const(uint64(e.Val)), nil
+	case *stg.Boolean:
+		return g.boolean(e.Val), nil
+	case *stg.BoolExp:
+		_, err := g.exp(e.Exp)
+		if err != nil {
+			return nil, err
+		}
+		return g.boolean(e.Val), nil
+	case *stg.SymExp:
+		return g.sym(e)
+	case *stg.Unary:
+		return g.unary(e)
+	case *stg.Binary:
+		return g.binary(e)
+	case *stg.Call:
+		return g.call(e)
+	case *stg.Cast:
+		return g.cast(e)
+	default:
+		// "*stg.Pack" => "Pack"
+		name := strings.TrimPrefix(fmt.Sprintf("%T", e), "*stg.")
+		return nil, diag.Unsupported(name+" expression in vm backend", e.Span())
+	}
+}
+
+func (g *Gen) boolean(v bool) *ssa.Value {
+	if v {
+		return g.b.Const(1)
+	}
+	return g.b.Const(0)
+}
+
+func (g *Gen) sym(e *stg.SymExp) (*ssa.Value, diag.Error) {
+	v, ok := g.vals[e.Symbol]
+	if !ok {
+		span := sm.Span{Pin: e.Pin, Len: uint32(len(e.Symbol.Name))}
+		return nil, diag.Unsupported(fmt.Sprintf("usage of %s symbol \"%s\" in vm backend", e.Symbol.Kind, e.Symbol.Name), span)
+	}
+	return v, nil
+}
+
+func (g *Gen) unary(u *stg.Unary) (*ssa.Value, diag.Error) {
+	v, err := g.exp(u.Exp)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Op.Kind {
+	case uok.Not:
+		return g.b.Bin(ssa.OpXor, v, g.b.Const(1)), nil
+	case uok.Plus:
+		return v, nil
+	case uok.Minus:
+		return g.wrap(u.Type(), g.b.Bin(ssa.OpSub, g.b.Const(0), v)), nil
+	case uok.BitNot:
+		return g.wrap(u.Type(), g.b.Bin(ssa.OpXor, v, g.b.Const(^uint64(0)))), nil
+	default:
+		panic(fmt.Sprintf("unexpected %s (=%d) unary operator", u.Op.Kind, u.Op.Kind))
+	}
+}
+
+func (g *Gen) binary(b *stg.Binary) (*ssa.Value, diag.Error) {
+	switch b.Op.Kind {
+	case bok.And, bok.Or:
+		return g.logical(b)
+	}
+
+	x, err := g.exp(b.A)
+	if err != nil {
+		return nil, err
+	}
+	y, err := g.exp(b.B)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b.Op.Kind {
+	case bok.Equal, bok.NotEqual, bok.Less, bok.Greater, bok.LessOrEqual, bok.GreaterOrEqual:
+		// static operand takes type of the other one
+		typ := b.A.Type()
+		if typ.IsStatic() {
+			typ = b.B.Type()
+		}
+		return g.compare(b.Op.Kind, typ, x, y), nil
+	default:
+		return g.binOp(b.Op, b.Type(), x, y, b.Span())
+	}
+}
+
+var compareOps = [...]struct {
+	signed   ssa.Op
+	unsigned ssa.Op
+}{
+	bok.Equal:          {signed: ssa.OpEq, unsigned: ssa.OpEq},
+	bok.NotEqual:       {signed: ssa.OpNe, unsigned: ssa.OpNe},
+	bok.Less:           {signed: ssa.OpLt, unsigned: ssa.OpULt},
+	bok.Greater:        {signed: ssa.OpGt, unsigned: ssa.OpUGt},
+	bok.LessOrEqual:    {signed: ssa.OpLe, unsigned: ssa.OpULe},
+	bok.GreaterOrEqual: {signed: ssa.OpGe, unsigned: ssa.OpUGe},
+}
+
+func (g *Gen) compare(k bok.Kind, typ *stg.Type, x, y *ssa.Value) *ssa.Value {
+	op := compareOps[k].unsigned
+	if baseType(typ).IsSigned() {
+		op = compareOps[k].signed
+	}
+	return g.b.Bin(op, x, y)
+}
+
+var arithOps = [...]ssa.Op{
+	bok.Add:        ssa.OpAdd,
+	bok.Sub:        ssa.OpSub,
+	bok.Mul:        ssa.OpMul,
+	bok.Div:        ssa.OpDiv,
+	bok.Mod:        ssa.OpRem,
+	bok.Xor:        ssa.OpXor,
+	bok.BitAnd:     ssa.OpAnd,
+	bok.BitOr:      ssa.OpOr,
+	bok.LeftShift:  ssa.OpShl,
+	bok.RightShift: ssa.OpShr,
+}
+
+var signedOps = [...]ssa.Op{
+	bok.Mod:        ssa.OpSRem,
+	bok.RightShift: ssa.OpSar,
+}
+
+// Generates arithmetic or bitwise operation with result of a given type.
+// Span is used for diagnostics.
+func (g *Gen) binOp(op stg.BinOp, typ *stg.Type, x, y *ssa.Value, span sm.Span) (*ssa.Value, diag.Error) {
+	k := op.Kind
+	switch k {
+	case bok.Div:
+		if baseType(typ).IsSigned() {
+			// dividing minimum value by -1 overflows
+			return g.wrap(typ, g.b.Bin(ssa.OpSDiv, x, y)), nil
+		}
+		return g.b.Bin(ssa.OpDiv, x, y), nil
+	case bok.Mod, bok.RightShift:
+		if baseType(typ).IsSigned() {
+			return g.b.Bin(signedOps[k], x, y), nil
+		}
+		return g.b.Bin(arithOps[k], x, y), nil
+	case bok.Xor, bok.BitAnd, bok.BitOr:
+		return g.b.Bin(arithOps[k], x, y), nil
+	case bok.Add, bok.Sub, bok.Mul, bok.LeftShift:
+		return g.wrap(typ, g.b.Bin(arithOps[k], x, y)), nil
+	case bok.BitAndNot:
+		all := g.b.Const(^uint64(0))
+		return g.b.Bin(ssa.OpAnd, x, g.b.Bin(ssa.OpXor, y, all)), nil
+	default:
+		return nil, diag.Unsupported(fmt.Sprintf("operator \"%s\" in vm backend", k), span)
+	}
+}
+
+// Generates short circuit evaluation of logical "and" and "or" operators.
+// Second operand is evaluated only if first one does not determine
+// the result.
+func (g *Gen) logical(b *stg.Binary) (*ssa.Value, diag.Error) {
+	x, err := g.exp(b.A)
+	if err != nil {
+		return nil, err
+	}
+
+	rhs := g.f.NewBlock()
+	join := g.f.NewBlock()
+	r := join.Param()
+
+	// result when second operand is skipped
+	skip := g.boolean(b.Op.Kind == bok.Or)
+	if b.Op.Kind == bok.And {
+		g.b.Branch(x, ssa.Edge{Block: rhs}, ssa.Edge{Block: join, Args: []*ssa.Value{skip}})
+	} else {
+		g.b.Branch(x, ssa.Edge{Block: join, Args: []*ssa.Value{skip}}, ssa.Edge{Block: rhs})
+	}
+
+	g.b = rhs
+	y, err := g.exp(b.B)
+	if err != nil {
+		return nil, err
+	}
+	g.b.Jump(join, y)
+
+	g.b = join
+	return r, nil
+}
+
+func (g *Gen) call(c *stg.Call) (*ssa.Value, diag.Error) {
+	f, err := g.getFun(c.Symbol, c.Span())
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]*ssa.Value, 0, len(c.Args))
+	for _, a := range c.Args {
+		v, err := g.exp(a)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	return g.b.Call(f, args...), nil
+}
+
+func (g *Gen) cast(c *stg.Cast) (*ssa.Value, diag.Error) {
+	if !isScalar(c.Type()) || !isScalar(c.Exp.Type()) {
+		return nil, unsupportedType(c.Type(), c.Span())
+	}
+
+	v, err := g.exp(c.Exp)
+	if err != nil {
+		return nil, err
+	}
+	return g.wrap(c.Type(), v), nil
+}
+
+// wrap truncates value to integer type size. All values are kept in 64-bit
+// registers, unsigned integers are zero-extended and signed integers are
+// sign-extended to full register width.
+func (g *Gen) wrap(t *stg.Type, v *ssa.Value) *ssa.Value {
+	t = baseType(t)
+	if t.Kind != tpk.Integer && t.Kind != tpk.Rune || t.Size == 0 || t.Size >= 8 {
+		return v
+	}
+
+	bits := 8 * uint64(t.Size)
+	v = g.b.Bin(ssa.OpAnd, v, g.b.Const(1<<bits-1))
+	if !t.IsSigned() {
+		return v
+	}
+
+	// (v ^ sign) - sign extends sign bit into upper bits
+	sign := g.b.Const(1 << (bits - 1))
+	return g.b.Bin(ssa.OpSub, g.b.Bin(ssa.OpXor, v, sign), sign)
+}
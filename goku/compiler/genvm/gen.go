@@ -0,0 +1,295 @@
+// Package genvm implements VM code generation from typed and pruned program
+// graph (STG).
+//
+// Each function is translated into SSA form (see goku/vm/ssa package), which
+// is then lowered into VM assembly representation and assembled into kvx
+// executable. Only functions reachable from program entrypoint are translated.
+//
+// All values are kept in 64-bit registers, thus only integer, rune and
+// boolean values are supported for now. Program exit status equals result
+// of main function.
+package genvm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mebyus/ku/goku/compiler/char"
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/enums/smk"
+	"github.com/mebyus/ku/goku/compiler/enums/tpk"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+	"github.com/mebyus/ku/goku/vm/asm"
+	"github.com/mebyus/ku/goku/vm/kvx"
+	"github.com/mebyus/ku/goku/vm/ssa"
+)
+
+// Program describes what should be placed into VM executable.
+type Program struct {
+	// Program units. Units must be typed and pruned beforehand.
+	Units []*stg.Unit
+
+	// Main function of the program. Used as entrypoint if test is not
+	// specified.
+	Main *stg.Symbol
+
+	// Test function of the program. If not nil, entrypoint calls this test
+	// instead of main function. Program exits with zero status if test
+	// passes and traps if it fails.
+	Test *stg.Symbol
+}
+
+// Generate translates a given program into VM executable.
+func Generate(p *Program) (*kvx.Program, diag.Error) {
+	prog, err := Lower(p)
+	if err != nil {
+		return nil, err
+	}
+	code, lerr := ssa.Lower(prog)
+	if lerr != nil {
+		return nil, &diag.SimpleMessageError{Text: lerr.Error()}
+	}
+	return asm.Assemble(code), nil
+}
+
+// Lower translates functions reachable from program entrypoint into SSA form.
+func Lower(p *Program) (*ssa.Program, diag.Error) {
+	entry := p.Test
+	if entry == nil {
+		entry = p.Main
+	}
+	if entry == nil {
+		panic("program has no entrypoint")
+	}
+
+	g := Gen{
+		funs:  make(map[*stg.Symbol]*ssa.Fun),
+		names: make(map[*stg.Symbol]string),
+	}
+	for _, u := range p.Units {
+		g.bindNames(u)
+	}
+
+	f, err := g.getFun(entry, sm.Span{Pin: entry.Pin})
+	if err != nil {
+		return nil, err
+	}
+
+	// functions are added to the queue upon first call
+	for len(g.queue) != 0 {
+		s := g.queue[0]
+		g.queue = g.queue[1:]
+
+		err := g.fun(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var prog ssa.Program
+	prog.Entry = f
+	if p.Test != nil || entry.Def.(*stg.Fun).Result == nil {
+		// entrypoint must produce exit status
+		prog.Entry = wrapEntry(f)
+		prog.Funs = append(prog.Funs, prog.Entry)
+	}
+	prog.Funs = append(prog.Funs, g.list...)
+	return &prog, nil
+}
+
+// Creates function which calls a given function and returns zero.
+// Test context is not supported, thus tests receive zero pointer
+// as context argument.
+func wrapEntry(f *ssa.Fun) *ssa.Fun {
+	w := ssa.NewFun("entry", 0)
+	b := w.NewBlock()
+	args := make([]*ssa.Value, len(f.Params))
+	for i := range args {
+		args[i] = b.Const(0)
+	}
+	b.Call(f, args...)
+	b.Return(b.Const(0))
+	return w
+}
+
+// Gen keeps state of program lowering.
+type Gen struct {
+	// Maps function symbol to its SSA form.
+	funs map[*stg.Symbol]*ssa.Fun
+
+	// Names of unit-level symbols in generated code.
+	names map[*stg.Symbol]string
+
+	// Functions which were called, but not yet translated.
+	queue []*stg.Symbol
+
+	// Functions in order of their creation.
+	list []*ssa.Fun
+
+	// state of currently lowered function
+
+	f *ssa.Fun
+
+	// Local variables (including parameters) which are visible at
+	// current position, in order of their definition.
+	vars []*stg.Symbol
+
+	// Maps local variable to its current value.
+	vals map[*stg.Symbol]*ssa.Value
+
+	// Stack of loops which enclose current position.
+	loops []loop
+
+	// Block which receives generated instructions. Equals nil when
+	// current position is unreachable (for example after return).
+	b *ssa.Block
+}
+
+// Binds names to unit-level symbols which can be present in generated code.
+//
+//	<loc> foo/bar, function "baz" => "loc_foo_bar_baz"
+func (g *Gen) bindNames(u *stg.Unit) {
+	var b strings.Builder
+	b.WriteString(u.Path.Origin.String())
+	b.WriteByte('_')
+	for i := range len(u.Path.Import) {
+		c := u.Path.Import[i]
+		if char.IsAlphanum(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	b.WriteByte('_')
+	prefix := b.String()
+
+	for _, s := range u.Scope.Symbols {
+		switch s.Kind {
+		case smk.Fun, smk.Method, smk.Test:
+			// methods and tests contain period in their names
+			g.names[s] = prefix + strings.ReplaceAll(s.Name, ".", "__")
+		}
+	}
+}
+
+// Returns SSA function of a given function symbol. Function is queued for
+// translation when requested for the first time.
+func (g *Gen) getFun(s *stg.Symbol, span sm.Span) (*ssa.Fun, diag.Error) {
+	f, ok := g.funs[s]
+	if ok {
+		return f, nil
+	}
+
+	switch s.Kind {
+	case smk.Fun, smk.Method, smk.Test:
+	default:
+		return nil, diag.Unsupported(fmt.Sprintf("call to %s symbol \"%s\" in vm backend", s.Kind, s.Name), span)
+	}
+	if s.IsStub() {
+		return nil, diag.Unsupported(fmt.Sprintf("call to stub function \"%s\" in vm backend", s.Name), span)
+	}
+
+	fun := s.Def.(*stg.Fun)
+	params := getParams(fun)
+	if s.Kind != smk.Test {
+		// test context is passed as zero pointer
+		for _, p := range params {
+			if !isScalar(p.Type) {
+				return nil, unsupportedType(p.Type, symSpan(p))
+			}
+		}
+	}
+	if fun.Result != nil && !isScalar(fun.Result) {
+		return nil, unsupportedType(fun.Result, symSpan(s))
+	}
+
+	f = ssa.NewFun(g.names[s], len(params))
+	g.funs[s] = f
+	g.list = append(g.list, f)
+	g.queue = append(g.queue, s)
+	return f, nil
+}
+
+func (g *Gen) fun(s *stg.Symbol) diag.Error {
+	fun := s.Def.(*stg.Fun)
+
+	g.f = g.funs[s]
+	g.vars = g.vars[:0]
+	g.vals = make(map[*stg.Symbol]*ssa.Value)
+	g.loops = g.loops[:0]
+
+	for i, p := range getParams(fun) {
+		v := g.f.Params[i]
+		v.Name = p.Name
+		g.define(p, v)
+	}
+
+	g.b = g.f.NewBlock()
+	err := g.block(&fun.Body)
+	if err != nil {
+		return err
+	}
+	if g.b != nil {
+		// execution falls through the end of function body
+		if fun.Result != nil || fun.Never {
+			g.b.Trap()
+		} else {
+			g.b.Return(nil)
+		}
+	}
+
+	// drop blocks which were created, but turned out to be unreachable
+	blocks := g.f.Blocks[:0]
+	for _, b := range g.f.Blocks {
+		if b.Term != nil {
+			blocks = append(blocks, b)
+		}
+	}
+	g.f.Blocks = blocks
+	return nil
+}
+
+// Returns list of function parameter symbols in the same order as
+// call arguments. For methods receiver goes first.
+func getParams(fun *stg.Fun) []*stg.Symbol {
+	var params []*stg.Symbol
+	for _, s := range fun.Body.Scope.Symbols {
+		if s.Kind == smk.Receiver {
+			params = append(params, s)
+		}
+	}
+	for _, s := range fun.Body.Scope.Symbols {
+		if s.Kind == smk.Param {
+			params = append(params, s)
+		}
+	}
+	return params
+}
+
+// Reports whether values of a given type can be held in a register.
+func isScalar(t *stg.Type) bool {
+	t = baseType(t)
+	switch t.Kind {
+	case tpk.Integer, tpk.Boolean, tpk.Rune:
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns underlying type of custom type.
+func baseType(t *stg.Type) *stg.Type {
+	for t.Kind == tpk.Custom {
+		t = t.Def.(*stg.Custom).Type
+	}
+	return t
+}
+
+func unsupportedType(t *stg.Type, span sm.Span) diag.Error {
+	return diag.Unsupported(fmt.Sprintf("value of %s type in vm backend", t.Kind), span)
+}
+
+func symSpan(s *stg.Symbol) sm.Span {
+	return sm.Span{Pin: s.Pin, Len: uint32(len(s.Name))}
+}
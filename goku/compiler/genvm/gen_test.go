@@ -0,0 +1,84 @@
+package genvm_test
+
+import (
+	"testing"
+
+	"github.com/mebyus/ku/goku/compiler/builder"
+	"github.com/mebyus/ku/goku/compiler/genvm"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/vm"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		unit   string
+		status uint64
+
+		// program must exit abnormally with trap
+		trap bool
+	}{
+		{
+			name:   "1 calls, loops and match",
+			unit:   "00001",
+			status: 425,
+		},
+		{
+			name:   "2 break, gonext and short circuit",
+			unit:   "00002",
+			status: 147,
+		},
+		{
+			name:   "3 integer wrapping and signed comparison",
+			unit:   "00003",
+			status: 111,
+		},
+		{
+			name: "4 failed must",
+			unit: "00004",
+			trap: true,
+		},
+		{
+			name:   "5 signed division, remainder and shift",
+			unit:   "00005",
+			status: 63,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := builder.Walk(builder.WalkConfig{
+				Dir: builder.BaseDirs{Loc: "testdata"},
+			}, builder.QueueItem{Path: sm.Local(tt.unit)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = builder.CompileBundle(bundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := genvm.Generate(&genvm.Program{
+				Units: bundle.Order,
+				Main:  bundle.Main.Scope.Get("main"),
+			})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			var m vm.Machine
+			exit := m.Exec(p)
+			if tt.trap {
+				if exit.Error == nil || exit.Error.Code != vm.ErrorTrap {
+					t.Errorf("exit.Error = %v, want trap", exit.Error)
+				}
+				return
+			}
+			if exit.Error != nil {
+				t.Fatalf("exit.Error = %v", exit.Error.Code)
+			}
+			if exit.Status != tt.status {
+				t.Errorf("exit.Status = %d, want %d", exit.Status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,464 @@
+package genvm
+
+import (
+	"fmt"
+
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/enums/smk"
+	"github.com/mebyus/ku/goku/compiler/typer/stg"
+	"github.com/mebyus/ku/goku/vm/ssa"
+)
+
+// Mutable local variables are tracked as a list of current values. Blocks
+// which may be reached from several places (joins after branches, loop
+// heads and exits) receive values of all visible variables via block
+// parameters. Unused parameters are cheap compared to liveness analysis
+// at this point.
+
+// loop describes control flow targets of a loop which encloses
+// current position.
+type loop struct {
+	// Gonext target.
+	next *ssa.Block
+
+	// Break target.
+	exit *ssa.Block
+}
+
+// define adds local variable with a given initial value.
+func (g *Gen) define(s *stg.Symbol, v *ssa.Value) {
+	g.vars = append(g.vars, s)
+	g.vals[s] = v
+}
+
+// join creates block which receives first n visible variables
+// as its parameters.
+func (g *Gen) join(n int) *ssa.Block {
+	b := g.f.NewBlock()
+	for range n {
+		b.Param()
+	}
+	return b
+}
+
+// args returns current values of first n visible variables.
+func (g *Gen) args(n int) []*ssa.Value {
+	args := make([]*ssa.Value, n)
+	for i, s := range g.vars[:n] {
+		args[i] = g.vals[s]
+	}
+	return args
+}
+
+// restore sets values of first n visible variables.
+func (g *Gen) restore(vals []*ssa.Value) {
+	for i, v := range vals {
+		g.vals[g.vars[i]] = v
+	}
+}
+
+// jump terminates current block with a jump to the block created by join.
+func (g *Gen) jump(b *ssa.Block) {
+	g.b.Jump(b, g.args(len(b.Params))...)
+	g.b = nil
+}
+
+// enter continues generation in the block created by join. Current position
+// becomes unreachable if block has no incoming edges.
+func (g *Gen) enter(b *ssa.Block) {
+	if len(b.Preds) == 0 {
+		g.b = nil
+		return
+	}
+	g.b = b
+	g.restore(b.Params)
+}
+
+func (g *Gen) block(block *stg.Block) diag.Error {
+	// variables defined inside block are not visible after it
+	n := len(g.vars)
+	defer func() { g.vars = g.vars[:n] }()
+
+	for _, s := range block.Nodes {
+		if g.b == nil {
+			// rest of the block is unreachable
+			return nil
+		}
+		err := g.node(s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Gen) node(stm stg.Statement) diag.Error {
+	switch s := stm.(type) {
+	case *stg.Block:
+		return g.block(s)
+	case *stg.Ret:
+		return g.ret(s)
+	case *stg.Var:
+		return g.localVar(s.Symbol, s.Exp)
+	case *stg.Assign:
+		return g.assign(s)
+	case *stg.OpAssign:
+		return g.opAssign(s)
+	case *stg.Invoke:
+		return g.invoke(s)
+	case *stg.If:
+		return g.ifStm(s)
+	case *stg.While:
+		return g.while(s)
+	case *stg.Loop:
+		return g.loop(s)
+	case *stg.ForRange:
+		return g.forRange(s)
+	case *stg.MatchInteger:
+		return g.match(s)
+	case *stg.Break:
+		g.jump(g.loops[len(g.loops)-1].exit)
+		return nil
+	case *stg.Gonext:
+		g.jump(g.loops[len(g.loops)-1].next)
+		return nil
+	case *stg.Must:
+		return g.must(s)
+	case *stg.Panic, *stg.Stub, *stg.Never:
+		g.b.Trap()
+		g.b = nil
+		return nil
+	case *stg.DeferCall:
+		return diag.Unsupported("defer in vm backend", s.Call.Span())
+	default:
+		panic(fmt.Sprintf("unexpected (%T) statement", s))
+	}
+}
+
+func (g *Gen) ret(r *stg.Ret) diag.Error {
+	if r.Exp == nil {
+		g.b.Return(nil)
+		g.b = nil
+		return nil
+	}
+
+	v, err := g.exp(r.Exp)
+	if err != nil {
+		return err
+	}
+	g.b.Return(v)
+	g.b = nil
+	return nil
+}
+
+func (g *Gen) localVar(s *stg.Symbol, exp stg.Exp) diag.Error {
+	if !isScalar(s.Type) {
+		return unsupportedType(s.Type, symSpan(s))
+	}
+
+	if exp == nil {
+		g.define(s, g.b.Const(0))
+		return nil
+	}
+
+	v, err := g.exp(exp)
+	if err != nil {
+		return err
+	}
+	g.define(s, v)
+	return nil
+}
+
+// Returns local variable symbol of assign target.
+func (g *Gen) target(exp stg.Exp) (*stg.Symbol, diag.Error) {
+	t, ok := exp.(*stg.SymExp)
+	if !ok || t.Symbol.Kind != smk.Var || !t.Symbol.IsLocal() {
+		return nil, diag.Unsupported("assignment to this target in vm backend", exp.Span())
+	}
+	return t.Symbol, nil
+}
+
+func (g *Gen) assign(a *stg.Assign) diag.Error {
+	s, err := g.target(a.Target)
+	if err != nil {
+		return err
+	}
+
+	_, ok := g.vals[s]
+	if !ok {
+		// first assignment to implicitly defined variable
+		// is its declaration
+		return g.localVar(s, a.Exp)
+	}
+
+	v, err := g.exp(a.Exp)
+	if err != nil {
+		return err
+	}
+	g.vals[s] = v
+	return nil
+}
+
+func (g *Gen) opAssign(a *stg.OpAssign) diag.Error {
+	s, err := g.target(a.Target)
+	if err != nil {
+		return err
+	}
+
+	v, err := g.exp(a.Exp)
+	if err != nil {
+		return err
+	}
+	v, err = g.binOp(a.Op, s.Type, g.vals[s], v, a.Target.Span())
+	if err != nil {
+		return err
+	}
+	g.vals[s] = v
+	return nil
+}
+
+func (g *Gen) invoke(i *stg.Invoke) diag.Error {
+	_, err := g.exp(i.Call)
+	if err != nil {
+		return err
+	}
+
+	c, ok := i.Call.(*stg.Call)
+	if ok && c.Symbol.Def.(*stg.Fun).Never {
+		// called function never returns
+		g.b.Trap()
+		g.b = nil
+	}
+	return nil
+}
+
+func (g *Gen) must(m *stg.Must) diag.Error {
+	cond, err := g.exp(m.Exp)
+	if err != nil {
+		return err
+	}
+
+	ok := g.f.NewBlock()
+	fail := g.f.NewBlock()
+	g.b.Branch(cond, ssa.Edge{Block: ok}, ssa.Edge{Block: fail})
+	fail.Trap()
+	g.b = ok
+	return nil
+}
+
+// arm is a conditional branch of if or match statement.
+type arm struct {
+	// Produces branch condition. Returns nil condition if branch is
+	// always taken.
+	cond func() (*ssa.Value, diag.Error)
+
+	body *stg.Block
+}
+
+// Generates chain of conditional branches. First branch with true condition
+// is executed, else block is executed if none of the conditions hold.
+func (g *Gen) branches(arms []arm, els *stg.Block) diag.Error {
+	n := len(g.vars)
+	join := g.join(n)
+
+	for _, a := range arms {
+		cond, err := a.cond()
+		if err != nil {
+			return err
+		}
+		if cond == nil {
+			els = a.body
+			break
+		}
+
+		then := g.f.NewBlock()
+		next := g.f.NewBlock()
+		g.b.Branch(cond, ssa.Edge{Block: then}, ssa.Edge{Block: next})
+
+		vals := g.args(n)
+		g.b = then
+		err = g.block(a.body)
+		if err != nil {
+			return err
+		}
+		if g.b != nil {
+			g.jump(join)
+		}
+
+		g.restore(vals)
+		g.b = next
+	}
+
+	if els != nil {
+		err := g.block(els)
+		if err != nil {
+			return err
+		}
+	}
+	if g.b != nil {
+		g.jump(join)
+	}
+
+	g.enter(join)
+	return nil
+}
+
+func (g *Gen) ifStm(f *stg.If) diag.Error {
+	arms := make([]arm, 0, len(f.Branches))
+	for _, b := range f.Branches {
+		if b.IsStatic() && !b.IsTrue() {
+			continue
+		}
+		arms = append(arms, arm{
+			cond: func() (*ssa.Value, diag.Error) {
+				if b.IsStatic() {
+					return nil, nil
+				}
+				return g.exp(b.Exp)
+			},
+			body: &b.Block,
+		})
+	}
+	return g.branches(arms, f.Else)
+}
+
+func (g *Gen) match(m *stg.MatchInteger) diag.Error {
+	v, err := g.exp(m.Exp)
+	if err != nil {
+		return err
+	}
+
+	arms := make([]arm, 0, len(m.Cases))
+	for _, c := range m.Cases {
+		arms = append(arms, arm{
+			cond: func() (*ssa.Value, diag.Error) {
+				var cond *ssa.Value
+				for _, exp := range c.List {
+					x, err := g.exp(exp)
+					if err != nil {
+						return nil, err
+					}
+					eq := g.b.Bin(ssa.OpEq, v, x)
+					if cond == nil {
+						cond = eq
+					} else {
+						cond = g.b.Bin(ssa.OpOr, cond, eq)
+					}
+				}
+				return cond, nil
+			},
+			body: &c.Body,
+		})
+	}
+	return g.branches(arms, m.Else)
+}
+
+// Generates loop body. Loop head is entered and loop targets are pushed
+// to the stack beforehand.
+func (g *Gen) loopBody(body *stg.Block, l loop) diag.Error {
+	g.loops = append(g.loops, l)
+	err := g.block(body)
+	g.loops = g.loops[:len(g.loops)-1]
+	if err != nil {
+		return err
+	}
+	if g.b != nil {
+		g.jump(l.next)
+	}
+	return nil
+}
+
+func (g *Gen) while(w *stg.While) diag.Error {
+	n := len(g.vars)
+	head := g.join(n)
+	exit := g.join(n)
+	g.jump(head)
+
+	g.enter(head)
+	cond, err := g.exp(w.Exp)
+	if err != nil {
+		return err
+	}
+	body := g.f.NewBlock()
+	g.b.Branch(cond, ssa.Edge{Block: body}, ssa.Edge{Block: exit, Args: g.args(n)})
+
+	g.b = body
+	err = g.loopBody(&w.Body, loop{next: head, exit: exit})
+	if err != nil {
+		return err
+	}
+
+	g.enter(exit)
+	return nil
+}
+
+func (g *Gen) loop(l *stg.Loop) diag.Error {
+	n := len(g.vars)
+	head := g.join(n)
+	exit := g.join(n)
+	g.jump(head)
+
+	g.enter(head)
+	err := g.loopBody(&l.Body, loop{next: head, exit: exit})
+	if err != nil {
+		return err
+	}
+
+	g.enter(exit)
+	return nil
+}
+
+func (g *Gen) forRange(r *stg.ForRange) diag.Error {
+	if !isScalar(r.Var.Type) {
+		return unsupportedType(r.Var.Type, symSpan(r.Var))
+	}
+
+	start := g.b.Const(0)
+	if r.Start != nil {
+		v, err := g.exp(r.Start)
+		if err != nil {
+			return err
+		}
+		start = g.wrap(r.Var.Type, v)
+	}
+
+	// loop variable is visible only inside the loop
+	defer func(n int) { g.vars = g.vars[:n] }(len(g.vars))
+	g.define(r.Var, start)
+
+	n := len(g.vars)
+	head := g.join(n)
+	next := g.join(n)
+	exit := g.join(n)
+	g.jump(head)
+
+	// loop end is evaluated before each iteration
+	g.enter(head)
+	end, err := g.exp(r.End)
+	if err != nil {
+		return err
+	}
+	op := ssa.OpULt
+	if baseType(r.Var.Type).IsSigned() {
+		op = ssa.OpLt
+	}
+	cond := g.b.Bin(op, g.vals[r.Var], g.wrap(r.Var.Type, end))
+	body := g.f.NewBlock()
+	g.b.Branch(cond, ssa.Edge{Block: body}, ssa.Edge{Block: exit, Args: g.args(n)})
+
+	g.b = body
+	err = g.loopBody(&r.Body, loop{next: next, exit: exit})
+	if err != nil {
+		return err
+	}
+
+	g.enter(next)
+	if g.b != nil {
+		one := g.b.Const(1)
+		g.vals[r.Var] = g.wrap(r.Var.Type, g.b.Bin(ssa.OpAdd, g.vals[r.Var], one))
+		g.jump(head)
+	}
+
+	g.enter(exit)
+	return nil
+}
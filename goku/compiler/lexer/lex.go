@@ -293,6 +293,7 @@ func (lx *Lexer) cstring() token.Token {
 
 	if tok.Kind == token.String {
 		tok.Kind = token.CString
+		tok.Val = uint64(lx.Pin() - pin)
 		return tok
 	}
 	return tok
@@ -314,6 +315,7 @@ func (lx *Lexer) str() token.Token {
 		// common case of empty string literal
 		lx.Advance() // skip quote
 		tok.Kind = token.String
+		tok.Val = 2
 		return tok
 	}
 
@@ -377,6 +379,7 @@ func (lx *Lexer) str() token.Token {
 
 	tok.Kind = token.String
 	tok.Data = data
+	tok.Val = uint64(lx.Pin() - tok.Pin)
 	return tok
 }
 
@@ -73,3 +73,28 @@ func TestLex(t *testing.T) {
 		})
 	}
 }
+
+func TestLexStringLength(t *testing.T) {
+	tests := []struct {
+		src  string
+		kind token.Kind
+		len  uint64
+	}{
+		{src: `""`, kind: token.String, len: 2},
+		{src: `"abc"`, kind: token.String, len: 5},
+		{src: `"a\nb\"c"`, kind: token.String, len: 9},
+		{src: `c""`, kind: token.CString, len: 3},
+		{src: `c"a\tb"`, kind: token.CString, len: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			tok := FromBytes([]byte(tt.src)).Lex()
+			if tok.Kind != tt.kind {
+				t.Fatalf("Lex() kind = %s, want %s", tok.Kind, tt.kind)
+			}
+			if tok.Val != tt.len {
+				t.Errorf("Lex() val = %d, want %d", tok.Val, tt.len)
+			}
+		})
+	}
+}
@@ -55,6 +55,7 @@ func (p *Parser) Operand() (ast.Operand, diag.Error) {
 		return ast.String{
 			Val: tok.Data,
 			Pin: tok.Pin,
+			Len: uint32(tok.Val),
 		}, nil
 	case token.Rune:
 		tok := p.peek
@@ -6,15 +6,16 @@ import (
 	"github.com/mebyus/ku/goku/compiler/token"
 )
 
-func (p *Parser) parse() diag.Error {
-	for {
+func (p *Parser) parse() {
+	for !p.stop {
 		if p.peek.Kind == token.EOF {
-			return nil
+			return
 		}
 
+		pin := p.peek.Pin
 		err := p.top()
 		if err != nil {
-			return err
+			p.syncTop(err, pin)
 		}
 	}
 }
@@ -71,3 +71,57 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRecover(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+
+		// number of reported errors
+		errors int
+	}{
+		{
+			name:   "1 no errors",
+			src:    "fun foo() {\n\tret;\n}\n",
+			errors: 0,
+		},
+		{
+			name:   "2 bad statements in one function",
+			src:    "fun foo() {\n\t) 1;\n\tvar a: u8 = 1;\n\t] 2;\n}\n",
+			errors: 2,
+		},
+		{
+			name:   "3 bad top-level nodes",
+			src:    "fun 1() {}\nfun bar() {}\ntype 2 u8\ntype A u8\n",
+			errors: 2,
+		},
+		{
+			name:   "4 unclosed function body",
+			src:    "fun foo() {\n\tvar a: u8 = 1;\nfun bar() {\n\t) 1;\n}\n",
+			errors: 2,
+		},
+		{
+			name:   "5 errors limit",
+			src:    strings.Repeat("fun foo() {\n\t) 1;\n}\n", 2*maxErrors),
+			errors: maxErrors,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStream(lexer.FromBytes([]byte(tt.src)))
+
+			var report diag.Report
+			report.Add(err)
+			if report.Len() != tt.errors {
+				t.Errorf("ParseStream() errors = %d, want %d: %v", report.Len(), tt.errors, err)
+				return
+			}
+
+			for i := 1; i < report.Len(); i += 1 {
+				if diag.PinOf(report.Errors[i-1]) > diag.PinOf(report.Errors[i]) {
+					t.Errorf("ParseStream() errors are not sorted by position: %v", err)
+				}
+			}
+		})
+	}
+}
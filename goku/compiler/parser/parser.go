@@ -20,10 +20,20 @@ type Parser struct {
 	next token.Token
 
 	props []ast.Prop
+
+	// errors reported during parsing
+	errors diag.Report
+
+	// true if parsing must be stopped due to errors limit
+	stop bool
 }
 
+// Nodes parses top-level nodes of the text. Parser recovers from
+// syntax errors and continues with the next statement or top-level node,
+// thus returned error may be a report with several errors.
 func (p *Parser) Nodes() (*ast.Text, diag.Error) {
-	err := p.parse()
+	p.parse()
+	err := p.errors.Err()
 	if err != nil {
 		return nil, err
 	}
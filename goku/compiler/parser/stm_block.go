@@ -75,9 +75,14 @@ func (p *Parser) Block() (ast.Block, diag.Error) {
 			}, nil
 		}
 
+		start := p.peek.Pin
 		s, err := p.Statement()
 		if err != nil {
-			return ast.Block{}, err
+			err = p.recoverNode(err, start)
+			if err != nil {
+				return ast.Block{}, err
+			}
+			continue
 		}
 		nodes = append(nodes, s)
 	}
@@ -100,9 +105,14 @@ func (p *Parser) Static() (ast.Static, diag.Error) {
 			}, nil
 		}
 
+		start := p.peek.Pin
 		s, err := p.Statement()
 		if err != nil {
-			return ast.Static{}, err
+			err = p.recoverNode(err, start)
+			if err != nil {
+				return ast.Static{}, err
+			}
+			continue
 		}
 		nodes = append(nodes, s)
 	}
@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"io"
+
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/token"
+)
+
+// Maximum number of errors reported by parser for a single text.
+// Parsing stops when this limit is reached.
+const maxErrors = 16
+
+// syncTopError is a signal (not an actual error) which is propagated
+// up the call chain after the parser failed to recover inside a block.
+// Error which caused it is already reported. Upon receiving this signal
+// parser continues from the next top-level node.
+type syncTopError struct{}
+
+var _ diag.Error = syncTopError{}
+
+var errSyncTop diag.Error = syncTopError{}
+
+func (syncTopError) Error() string {
+	return "sync to next top-level node"
+}
+
+func (syncTopError) Render(w io.Writer, m sm.PinMap) error {
+	_, err := io.WriteString(w, "sync to next top-level node")
+	return err
+}
+
+func (syncTopError) SetFallbackSpan(span sm.Span) {}
+
+// report adds error to the list of parser errors. Stops parsing
+// if errors limit is reached.
+func (p *Parser) report(err diag.Error) {
+	p.errors.Add(err)
+	if p.errors.Len() >= maxErrors {
+		p.stop = true
+	}
+}
+
+// recoverNode reports statement parsing error and skips tokens until
+// start of the next statement inside current block. Pin marks position
+// where parsing of failed statement started. Returns nil if parser can
+// proceed with the next statement. Otherwise returns sync signal which
+// must be propagated to top-level.
+func (p *Parser) recoverNode(err diag.Error, pin sm.Pin) diag.Error {
+	if err == errSyncTop {
+		return err
+	}
+
+	p.report(err)
+	if p.stop {
+		return errSyncTop
+	}
+
+	if p.peek.Pin == pin && p.peek.Kind != token.EOF && p.peek.Kind != token.RightCurly &&
+		!isTopSync(p.peek.Kind, p.next.Kind) {
+		// parser made no progress, skip offending token
+		// to avoid looping on it forever
+		p.advance()
+	}
+
+	// tracks nesting of curly braces skipped during sync,
+	// inner blocks are skipped as a whole
+	depth := 0
+	for {
+		if depth == 0 && isTopSync(p.peek.Kind, p.next.Kind) {
+			return errSyncTop
+		}
+
+		switch p.peek.Kind {
+		case token.EOF:
+			return errSyncTop
+		case token.Semicolon:
+			p.advance() // skip ";"
+			if depth == 0 {
+				// assume it's the end of malformed statement
+				return nil
+			}
+		case token.LeftCurly, token.HashCurly:
+			depth += 1
+			p.advance()
+		case token.RightCurly:
+			if depth == 0 {
+				// assume it closes current block
+				return nil
+			}
+			depth -= 1
+			p.advance() // skip "}"
+			if depth == 0 {
+				// assume it ends malformed statement with block
+				return nil
+			}
+		default:
+			if depth == 0 && isNodeStart(p.peek.Kind) {
+				return nil
+			}
+			p.advance()
+		}
+	}
+}
+
+// syncTop reports top-level node parsing error and skips tokens until
+// start of the next top-level node. Pin marks position where parsing
+// of failed node started.
+func (p *Parser) syncTop(err diag.Error, pin sm.Pin) {
+	p.props = p.props[:0]
+	if err != errSyncTop {
+		p.report(err)
+	}
+
+	if p.peek.Pin == pin && p.peek.Kind != token.EOF {
+		// parser made no progress, skip offending token
+		// to avoid looping on it forever
+		p.advance()
+	}
+	for !isTopSync(p.peek.Kind, p.next.Kind) && p.peek.Kind != token.EOF {
+		p.advance()
+	}
+}
+
+// isTopSync reports whether token (followed by the next one) is likely
+// to start a new top-level node.
+func isTopSync(k, next token.Kind) bool {
+	switch k {
+	case token.Type, token.Pub, token.Export, token.Gen, token.Bag, token.HashSquare:
+		return true
+	case token.Fun:
+		// method and function type specifier cannot be told apart
+		// at this point, since both are followed by "(",
+		// thus only functions are recognized here
+		return next == token.Word
+	default:
+		return false
+	}
+}
+
+// isNodeStart reports whether token always starts a new statement.
+func isNodeStart(k token.Kind) bool {
+	switch k {
+	case token.Const, token.Var, token.Let, token.If, token.Ret, token.For, token.Defer,
+		token.Goto, token.Gonext, token.Break, token.Never, token.Panic, token.Must:
+		return true
+	default:
+		return false
+	}
+}
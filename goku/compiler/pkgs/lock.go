@@ -0,0 +1,158 @@
+package pkgs
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prefix of content hash in lock file. Denotes hashing algorithm.
+const hashPrefix = "sha256:"
+
+// Lock maps package name to its content hash.
+type Lock map[string]string
+
+// LoadLock loads lock file from a given project root directory.
+// Returns empty lock if project has no lock file.
+func LoadLock(root string) (Lock, error) {
+	path := filepath.Join(root, LockFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Lock{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseLock(path, data)
+}
+
+// ParseLock parses lock file text. Path is used only for error messages.
+//
+// Each non-empty line (except comments) has the form:
+//
+//	<name> sha256:<hex>
+func ParseLock(path string, data []byte) (Lock, error) {
+	lock := make(Lock)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	n := 0
+	for sc.Scan() {
+		n += 1
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], hashPrefix) {
+			return nil, fmt.Errorf("%s:%d malformed lock entry", path, n)
+		}
+		name := fields[0]
+		_, ok := lock[name]
+		if ok {
+			return nil, fmt.Errorf("%s:%d multiple entries of package \"%s\"", path, n, name)
+		}
+		lock[name] = fields[1]
+	}
+	err := sc.Err()
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Encode writes lock file text into w. Entries are sorted by package name.
+func (l Lock) Encode(w io.Writer) error {
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by ku lock. DO NOT EDIT.\n\n")
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(' ')
+		b.WriteString(l[name])
+		b.WriteByte('\n')
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// WriteLock computes content hashes of all packages listed in manifest of a given
+// project and writes them into project lock file.
+func WriteLock(root string) error {
+	m, err := LoadManifest(root)
+	if err != nil {
+		return err
+	}
+
+	lock := make(Lock, len(m.Packages))
+	for _, p := range m.Packages {
+		hash, err := Hash(p.Dir)
+		if err != nil {
+			return fmt.Errorf("package \"%s\": %w", p.Name, err)
+		}
+		lock[p.Name] = hash
+	}
+
+	var b bytes.Buffer
+	err = lock.Encode(&b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, LockFile), b.Bytes(), 0o644)
+}
+
+// Hash computes content hash of all regular files inside a given directory
+// (including nested directories). Hash depends on file contents and their
+// paths relative to the directory.
+func Hash(dir string) (string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return "", err
+		}
+
+		// path and content length are included to separate
+		// files from each other
+		h.Write([]byte(filepath.ToSlash(rel)))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.Itoa(len(data))))
+		h.Write([]byte{0})
+		h.Write(data)
+	}
+	return hashPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
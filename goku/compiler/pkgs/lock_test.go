@@ -0,0 +1,213 @@
+package pkgs
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLock(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Lock
+		wantErr bool
+	}{
+		{
+			name: "1 empty",
+			text: "",
+			want: Lock{},
+		},
+		{
+			name: "2 entries and comments",
+			text: `
+// comment
+json sha256:aa
+
+http   sha256:bb
+`,
+			want: Lock{
+				"json": "sha256:aa",
+				"http": "sha256:bb",
+			},
+		},
+		{
+			name:    "3 missing hash",
+			text:    "json",
+			wantErr: true,
+		},
+		{
+			name:    "4 unknown hash prefix",
+			text:    "json md5:aa",
+			wantErr: true,
+		},
+		{
+			name:    "5 duplicate entry",
+			text:    "json sha256:aa\njson sha256:bb",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLock(LockFile, []byte(tt.text))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseLock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("ParseLock() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockEncode(t *testing.T) {
+	lock := Lock{
+		"yaml": "sha256:cc",
+		"http": "sha256:bb",
+		"json": "sha256:aa",
+	}
+
+	var b bytes.Buffer
+	err := lock.Encode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := b.String()
+	if strings.Index(text, "http") > strings.Index(text, "json") || strings.Index(text, "json") > strings.Index(text, "yaml") {
+		t.Errorf("Encode() entries are not sorted:\n%s", text)
+	}
+
+	got, err := ParseLock(LockFile, b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got, lock) {
+		t.Errorf("ParseLock(Encode()) = %v, want %v", got, lock)
+	}
+}
+
+// writeFiles creates files with given contents inside directory.
+// Keys are slash-separated file paths relative to directory.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(content), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	hash := func(files map[string]string) string {
+		dir := t.TempDir()
+		writeFiles(t, dir, files)
+		h, err := Hash(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	base := hash(map[string]string{"a.ku": "abc", "dec/b.ku": "def"})
+	if !strings.HasPrefix(base, hashPrefix) {
+		t.Errorf("Hash() = %s, want %s prefix", base, hashPrefix)
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		same  bool
+	}{
+		{
+			name:  "1 same content",
+			files: map[string]string{"a.ku": "abc", "dec/b.ku": "def"},
+			same:  true,
+		},
+		{
+			name:  "2 changed content",
+			files: map[string]string{"a.ku": "abc", "dec/b.ku": "deg"},
+		},
+		{
+			name:  "3 renamed file",
+			files: map[string]string{"a.ku": "abc", "dec/c.ku": "def"},
+		},
+		{
+			name:  "4 moved file",
+			files: map[string]string{"a.ku": "abc", "b.ku": "def"},
+		},
+		{
+			name:  "5 content moved between files",
+			files: map[string]string{"a.ku": "abcd", "dec/b.ku": "ef"},
+		},
+		{
+			name:  "6 added file",
+			files: map[string]string{"a.ku": "abc", "dec/b.ku": "def", "c.ku": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hash(tt.files)
+			if (got == base) != tt.same {
+				t.Errorf("Hash() = %s, base = %s, want same %v", got, base, tt.same)
+			}
+		})
+	}
+}
+
+func TestWriteLock(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		ManifestFile:            `pkg json -> "libs/json"; pkg http -> vendor;`,
+		"libs/json/decode/d.ku": "fun decode() {}",
+		"vendor/http/h.ku":      "fun get() {}",
+	})
+
+	err := WriteLock(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := LoadLock(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, dir := range map[string]string{
+		"json": filepath.Join(root, "libs/json"),
+		"http": filepath.Join(root, VendorDir, "http"),
+	} {
+		want, err := Hash(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lock[name] != want {
+			t.Errorf("lock entry of package \"%s\" = %s, want %s", name, lock[name], want)
+		}
+	}
+	if len(lock) != 2 {
+		t.Errorf("lock has %d entries, want 2", len(lock))
+	}
+}
+
+func TestLoadLockMissing(t *testing.T) {
+	lock, err := LoadLock(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lock) != 0 {
+		t.Errorf("LoadLock() = %v, want empty lock", lock)
+	}
+}
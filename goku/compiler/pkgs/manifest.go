@@ -0,0 +1,195 @@
+// Package pkgs implements project package manifest and lock file, which
+// together describe where units imported with "pkg" origin are located.
+//
+// Manifest is stored in "pkg.ku" file in project root directory. It lists
+// packages available to project units:
+//
+//	// package with units stored in local directory
+//	pkg json -> "../libs/json";
+//
+//	// package with units copied into "vendor/http" directory
+//	pkg http -> vendor;
+//
+// Unit path inside package is resolved relative to package directory:
+//
+//	import pkg {
+//		decode -> "json/decode" // resolved to "../libs/json/decode"
+//	}
+//
+// Lock file "pkg.lock" stores content hashes of all listed packages.
+// Packages with missing or mismatched hash cannot be imported.
+package pkgs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mebyus/ku/goku/compiler/lexer"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/compiler/token"
+)
+
+const (
+	// Name of package manifest file in project root directory.
+	ManifestFile = "pkg.ku"
+
+	// Name of lock file in project root directory.
+	LockFile = "pkg.lock"
+
+	// Name of directory (inside project root) with vendored packages.
+	VendorDir = "vendor"
+)
+
+// Package describes a single package listed in manifest.
+type Package struct {
+	// Package name. Always the first segment of import string.
+	Name string
+
+	// System path to directory with package units.
+	Dir string
+
+	// True if package is stored in vendor directory.
+	Vendor bool
+}
+
+// Manifest describes packages available to project units.
+type Manifest struct {
+	// Packages sorted by name.
+	Packages []*Package
+
+	m map[string]*Package
+}
+
+// Get returns package with a given name. Returns nil if manifest does not
+// list such package.
+func (m *Manifest) Get(name string) *Package {
+	return m.m[name]
+}
+
+// LoadManifest loads package manifest from a given project root directory.
+// Returns empty manifest if project has no manifest file.
+func LoadManifest(root string) (*Manifest, error) {
+	path := filepath.Join(root, ManifestFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{m: make(map[string]*Package)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseManifest(root, path, data)
+}
+
+// ParseManifest parses manifest text. Path is used only for error messages.
+// Local package directories are resolved relative to project root.
+func ParseManifest(root, path string, data []byte) (*Manifest, error) {
+	p := manifestParser{
+		lx:   lexer.FromBytes(data),
+		path: path,
+		data: data,
+		root: root,
+		m:    &Manifest{m: make(map[string]*Package)},
+	}
+	p.advance()
+
+	for p.tok.Kind != token.EOF {
+		err := p.entry()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	list := p.m.Packages
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return p.m, nil
+}
+
+type manifestParser struct {
+	tok token.Token
+
+	lx *lexer.Lexer
+
+	m *Manifest
+
+	// Manifest file path.
+	path string
+
+	// Manifest text.
+	data []byte
+
+	// Project root directory.
+	root string
+}
+
+func (p *manifestParser) advance() {
+	p.tok = p.lx.Lex()
+}
+
+// parses manifest entry:
+//
+//	pkg <name> -> "<path>";
+//	pkg <name> -> vendor;
+func (p *manifestParser) entry() error {
+	if p.tok.Kind != token.Word || p.tok.Data != "pkg" {
+		return p.unexpected()
+	}
+	p.advance() // skip "pkg"
+
+	if p.tok.Kind != token.Word {
+		return p.unexpected()
+	}
+	name := p.tok.Data
+	pin := p.tok.Pin
+	p.advance() // skip name
+
+	if p.tok.Kind != token.RightArrow {
+		return p.unexpected()
+	}
+	p.advance() // skip "->"
+
+	pkg := &Package{Name: name}
+	switch {
+	case p.tok.Kind == token.String:
+		if p.tok.Data == "" {
+			return p.errorf(p.tok.Pin, "empty path of package \"%s\"", name)
+		}
+		pkg.Dir = filepath.Join(p.root, filepath.FromSlash(p.tok.Data))
+	case p.tok.Kind == token.Word && p.tok.Data == "vendor":
+		pkg.Vendor = true
+		pkg.Dir = filepath.Join(p.root, VendorDir, name)
+	default:
+		return p.unexpected()
+	}
+	p.advance() // skip path or "vendor"
+
+	if p.tok.Kind != token.Semicolon {
+		return p.unexpected()
+	}
+	p.advance() // skip ";"
+
+	_, ok := p.m.m[name]
+	if ok {
+		return p.errorf(pin, "multiple entries of package \"%s\"", name)
+	}
+	p.m.m[name] = pkg
+	p.m.Packages = append(p.m.Packages, pkg)
+	return nil
+}
+
+func (p *manifestParser) unexpected() error {
+	if p.tok.Kind == token.EOF {
+		return p.errorf(p.tok.Pin, "unexpected end of file")
+	}
+	return p.errorf(p.tok.Pin, "unexpected token \"%s\"", p.tok.String())
+}
+
+func (p *manifestParser) errorf(pin sm.Pin, format string, args ...any) error {
+	pos := sm.FindTextPos(p.data, pin.Pos().Offset)
+	return fmt.Errorf("%s:%s %s", p.path, pos, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,76 @@
+package pkgs
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []Package
+		wantErr bool
+	}{
+		{
+			name: "1 empty",
+			text: "",
+			want: nil,
+		},
+		{
+			name: "2 local path",
+			text: `pkg json -> "../libs/json";`,
+			want: []Package{
+				{Name: "json", Dir: filepath.Join("root", "../libs/json")},
+			},
+		},
+		{
+			name: "3 vendor and sorting",
+			text: `
+// comment
+pkg yaml -> vendor;
+pkg http -> "deps/http";
+`,
+			want: []Package{
+				{Name: "http", Dir: filepath.Join("root", "deps/http")},
+				{Name: "yaml", Dir: filepath.Join("root", VendorDir, "yaml"), Vendor: true},
+			},
+		},
+		{
+			name:    "4 duplicate entry",
+			text:    `pkg a -> vendor; pkg a -> "a";`,
+			wantErr: true,
+		},
+		{
+			name:    "5 missing semicolon",
+			text:    `pkg a -> vendor`,
+			wantErr: true,
+		},
+		{
+			name:    "6 empty path",
+			text:    `pkg a -> "";`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseManifest("root", ManifestFile, []byte(tt.text))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseManifest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Packages) != len(tt.want) {
+				t.Errorf("ParseManifest() got %d package(s), want %d", len(got.Packages), len(tt.want))
+				return
+			}
+			for i, p := range got.Packages {
+				if *p != tt.want[i] {
+					t.Errorf("ParseManifest() package %d = %+v, want %+v", i, *p, tt.want[i])
+				}
+			}
+		})
+	}
+}
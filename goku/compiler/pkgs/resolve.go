@@ -0,0 +1,109 @@
+package pkgs
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Resolver translates import strings of units with "pkg" origin into
+// system paths of unit directories.
+//
+// Package contents are checked against lock file upon first import.
+type Resolver struct {
+	manifest *Manifest
+
+	lock Lock
+
+	// Result of package content check. Maps package name to check error
+	// (nil if package passed the check).
+	checked map[string]error
+}
+
+// NewResolver creates resolver for a given project root directory.
+func NewResolver(root string) (*Resolver, error) {
+	m, err := LoadManifest(root)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := LoadLock(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Resolver{
+		manifest: m,
+		lock:     lock,
+		checked:  make(map[string]error),
+	}, nil
+}
+
+// Resolve returns system path to directory which contains source files
+// of unit with a given import string.
+func (r *Resolver) Resolve(s string) (string, error) {
+	name, rest, nested := strings.Cut(s, "/")
+	p := r.manifest.Get(name)
+	if p == nil {
+		return "", fmt.Errorf("package \"%s\" is not listed in %s", name, ManifestFile)
+	}
+
+	if nested {
+		err := checkUnitPath(rest)
+		if err != nil {
+			return "", fmt.Errorf("import \"%s\": %w", s, err)
+		}
+	}
+
+	err := r.check(p)
+	if err != nil {
+		return "", err
+	}
+
+	if rest == "" {
+		return p.Dir, nil
+	}
+	return filepath.Join(p.Dir, filepath.FromSlash(rest)), nil
+}
+
+// checkUnitPath checks that unit path inside package stays within package
+// directory. Only plain names separated by "/" are allowed, since content
+// hash does not cover files outside of package directory.
+func checkUnitPath(s string) error {
+	for _, part := range strings.Split(s, "/") {
+		switch part {
+		case "":
+			return errors.New("empty element in unit path")
+		case ".", "..":
+			return fmt.Errorf("unit path must not contain \"%s\" elements", part)
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) check(p *Package) error {
+	err, ok := r.checked[p.Name]
+	if ok {
+		return err
+	}
+
+	err = r.checkHash(p)
+	r.checked[p.Name] = err
+	return err
+}
+
+func (r *Resolver) checkHash(p *Package) error {
+	want, ok := r.lock[p.Name]
+	if !ok {
+		return fmt.Errorf("package \"%s\" is missing from %s, run \"ku lock\" to update it", p.Name, LockFile)
+	}
+
+	hash, err := Hash(p.Dir)
+	if err != nil {
+		return fmt.Errorf("package \"%s\": %w", p.Name, err)
+	}
+	if hash != want {
+		return fmt.Errorf("package \"%s\" content hash %s does not match %s entry %s", p.Name, hash, LockFile, want)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package pkgs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		ManifestFile:            `pkg json -> "libs/json"; pkg http -> vendor; pkg yaml -> "libs/yaml";`,
+		"libs/json/decode/d.ku": "fun decode() {}",
+		"libs/json/j.ku":        "fun json() {}",
+		"libs/secret/s.ku":      "fun secret() {}",
+		"vendor/http/h.ku":      "fun get() {}",
+		"libs/yaml/y.ku":        "fun yaml() {}",
+	})
+	err := WriteLock(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// modify package after lock was written
+	err = os.WriteFile(filepath.Join(root, "libs/yaml/y.ku"), []byte("fun yml() {}"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewResolver(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "1 package root",
+			path: "json",
+			want: filepath.Join(root, "libs/json"),
+		},
+		{
+			name: "2 nested unit",
+			path: "json/decode",
+			want: filepath.Join(root, "libs/json/decode"),
+		},
+		{
+			name: "3 vendor package",
+			path: "http",
+			want: filepath.Join(root, VendorDir, "http"),
+		},
+		{
+			name:    "4 unknown package",
+			path:    "xml/decode",
+			wantErr: true,
+		},
+		{
+			name:    "5 hash mismatch",
+			path:    "yaml",
+			wantErr: true,
+		},
+		{
+			name:    "6 escape package directory",
+			path:    "json/../secret",
+			wantErr: true,
+		},
+		{
+			name:    "7 escape package directory from nested unit",
+			path:    "json/decode/../../secret",
+			wantErr: true,
+		},
+		{
+			name:    "8 dot element",
+			path:    "json/./decode",
+			wantErr: true,
+		},
+		{
+			name:    "9 empty element",
+			path:    "json//decode",
+			wantErr: true,
+		},
+		{
+			name:    "10 trailing slash",
+			path:    "json/",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMissingLock(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		ManifestFile:     `pkg json -> "libs/json";`,
+		"libs/json/j.ku": "fun json() {}",
+	})
+
+	r, err := NewResolver(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Resolve("json")
+	if err == nil {
+		t.Error("Resolve() error = nil, want missing lock entry error")
+	}
+}
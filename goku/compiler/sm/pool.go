@@ -37,6 +37,15 @@ func (p *Pool) DecodePin(pin Pin) (FilePos, error) {
 	}, nil
 }
 
+// PinText implements TextMap interface.
+func (p *Pool) PinText(pin Pin) *Text {
+	id := pin.Pos().Text
+	if id == 0 {
+		return nil
+	}
+	return p.get(id)
+}
+
 // Load loads a file by given path and stores it into internal cache.
 // Returns Text created from loaded file.
 // If file was already loaded previously, then cached version is used.
@@ -13,6 +13,15 @@ type PinMap interface {
 	DecodePin(Pin) (FilePos, error)
 }
 
+// TextMap is a PinMap which also provides access to source texts.
+type TextMap interface {
+	PinMap
+
+	// PinText returns text which contains position specified by pin.
+	// Returns nil if text is not found.
+	PinText(Pin) *Text
+}
+
 type FilePos struct {
 	Path string
 	Pos  TextPos
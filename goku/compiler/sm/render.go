@@ -45,9 +45,49 @@ type WindowParams struct {
 //
 // Panics if offset is outside of text slice.
 func FindTargetLineWindow(text []byte, params WindowParams) TargetLineWindow {
-	targetLine := FindLineNumberAtOffset(text, params.Offset)
-	_ = targetLine
-	return TargetLineWindow{}
+	if params.Offset > uint32(len(text)) {
+		panic(fmt.Sprintf("offset (=%d) is outside of text (len=%d)", params.Offset, len(text)))
+	}
+
+	target := FindLineOffset(text, params.Offset)
+	line := FindLineNumberAtOffset(text, target)
+
+	// go back line by line until reaching max number of lines
+	// or text start
+	start := target
+	var before uint32
+	for before < params.MaxLinesBefore && start != 0 {
+		start = FindLineOffset(text, start-1)
+		before += 1
+	}
+
+	lines := make([][]byte, 0, before+1+params.MaxLinesAfter)
+	offset := start
+	for i := uint32(0); i <= before; i += 1 {
+		next := FindNextLineOffset(text, offset)
+		lines = append(lines, trimLineEnd(text[offset:next]))
+		offset = next
+	}
+
+	for i := uint32(0); i < params.MaxLinesAfter && offset < uint32(len(text)); i += 1 {
+		next := FindNextLineOffset(text, offset)
+		lines = append(lines, trimLineEnd(text[offset:next]))
+		offset = next
+	}
+
+	return TargetLineWindow{
+		Lines:  lines,
+		Target: before,
+		Start:  line - before,
+	}
+}
+
+// trimLineEnd removes newline character from the end of line.
+func trimLineEnd(line []byte) []byte {
+	if len(line) != 0 && line[len(line)-1] == '\n' {
+		return line[:len(line)-1]
+	}
+	return line
 }
 
 // FindLineNumberAtOffset returns zero-based line number of position in text.
@@ -121,10 +161,22 @@ func FindLineOffset(text []byte, offset uint32) uint32 {
 }
 
 // FindNextLineOffset returns offset of next line start after the given position.
+// Returns text length if position is on the last line.
 //
 // Panics if offset is outside of text.
 func FindNextLineOffset(text []byte, offset uint32) uint32 {
-	return 0
+	if offset > uint32(len(text)) {
+		panic(fmt.Sprintf("offset (=%d) is outside of text (len=%d)", offset, len(text)))
+	}
+
+	i := offset
+	for i < uint32(len(text)) {
+		if text[i] == '\n' {
+			return i + 1
+		}
+		i += 1
+	}
+	return i
 }
 
 // FindOffset tries to find byte offset of position (line, column) inside the text.
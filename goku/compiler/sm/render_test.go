@@ -2,6 +2,7 @@ package sm
 
 import (
 	"bytes"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -243,3 +244,63 @@ func TestFindLineOffset(t *testing.T) {
 		})
 	}
 }
+
+func TestFindTargetLineWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		params WindowParams
+		want   TargetLineWindow
+	}{
+		{
+			name:   "1 empty text",
+			text:   "",
+			params: WindowParams{Offset: 0, MaxLinesBefore: 2, MaxLinesAfter: 2},
+			want:   TargetLineWindow{Lines: [][]byte{{}}, Target: 0, Start: 0},
+		},
+		{
+			name:   "2 single line",
+			text:   "abc",
+			params: WindowParams{Offset: 1},
+			want:   TargetLineWindow{Lines: [][]byte{[]byte("abc")}, Target: 0, Start: 0},
+		},
+		{
+			name:   "3 lines around target",
+			text:   "a\nbb\nccc\ndd\ne\n",
+			params: WindowParams{Offset: 6, MaxLinesBefore: 1, MaxLinesAfter: 1},
+			want: TargetLineWindow{
+				Lines:  [][]byte{[]byte("bb"), []byte("ccc"), []byte("dd")},
+				Target: 1,
+				Start:  1,
+			},
+		},
+		{
+			name:   "4 window clipped by text boundaries",
+			text:   "a\nbb\nccc",
+			params: WindowParams{Offset: 2, MaxLinesBefore: 3, MaxLinesAfter: 3},
+			want: TargetLineWindow{
+				Lines:  [][]byte{[]byte("a"), []byte("bb"), []byte("ccc")},
+				Target: 1,
+				Start:  0,
+			},
+		},
+		{
+			name:   "5 target on newline character",
+			text:   "a\nbb\n",
+			params: WindowParams{Offset: 4, MaxLinesAfter: 1},
+			want: TargetLineWindow{
+				Lines:  [][]byte{[]byte("bb")},
+				Target: 0,
+				Start:  1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindTargetLineWindow([]byte(tt.text), tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindTargetLineWindow() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -172,12 +172,13 @@ func (k Kind) String() string {
 	return literal[k]
 }
 
-func (k Kind) hasStaticLiteral() bool {
+// HasStaticLiteral reports whether tokens of this kind always have the same literal.
+func (k Kind) HasStaticLiteral() bool {
 	return k < staticLiteralEnd
 }
 
 func (t Token) String() string {
-	if t.Kind.hasStaticLiteral() {
+	if t.Kind.HasStaticLiteral() {
 		return t.Kind.String()
 	}
 
@@ -249,7 +250,7 @@ func FormatTokenLine(m sm.PinMap, t Token) string {
 		return buf.String()
 	}
 
-	if !t.Kind.hasStaticLiteral() {
+	if !t.Kind.HasStaticLiteral() {
 		buf.WriteString(t.Kind.String())
 	}
 	for range 32 - buf.Len() {
@@ -18,6 +18,8 @@ type Token struct {
 	//
 	//	Integer:	parsed integer value (if it fits into 64 bits)
 	//	Rune:		integer value of code point
+	//	String:		length of literal in source text (including quotes)
+	//	CString:	length of literal in source text (including prefix and quotes)
 	//	EOF:		error code (can be 0, in case end of text was reached without error)
 	//	Illegal:	error code (always not 0)
 	Val uint64
@@ -11,6 +11,9 @@ import (
 	"github.com/mebyus/ku/goku/compiler/typer/stg"
 )
 
+// Enables printing of intermediate typer state to stdout.
+const debug = false
+
 // Typer is a high-level algorithm driver that gathers multiple ASTs of unit's source
 // texts to produce that unit's STG.
 //
@@ -57,9 +60,35 @@ type Typer struct {
 
 	// Scope of current block being translated.
 	scope *stg.Scope
+
+	// Symbols which failed conversion or depend on such symbols.
+	failed map[*stg.Symbol]bool
+
+	// Errors gathered during current compilation phase.
+	errors diag.Report
+
+	// Set for prelude units. Errors inside function, method and test
+	// bodies are saved into unit instead of being reported.
+	lazy bool
 }
 
 func Compile(c *stg.Common, unit *stg.Unit, texts []*ast.Text) diag.Error {
+	t := newTyper(c, unit, texts)
+	return t.compile(texts)
+}
+
+// CompilePrelude is the same as Compile, but errors inside bodies of
+// functions, methods and tests are not reported. They are saved into
+// unit (see stg.Unit.Deferred) and must be reported only if symbol is
+// used by the program. Prelude units are loaded implicitly, thus their
+// unused parts must not break the build.
+func CompilePrelude(c *stg.Common, unit *stg.Unit, texts []*ast.Text) diag.Error {
+	t := newTyper(c, unit, texts)
+	t.lazy = true
+	return t.compile(texts)
+}
+
+func newTyper(c *stg.Common, unit *stg.Unit, texts []*ast.Text) *Typer {
 	if c == nil {
 		panic("nil context")
 	}
@@ -71,114 +100,75 @@ func Compile(c *stg.Common, unit *stg.Unit, texts []*ast.Text) diag.Error {
 	}
 
 	unit.Init(&c.Global)
-	t := Typer{
+	t := &Typer{
 		ctx:  c,
 		unit: unit,
 
 		fields:            make(map[string]sm.Pin),
 		methodsByReceiver: make(map[*stg.Symbol][]*stg.Symbol),
+		failed:            make(map[*stg.Symbol]bool),
 	}
 	t.box.init(texts)
-	return t.compile(texts)
+	return t
 }
 
+// compile performs unit compilation in several phases. Errors in symbol
+// declarations do not stop the current phase, they are gathered into
+// a report. Compilation stops after indexing phases if there were errors,
+// since later phases rely on consistent unit scope. During inspection and
+// conversion phases symbols which failed (and symbols which use them)
+// are skipped, while other symbols are checked as usual.
 func (t *Typer) compile(texts []*ast.Text) diag.Error {
-	err := t.addTexts(texts)
+	t.addTexts(texts)
+	t.bindMethodReceivers()
+	t.checkGenericBinds()
+	err := t.errors.Err()
 	if err != nil {
 		return err
 	}
-	err = t.bindMethodReceivers()
-	if err != nil {
-		return err
-	}
-	err = t.checkGenericBinds()
-	if err != nil {
-		return err
-	}
-	err = t.indexGenericSymbols()
-	if err != nil {
-		return err
-	}
-	err = t.inspectSymbols()
+
+	t.indexGenericSymbols()
+	err = t.errors.Err()
 	if err != nil {
 		return err
 	}
+
+	t.inspectSymbols()
 	err = t.hoistSymbols()
 	if err != nil {
-		return err
+		t.report(err)
+		return t.errors.Err()
 	}
-	err = t.checkAndConvertAST()
-	if err != nil {
-		return err
-	}
-	return nil
-}
 
-func (t *Typer) addTexts(texts []*ast.Text) diag.Error {
-	err := t.addImports(t.unit.Imports)
-	if err != nil {
-		return err
-	}
+	t.checkAndConvertAST()
+	return t.errors.Err()
+}
 
+func (t *Typer) addTexts(texts []*ast.Text) {
+	t.addImports(t.unit.Imports)
 	for _, text := range texts {
-		err := t.addText(text)
-		if err != nil {
-			return err
-		}
+		t.addText(text)
 	}
-	return nil
 }
 
-func (t *Typer) addText(text *ast.Text) diag.Error {
-	err := t.addTypes(text.Types)
-	if err != nil {
-		return err
-	}
-	err = t.addConstants(text.Constants)
-	if err != nil {
-		return err
-	}
-	err = t.addFuns(text.Functions)
-	if err != nil {
-		return err
-	}
-	err = t.addAliases(text.Aliases)
-	if err != nil {
-		return err
-	}
-	err = t.addFunStubs(text.FunStubs)
-	if err != nil {
-		return err
-	}
-	err = t.addVars(text.Variables)
-	if err != nil {
-		return err
-	}
-	err = t.addMethods(text.Methods)
-	if err != nil {
-		return err
-	}
-	err = t.addTests(text.Tests)
-	if err != nil {
-		return err
-	}
-	err = t.addGenerics(text.Generics)
-	if err != nil {
-		return err
-	}
-	err = t.addGenBinds(text.GenBinds)
-	if err != nil {
-		return err
-	}
-
-	return nil
+func (t *Typer) addText(text *ast.Text) {
+	t.addTypes(text.Types)
+	t.addConstants(text.Constants)
+	t.addFuns(text.Functions)
+	t.addAliases(text.Aliases)
+	t.addFunStubs(text.FunStubs)
+	t.addVars(text.Variables)
+	t.addMethods(text.Methods)
+	t.addTests(text.Tests)
+	t.addGenerics(text.Generics)
+	t.addGenBinds(text.GenBinds)
 }
 
 func (t *Typer) bindMethod(receiver, method *stg.Symbol) {
 	t.methodsByReceiver[receiver] = append(t.methodsByReceiver[receiver], method)
 }
 
-func (t *Typer) bindMethodReceivers() diag.Error {
+func (t *Typer) bindMethodReceivers() {
 	if len(t.methods) != len(t.box.Methods) {
 		panic(fmt.Sprintf("mismatched number of method symbols (=%d) and AST nodes (=%d)", len(t.methods), len(t.box.Methods)))
 	}
@@ -194,153 +184,144 @@ func (t *Typer) bindMethodReceivers() diag.Error {
 
 		receiver := t.unit.Scope.Get(name)
 		if receiver == nil {
-			return &diag.SimpleMessageError{
+			t.report(&diag.SimpleMessageError{
 				Pin:  pin,
 				Text: fmt.Sprintf("method receiver \"%s\" refers to undefined symbol", name),
-			}
+			})
+			continue
 		}
 		if receiver.Kind != smk.Type {
-			return &diag.SimpleMessageError{
+			t.report(&diag.SimpleMessageError{
 				Pin:  pin,
 				Text: fmt.Sprintf("method receiver \"%s\" refers to %s symbol (instead of custom type)", name, receiver.Kind),
-			}
+			})
+			continue
 		}
 
 		t.bindMethod(receiver, s)
 	}
-	return nil
 }
 
-func (t *Typer) checkGenericBinds() diag.Error {
+func (t *Typer) checkGenericBinds() {
 	for _, bind := range t.box.GenBinds {
 		name := bind.Name.Str
 		pin := bind.Name.Pin
 
 		generic := t.unit.Scope.Get(name)
 		if generic == nil {
-			return &diag.SimpleMessageError{
+			t.report(&diag.SimpleMessageError{
 				Pin:  pin,
 				Text: fmt.Sprintf("generic bind \"%s\" refers to undefined symbol", name),
-			}
+			})
+			continue
 		}
 		if generic.Kind != smk.Gen {
-			return &diag.SimpleMessageError{
+			t.report(&diag.SimpleMessageError{
 				Pin:  pin,
 				Text: fmt.Sprintf("generic bind \"%s\" refers to %s symbol (instead of generic)", name, generic.Kind),
-			}
+			})
+			continue
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addImports(imports []sm.ImportSite) diag.Error {
+func (t *Typer) addImports(imports []sm.ImportSite) {
 	for _, s := range imports {
 		err := t.addImport(s)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addTypes(types []ast.Type) diag.Error {
+func (t *Typer) addTypes(types []ast.Type) {
 	for _, typ := range types {
 		err := t.addType(typ)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addConstants(constants []ast.TopConst) diag.Error {
+func (t *Typer) addConstants(constants []ast.TopConst) {
 	for _, c := range constants {
 		err := t.addConst(c)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addFuns(funs []ast.Fun) diag.Error {
+func (t *Typer) addFuns(funs []ast.Fun) {
 	for _, fun := range funs {
 		err := t.addFun(fun)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addAliases(aliases []ast.TopAlias) diag.Error {
+func (t *Typer) addAliases(aliases []ast.TopAlias) {
 	for _, alias := range aliases {
 		err := t.addAlias(alias)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addFunStubs(stubs []ast.FunStub) diag.Error {
+func (t *Typer) addFunStubs(stubs []ast.FunStub) {
 	for _, stub := range stubs {
 		err := t.addFunStub(stub)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addVars(vars []ast.TopVar) diag.Error {
+func (t *Typer) addVars(vars []ast.TopVar) {
 	for _, v := range vars {
 		err := t.addVar(v)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addMethods(methods []ast.Method) diag.Error {
+func (t *Typer) addMethods(methods []ast.Method) {
 	for _, method := range methods {
 		err := t.addMethod(method)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addTests(tests []ast.TestFun) diag.Error {
+func (t *Typer) addTests(tests []ast.TestFun) {
 	for _, test := range tests {
 		err := t.addTest(test)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addGenerics(gens []ast.Gen) diag.Error {
+func (t *Typer) addGenerics(gens []ast.Gen) {
 	for _, gen := range gens {
 		err := t.addGeneric(gen)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
-func (t *Typer) addGenBinds(binds []ast.GenBind) diag.Error {
+func (t *Typer) addGenBinds(binds []ast.GenBind) {
 	for _, bind := range binds {
 		err := t.addGenBind(bind)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
 func (t *Typer) addImport(s sm.ImportSite) diag.Error {
@@ -396,9 +377,41 @@ func (t *Typer) addFunStub(stub ast.FunStub) diag.Error {
 	symbol := t.unit.Scope.Alloc(smk.Fun, name, pin)
 	symbol.Aux = t.box.addFunStub(stub)
 	symbol.Flags = stg.SymbolStub
+	if stub.Pub {
+		symbol.Flags |= stg.SymbolPublic
+	}
+
+	link, err := getLinkName(stub.Traits)
+	if err != nil {
+		return err
+	}
+	symbol.Link = link
 	return nil
 }
 
+// Returns value of "link.name" property if it is present in traits.
+func getLinkName(traits ast.Traits) (string, diag.Error) {
+	if traits.Props == nil {
+		return "", nil
+	}
+
+	for _, p := range *traits.Props {
+		if p.Name != "link.name" {
+			continue
+		}
+
+		s, ok := p.Exp.(ast.String)
+		if !ok || s.Val == "" {
+			return "", &diag.SimpleMessageError{
+				Pin:  p.Pin,
+				Text: "property \"link.name\" must have non-empty string value",
+			}
+		}
+		return s.Val, nil
+	}
+	return "", nil
+}
+
 func (t *Typer) addType(typ ast.Type) diag.Error {
 	name := typ.Name.Str
 	pin := typ.Name.Pin
@@ -448,19 +461,21 @@ func (t *Typer) addVar(v ast.TopVar) diag.Error {
 	name := v.Name.Str
 	pin := v.Name.Pin
 
-	if v.Pub {
-		return &diag.SimpleMessageError{
-			Pin:  pin,
-			Text: fmt.Sprintf("variable \"%s\" declared as public", name),
-		}
-	}
-
 	if t.unit.Scope.Has(name) {
 		return errMultDef(name, pin)
 	}
 
 	symbol := t.unit.Scope.Alloc(smk.Var, name, pin)
 	symbol.Aux = t.box.addVar(v)
+
+	if v.Pub {
+		// symbol is still added to avoid spurious errors
+		// about undefined variable in other places
+		return &diag.SimpleMessageError{
+			Pin:  pin,
+			Text: fmt.Sprintf("variable \"%s\" declared as public", name),
+		}
+	}
 	return nil
 }
 
@@ -513,6 +528,35 @@ func (t *Typer) addGenBind(bind ast.GenBind) diag.Error {
 	return nil
 }
 
+// report adds error to the list of errors gathered during
+// current compilation phase.
+func (t *Typer) report(err diag.Error) {
+	t.errors.Add(err)
+}
+
+// deferError saves error found inside body of a given symbol from prelude
+// unit. Only the first error is saved for each symbol. Returns false if
+// error cannot be deferred and must be reported right away.
+func (t *Typer) deferError(s *stg.Symbol, err diag.Error) bool {
+	if !t.lazy {
+		return false
+	}
+	switch s.Kind {
+	case smk.Fun, smk.Method, smk.Test:
+	default:
+		return false
+	}
+
+	if t.unit.Deferred == nil {
+		t.unit.Deferred = make(map[*stg.Symbol]diag.Error)
+	}
+	_, ok := t.unit.Deferred[s]
+	if !ok {
+		t.unit.Deferred[s] = err
+	}
+	return true
+}
+
 func errMultDef(name string, pin sm.Pin) diag.Error {
 	return &diag.SimpleMessageError{
 		Pin:  pin,
@@ -520,7 +564,7 @@ func errMultDef(name string, pin sm.Pin) diag.Error {
 	}
 }
 
-func (t *Typer) indexGenericSymbols() diag.Error {
+func (t *Typer) indexGenericSymbols() {
 	for _, g := range t.box.Generics {
 		name := g.Name.Str
 		s := t.unit.Scope.Get(name)
@@ -529,10 +573,9 @@ func (t *Typer) indexGenericSymbols() diag.Error {
 		}
 		err := t.initGeneric(s)
 		if err != nil {
-			return err
+			t.report(err)
 		}
 	}
-	return nil
 }
 
 func (t *Typer) initGeneric(s *stg.Symbol) diag.Error {
@@ -636,9 +679,7 @@ func (t *Typer) addGenericMethod(g *stg.Generic, m ast.Method) diag.Error {
 	return nil
 }
 
-func (t *Typer) inspectSymbols() diag.Error {
-	const debug = false
-
+func (t *Typer) inspectSymbols() {
 	symbols := t.unit.Scope.Symbols
 	t.ins.Init()
 	t.gb.Init(len(symbols))
@@ -649,8 +690,16 @@ func (t *Typer) inspectSymbols() diag.Error {
 
 		t.ins.Reset()
 		err := t.inspectSymbol(s)
-		if err != nil {
-			return err
+		if err != nil && !t.deferError(s, err) {
+			// symbol is still added to the graph with links gathered
+			// so far, but will be skipped during conversion along
+			// with symbols which use it
+			//
+			// deferred errors come from bodies of prelude symbols,
+			// such symbols are converted as usual, since their
+			// signatures are not affected
+			t.report(err)
+			t.failed[s] = true
 		}
 
 		links := t.ins.Links()
@@ -664,5 +713,4 @@ func (t *Typer) inspectSymbols() diag.Error {
 
 		t.gb.Add(s, links)
 	}
-	return nil
 }
@@ -3,32 +3,91 @@ package typer
 import (
 	"fmt"
 
+	"github.com/mebyus/ku/goku/compiler/ast"
 	"github.com/mebyus/ku/goku/compiler/diag"
 	"github.com/mebyus/ku/goku/compiler/enums/smk"
+	"github.com/mebyus/ku/goku/compiler/enums/tpk"
 	"github.com/mebyus/ku/goku/compiler/typer/stg"
 )
 
-func (t *Typer) checkAndConvertAST() diag.Error {
+func (t *Typer) checkAndConvertAST() {
 	graph := t.graph
+	t.declareRecursiveTypes()
+
 	for _, i := range graph.Isolated {
-		err := t.convSymbol(graph.Nodes[i].Symbol)
-		if err != nil {
-			return err
-		}
+		t.convNode(i)
 	}
 
 	for _, comp := range graph.Comps {
 		for _, c := range comp.Cohorts {
 			for _, k := range c {
-				err := t.convSymbol(graph.Nodes[comp.V[k].Index].Symbol)
-				if err != nil {
-					return err
-				}
+				t.convNode(comp.V[k].Index)
 			}
 		}
 	}
 
-	return t.translateSymbols()
+	t.translateSymbols()
+}
+
+// convNode converts symbol attached to graph node with a given index.
+// Symbols which use another symbol that failed conversion are skipped
+// without reporting, since their errors would be a mere consequence
+// of the original one.
+func (t *Typer) convNode(i uint32) {
+	n := &t.graph.Nodes[i]
+	if t.failed[n.Symbol] {
+		return
+	}
+	for _, l := range n.Anc {
+		if t.failed[t.graph.Nodes[l.Index].Symbol] {
+			t.failed[n.Symbol] = true
+			return
+		}
+	}
+
+	err := t.convSymbol(n.Symbol)
+	if err != nil {
+		t.failed[n.Symbol] = true
+		t.report(err)
+	}
+}
+
+// declareRecursiveTypes creates definitions for type symbols which may be
+// referenced (through indirection) before they are converted. These are
+// types with self loops or types which belong to clusters. Created type is
+// filled with actual definition during type symbol conversion.
+func (t *Typer) declareRecursiveTypes() {
+	graph := t.graph
+	for i := 0; i < len(graph.Nodes); i += 1 {
+		n := &graph.Nodes[i]
+		if n.SelfLoop {
+			t.declareType(n.Symbol)
+		}
+	}
+
+	for _, comp := range graph.Comps {
+		for _, v := range comp.V {
+			if v.Cluster != 0 {
+				t.declareType(graph.Nodes[v.Index].Symbol)
+			}
+		}
+	}
+}
+
+func (t *Typer) declareType(s *stg.Symbol) {
+	if s.Kind != smk.Type || s.Def != nil {
+		return
+	}
+	_, ok := t.box.Type(s.Aux).Spec.(ast.Bag)
+	if ok {
+		return
+	}
+
+	s.Def = stg.SymDefType{Type: &stg.Type{
+		Def:   &stg.Custom{Symbol: s},
+		Flags: stg.TypeFlagRecursive,
+		Kind:  tpk.Custom,
+	}}
 }
 
 func (t *Typer) convSymbol(s *stg.Symbol) diag.Error {
@@ -45,6 +104,14 @@ func (t *Typer) convSymbol(s *stg.Symbol) diag.Error {
 		return t.convVarSymbol(s)
 	case smk.Test:
 		return t.convTestSymbol(s)
+	case smk.Gen:
+		// generic body is checked only upon instantiation
+		return nil
+	case smk.Alias:
+		return &diag.SimpleMessageError{
+			Pin:  s.Pin,
+			Text: fmt.Sprintf("generic instantiation \"%s\" is not implemented", s.Name),
+		}
 	default:
 		panic(fmt.Sprintf("unexpected \"%s\" (=%d) symbol (%s)", s.Kind, s.Kind, s.Name))
 	}
@@ -9,8 +9,6 @@ import (
 )
 
 func (t *Typer) convVarSymbol(s *stg.Symbol) diag.Error {
-	const debug = false
-
 	v := t.box.Var(s.Aux)
 	name := v.Name.Str
 
@@ -42,8 +40,6 @@ func (t *Typer) convVarSymbol(s *stg.Symbol) diag.Error {
 }
 
 func (t *Typer) convConstSymbol(s *stg.Symbol) diag.Error {
-	const debug = false
-
 	c := t.box.Const(s.Aux)
 	name := c.Name.Str
 
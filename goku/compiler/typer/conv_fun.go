@@ -12,8 +12,13 @@ import (
 )
 
 func (t *Typer) convFunSymbol(s *stg.Symbol) diag.Error {
-	fun := t.box.Fun(s.Aux)
-	def, err := t.createFun(s.Name, fun.Signature)
+	var sig ast.Signature
+	if s.IsStub() {
+		sig = t.box.FunStub(s.Aux).Signature
+	} else {
+		sig = t.box.Fun(s.Aux).Signature
+	}
+	def, err := t.createFun(s.Name, sig)
 	if err != nil {
 		return err
 	}
@@ -112,6 +117,13 @@ func (t *Typer) createFun(sname string, sig ast.Signature) (*stg.Fun, diag.Error
 }
 
 func (t *Typer) convTestSymbol(s *stg.Symbol) diag.Error {
+	if t.ctx.Types.Prelude.TestContext == nil {
+		return &diag.SimpleMessageError{
+			Pin:  s.Pin,
+			Text: fmt.Sprintf("test \"%s\" requires context type from \"stf\" unit of standard library", s.Name),
+		}
+	}
+
 	def := &stg.Fun{}
 	scope := &def.Body.Scope
 	scope.Init(sck.Node, &t.unit.Scope)
@@ -10,8 +10,6 @@ import (
 )
 
 func (t *Typer) convTypeSymbol(s *stg.Symbol) diag.Error {
-	const debug = false
-
 	spec := t.box.Type(s.Aux).Spec
 	methods := t.methodsByReceiver[s]
 
@@ -22,6 +20,16 @@ func (t *Typer) convTypeSymbol(s *stg.Symbol) diag.Error {
 		}
 	}
 
+	_, ok := spec.(ast.Bag)
+	if ok {
+		// bag types are not translated yet, their symbols stay
+		// in unit scope without definition
+		if debug {
+			fmt.Printf("WARN: bag type \"%s\" not implemented\n", s.Name)
+		}
+		return nil
+	}
+
 	p, ok := spec.(ast.Struct)
 	if ok {
 		err := t.checkStructCollisions(s, p.Fields, methods)
@@ -53,18 +61,27 @@ func (t *Typer) convTypeSymbol(s *stg.Symbol) diag.Error {
 			panic("custom type inside another custom type")
 		}
 	}
-	custom := &stg.Custom{
-		Symbol:  s,
-		Methods: methods,
-		Type:    typ,
+
+	var def *stg.Type
+	if s.Def != nil {
+		// recursive type was declared before conversion,
+		// fill its definition in place
+		def = s.Def.(stg.SymDefType).Type
+	} else {
+		def = &stg.Type{
+			Def:  &stg.Custom{Symbol: s},
+			Kind: tpk.Custom,
+		}
+		s.Def = stg.SymDefType{Type: def}
 	}
+
+	custom := def.Def.(*stg.Custom)
+	custom.Methods = methods
+	custom.Type = typ
 	custom.Init()
 
-	def := &stg.Type{
-		Def:  custom,
-		Kind: tpk.Custom,
-	}
-	s.Def = stg.SymDefType{Type: def}
+	def.Size = typ.Size
+	def.Align = typ.Align
 	return nil
 }
 
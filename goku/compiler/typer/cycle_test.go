@@ -0,0 +1,103 @@
+package typer_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mebyus/ku/goku/compiler/builder"
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/sm"
+)
+
+func TestDefinitionCycle(t *testing.T) {
+	tests := []struct {
+		name string
+		unit string
+
+		// Names of symbols along reported cycle. Nil if
+		// definition cycle must not be reported.
+		sites []string
+
+		// Expected error message. Empty if unit must compile.
+		want string
+
+		// Expected rendered cycle sites with their positions.
+		render string
+	}{
+		{
+			name: "1 cluster with indirection",
+			unit: "00001",
+		},
+		{
+			name:  "2 direct value cycle",
+			unit:  "00002",
+			sites: []string{"A", "B", "C", "A"},
+			want:  "recursive definition without indirection: A -> B -> C -> A",
+			render: `
+    testdata/cycle/00002/abc.ku:1:6: A
+    testdata/cycle/00002/abc.ku:5:6: B
+    testdata/cycle/00002/abc.ku:9:6: C`,
+		},
+		{
+			name: "3 direct self cycle",
+			unit: "00003",
+			want: "type \"Node\" definition directly references itself",
+		},
+		{
+			name:  "4 value cycle inside cluster with indirection",
+			unit:  "00004",
+			sites: []string{"A", "B", "A"},
+			want:  "recursive definition without indirection: A -> B -> A",
+			render: `
+    testdata/cycle/00004/mixed.ku:3:6: A
+    testdata/cycle/00004/mixed.ku:8:6: B`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := builder.Walk(builder.WalkConfig{
+				Dir: builder.BaseDirs{Loc: "testdata/cycle"},
+			}, builder.QueueItem{Path: sm.Local(tt.unit)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = builder.CompileBundle(bundle)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("CompileBundle() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("CompileBundle() error = nil, want %s", tt.want)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("CompileBundle() error = %v, want %s", err, tt.want)
+			}
+
+			c, ok := err.(*diag.DefinitionCycleError)
+			if !ok {
+				if tt.sites != nil {
+					t.Errorf("CompileBundle() error type = %T, want definition cycle", err)
+				}
+				return
+			}
+			var names []string
+			for _, s := range c.Sites {
+				names = append(names, s.Name)
+				if s.Pin == 0 {
+					t.Errorf("site \"%s\" has no position", s.Name)
+				}
+			}
+			if !slices.Equal(names, tt.sites) {
+				t.Errorf("cycle sites = %v, want %v", names, tt.sites)
+			}
+
+			got := diag.Stringify(bundle.Pool, err)
+			if got != tt.want+tt.render {
+				t.Errorf("Render() = %s, want %s", got, tt.want+tt.render)
+			}
+		})
+	}
+}
@@ -6,13 +6,15 @@ import (
 	"sort"
 
 	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/enums/smk"
 	"github.com/mebyus/ku/goku/compiler/typer/stg"
 )
 
 func (t *Typer) hoistSymbols() diag.Error {
-	const debug = false
-
-	graph := t.gb.Scan()
+	graph, err := t.gb.Scan()
+	if err != nil {
+		return err
+	}
 	t.graph = graph
 
 	if debug {
@@ -424,7 +426,7 @@ func (g *GraphBuilder) bfs(n uint32) {
 	}
 }
 
-func (g *GraphBuilder) Scan() *Graph {
+func (g *GraphBuilder) Scan() (*Graph, diag.Error) {
 	for i := 0; i < len(g.Nodes); i += 1 {
 		anc := g.mapAncestors(uint32(i))
 		g.Nodes[i].Anc = anc
@@ -439,14 +441,17 @@ func (g *GraphBuilder) Scan() *Graph {
 	g.split()
 	g.discoverClusters()
 
-	// TODO: scan clusters for direct-indirect correctness
+	err := g.checkClusters()
+	if err != nil {
+		return nil, err
+	}
 	g.rank()
 
 	return &Graph{
 		Nodes:    g.Nodes,
 		Comps:    g.Comps,
 		Isolated: g.Isolated,
-	}
+	}, nil
 }
 
 func (g *GraphBuilder) rank() {
@@ -464,13 +469,144 @@ func (g *GraphBuilder) rank() {
 		}
 
 		if len(c.Clusters) != 0 {
-			panic("recursive cluster types not implemented")
+			g.cut(c)
 		}
 
 		r.Rank(c)
 	}
 }
 
+// isStrongLink reports whether j-th ancestor link of vertex v inside
+// component c imposes conversion order. Links between vertices of different
+// clusters always do. Inside a cluster link is weak if it goes through
+// indirection or its descendant is a function, method or test: only their
+// signatures are converted in order, bodies are translated after all
+// symbols are converted.
+func (g *GraphBuilder) isStrongLink(c *GraphComponent, v uint32, j int) bool {
+	a := c.V[v].Anc[j]
+	if c.V[v].Cluster == 0 || c.V[v].Cluster != c.V[a].Cluster {
+		return true
+	}
+
+	n := &g.Nodes[c.V[v].Index]
+	if n.Anc[j].Kind == LinkIndirect {
+		return false
+	}
+	switch n.Symbol.Kind {
+	case smk.Fun, smk.Method, smk.Test:
+		return false
+	}
+	return true
+}
+
+// checkClusters searches each cluster for a cycle formed only by strong
+// links. Symbols on such a cycle contain each other by value, thus they
+// cannot be defined in any order.
+func (g *GraphBuilder) checkClusters() diag.Error {
+	for k := 0; k < len(g.Comps); k += 1 {
+		c := &g.Comps[k]
+		for _, list := range c.Clusters {
+			cycle := g.findStrongCycle(c, list)
+			if cycle == nil {
+				continue
+			}
+
+			sites := make([]diag.CycleSite, 0, len(cycle))
+			for _, v := range cycle {
+				s := g.Nodes[c.V[v].Index].Symbol
+				sites = append(sites, diag.CycleSite{
+					Name: s.Name,
+					Pin:  s.Pin,
+				})
+			}
+			return &diag.DefinitionCycleError{Sites: sites}
+		}
+	}
+	return nil
+}
+
+// findStrongCycle returns path of vertices along the first found cycle
+// of strong links inside the given cluster. Path starts and ends with
+// the same vertex, each vertex in path uses the next one. Returns nil if
+// there is no such cycle.
+func (g *GraphBuilder) findStrongCycle(c *GraphComponent, list []uint32) []uint32 {
+	const (
+		unseen = iota
+		onPath
+		done
+	)
+	state := make(map[uint32]uint8, len(list))
+	var path []uint32
+
+	var visit func(v uint32) []uint32
+	visit = func(v uint32) []uint32 {
+		state[v] = onPath
+		path = append(path, v)
+
+		for j, a := range c.V[v].Anc {
+			if c.V[a].Cluster != c.V[v].Cluster || !g.isStrongLink(c, v, j) {
+				continue
+			}
+
+			switch state[a] {
+			case unseen:
+				cycle := visit(a)
+				if cycle != nil {
+					return cycle
+				}
+			case onPath:
+				i := slices.Index(path, a)
+				return append(slices.Clone(path[i:]), a)
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[v] = done
+		return nil
+	}
+
+	for _, v := range list {
+		if state[v] == unseen {
+			cycle := visit(v)
+			if cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// cut removes weak links between vertices of the same cluster. After
+// cluster check remaining links form acyclic graph, thus component
+// can be ranked as usual. Component roots are recomputed, since vertices
+// may lose all their ancestors.
+//
+// As a result vertices of each cluster are ranked only by strong links
+// between them, for example struct type is ranked after types which it
+// embeds by value, but may be ranked before types it points to.
+func (g *GraphBuilder) cut(c *GraphComponent) {
+	for v := 0; v < len(c.V); v += 1 {
+		var anc []uint32
+		for j, a := range c.V[v].Anc {
+			if g.isStrongLink(c, uint32(v), j) {
+				anc = append(anc, a)
+			}
+		}
+		c.V[v].Anc = anc
+		c.V[v].Des = nil
+	}
+
+	c.Roots = nil
+	for v := 0; v < len(c.V); v += 1 {
+		if len(c.V[v].Anc) == 0 {
+			c.Roots = append(c.Roots, uint32(v))
+		}
+		for _, a := range c.V[v].Anc {
+			c.V[a].Des = append(c.V[a].Des, uint32(v))
+		}
+	}
+}
+
 func (g *GraphBuilder) discoverClusters() {
 	if len(g.Comps) == 0 {
 		return
@@ -657,7 +793,6 @@ func (w *GraphComponentWalker) walk(v uint32) {
 		slices.Sort(list)
 
 		w.c.Clusters = append(w.c.Clusters, list)
-		fmt.Printf("cluster (%d): %v\n", w.low[v], list)
 	}
 }
 
@@ -666,6 +801,9 @@ func (w *GraphComponentWalker) walk(v uint32) {
 // information within itself.
 type ComponentVertex struct {
 	// list of ancestor indices inside V
+	//
+	// For components with clusters weak links inside clusters
+	// are removed before ranking.
 	Anc []uint32
 
 	// list of descendant indices inside V
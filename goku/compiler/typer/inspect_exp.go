@@ -66,10 +66,7 @@ func (t *Typer) linkConstSymbol(sym ast.Symbol) diag.Error {
 	name := sym.Name
 	s := t.unit.Scope.Lookup(name)
 	if s == nil {
-		return &diag.SimpleMessageError{
-			Pin:  sym.Pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", name),
-		}
+		return diag.UndefinedSymbol("name", name, sym.Pin)
 	}
 	if s.Kind != smk.Const {
 		return &diag.SimpleMessageError{
@@ -9,6 +9,10 @@ import (
 )
 
 func (t *Typer) inspectFunSymbol(s *stg.Symbol) diag.Error {
+	if s.IsStub() {
+		return t.inspectSignature(t.box.FunStub(s.Aux).Signature)
+	}
+
 	fun := t.box.Fun(s.Aux)
 
 	err := t.inspectSignature(fun.Signature)
@@ -406,10 +406,7 @@ func (t *Typer) inspectChainExp(chain ast.Chain) diag.Error {
 		name = "unsafe." + u.Name
 		s := t.unit.Scope.Get(name)
 		if s == nil {
-			return &diag.SimpleMessageError{
-				Pin:  u.Pin,
-				Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", name),
-			}
+			return diag.UndefinedSymbol("name", name, u.Pin)
 		}
 
 		t.ins.link(s)
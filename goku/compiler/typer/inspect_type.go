@@ -35,7 +35,9 @@ func (t *Typer) inspectCustomTypeSpec(spec ast.TypeSpec) diag.Error {
 	case ast.Struct:
 		return t.inspectFields(p.Fields)
 	case ast.Bag:
-		fmt.Printf("WARN: bag type specifier not implemented\n")
+		if debug {
+			fmt.Printf("WARN: bag type specifier not implemented\n")
+		}
 		return nil
 	case ast.Enum:
 		return t.inspectEnum(p)
@@ -64,10 +66,7 @@ func (t *Typer) inspectTypeFullName(p ast.TypeFullName) diag.Error {
 	iname := p.Import.Str
 	m := t.unit.Scope.Lookup(iname)
 	if m == nil {
-		return &diag.SimpleMessageError{
-			Pin:  p.Name.Pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", iname),
-		}
+		return diag.UndefinedSymbol("name", iname, p.Import.Pin)
 	}
 	if m.Kind != smk.Import {
 		return &diag.SimpleMessageError{
@@ -80,10 +79,7 @@ func (t *Typer) inspectTypeFullName(p ast.TypeFullName) diag.Error {
 	name := p.Name.Str
 	s := unit.Scope.Lookup(name)
 	if s == nil {
-		return &diag.SimpleMessageError{
-			Pin:  p.Name.Pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", name),
-		}
+		return diag.UndefinedSymbol("name", name, p.Name.Pin)
 	}
 	if s.Kind != smk.Type {
 		return &diag.SimpleMessageError{
@@ -105,10 +101,7 @@ func (t *Typer) linkTypeName(p ast.TypeName) diag.Error {
 	name := p.Name.Str
 	s := t.unit.Scope.Lookup(name)
 	if s == nil {
-		return &diag.SimpleMessageError{
-			Pin:  p.Name.Pin,
-			Text: fmt.Sprintf("type name \"%s\" refers to undefined symbol", name),
-		}
+		return diag.UndefinedSymbol("type name", name, p.Name.Pin)
 	}
 	if s.Kind != smk.Type {
 		return &diag.SimpleMessageError{
@@ -16,7 +16,11 @@ func (s *Scope) translateBgenFunCall(se *SymExp, args []ast.Exp) (Exp, diag.Erro
 	case bgk.Min:
 		return s.translateMinCall(se.Pin, args)
 	default:
-		panic(fmt.Sprintf("unexpected \"%s\" (=%d) builtin generic function", se.Symbol.Name, k))
+		name := se.Symbol.Name
+		return nil, diag.Unsupported(fmt.Sprintf("builtin generic function \"%s\"", name), sm.Span{
+			Pin: se.Pin,
+			Len: uint32(len(name)),
+		})
 	}
 }
 
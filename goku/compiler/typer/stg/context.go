@@ -1,6 +1,9 @@
 package stg
 
-import "github.com/mebyus/ku/goku/compiler/sm"
+import (
+	"github.com/mebyus/ku/goku/compiler/enums/smk"
+	"github.com/mebyus/ku/goku/compiler/sm"
+)
 
 // Common contains things which (by their nature) exist as a single instance
 // during whole program compilation.
@@ -13,13 +16,21 @@ type Common struct {
 	Gens   GenIndex
 	Map    map[sm.UnitPath]*Unit
 
+	// Data model of compilation target. DefaultDataModel is used
+	// if left nil.
+	Model *DataModel
+
 	pool *sm.Pool
 }
 
 func (c *Common) Init(pool *sm.Pool) {
 	c.pool = pool
 
-	c.Types.Init()
+	if c.Model == nil {
+		c.Model = DefaultDataModel
+	}
+
+	c.Types.Init(c.Model)
 	c.Gens.Init()
 	c.Global.InitGlobal(&c.Types, &c.Gens)
 
@@ -30,3 +41,21 @@ func (c *Common) Init(pool *sm.Pool) {
 	addBuiltinTypes(c)
 	addBuiltinGens(c)
 }
+
+// SetTestPrelude recognizes types from a given testing framework unit.
+// Must be called after the unit is translated and before translation of
+// units with test functions.
+//
+// Does nothing if unit does not have suitable context type.
+func (c *Common) SetTestPrelude(unit *Unit) {
+	s := unit.Scope.Get("Context")
+	if s == nil || s.Kind != smk.Type {
+		return
+	}
+	def, ok := s.Def.(SymDefType)
+	if !ok {
+		return
+	}
+
+	c.Types.Prelude.TestContext = c.Types.getRef(def.Type)
+}
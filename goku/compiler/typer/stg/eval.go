@@ -23,7 +23,7 @@ func (s *Scope) EvalConstExp(exp ast.Exp) (Exp, diag.Error) {
 	case ast.Integer:
 		return s.Types.MakeInteger(e.Pin, e.Val), nil
 	case ast.String:
-		return s.Types.MakeString(e.Pin, e.Val), nil
+		return s.Types.MakeString(e.Span(), e.Val), nil
 	case ast.True:
 		return s.Types.MakeBoolean(e.Pin, true), nil
 	case ast.False:
@@ -37,6 +37,8 @@ func (s *Scope) EvalConstExp(exp ast.Exp) (Exp, diag.Error) {
 		return s.translateConstBinaryExp(e)
 	case ast.Paren:
 		return s.EvalConstExp(e.Exp)
+	case ast.Size:
+		return s.translateSizeExp(e)
 	default:
 		panic(fmt.Sprintf("unexpected \"%s\" (=%d) expression (%T)", e.Kind(), e.Kind(), e))
 	}
@@ -48,10 +50,7 @@ func (s *Scope) evalConstSymbolExp(sym ast.Symbol) (Exp, diag.Error) {
 
 	symbol := s.Lookup(name)
 	if s == nil {
-		return nil, &diag.SimpleMessageError{
-			Pin:  pin,
-			Text: fmt.Sprintf("type name \"%s\" refers to undefined symbol", name),
-		}
+		return nil, diag.UndefinedSymbol("type name", name, pin)
 	}
 	if symbol.Kind != smk.Const {
 		return nil, &diag.SimpleMessageError{
@@ -31,7 +31,7 @@ func (s *Scope) TranslateExp(hint *Hint, exp ast.Exp) (Exp, diag.Error) {
 	case ast.PinExp:
 		return s.Types.MakeInteger(e.Pin, uint64(e.Pin)), nil
 	case ast.String:
-		return s.Types.MakeString(e.Pin, e.Val), nil
+		return s.Types.MakeString(e.Span(), e.Val), nil
 	case ast.True:
 		return s.Types.MakeBoolean(e.Pin, true), nil
 	case ast.False:
@@ -49,7 +49,7 @@ func (s *Scope) TranslateExp(hint *Hint, exp ast.Exp) (Exp, diag.Error) {
 	case ast.Binary:
 		return s.translateBinaryExp(hint, e)
 	case ast.Chain:
-		return s.TranslateChain(hint, e)
+		return s.translateChainOperand(hint, e)
 	case ast.Call:
 		return s.TranslateCall(hint, e)
 	case ast.Slice:
@@ -60,11 +60,27 @@ func (s *Scope) TranslateExp(hint *Hint, exp ast.Exp) (Exp, diag.Error) {
 		return s.translateCast(hint, e)
 	case ast.DerefSlice:
 		return s.translateDerefSlice(hint, e)
+	case ast.Size:
+		return s.translateSizeExp(e)
 	default:
 		panic(fmt.Sprintf("unexpected %s (=%d) expression (%T)", e.Kind(), e.Kind(), e))
 	}
 }
 
+// Translates chain which is used as an operand (not as a callee).
+func (s *Scope) translateChainOperand(hint *Hint, chain ast.Chain) (Exp, diag.Error) {
+	exp, err := s.TranslateChain(hint, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := exp.(*BoundMethod)
+	if ok {
+		return nil, diag.Unsupported(fmt.Sprintf("usage of method \"%s\" as value", m.Symbol.Name), chain.Span())
+	}
+	return exp, nil
+}
+
 func (s *Scope) translateUnaryExp(hint *Hint, u ast.Unary) (Exp, diag.Error) {
 	exp, err := s.TranslateExp(hint, u.Exp)
 	if err != nil {
@@ -143,7 +159,7 @@ func (s *Scope) translateMakeSpan(hint *Hint, d ast.DerefSlice) (Exp, diag.Error
 
 	var start Exp
 	if d.Start != nil {
-		start, err := s.TranslateExp(hint, d.Start)
+		start, err = s.TranslateExp(hint, d.Start)
 		if err != nil {
 			return nil, err
 		}
@@ -170,6 +186,7 @@ func (s *Scope) translateMakeSpan(hint *Hint, d ast.DerefSlice) (Exp, diag.Error
 	}
 
 	return &MakeSpan{
+		Exp:   c,
 		Start: start,
 		End:   end,
 		typ:   s.Types.getSpan(elem),
@@ -421,10 +438,7 @@ func (s *Scope) TranslateChain(hint *Hint, exp ast.Chain) (Exp, diag.Error) {
 
 	start := s.Lookup(name)
 	if start == nil {
-		return nil, &diag.SimpleMessageError{
-			Pin:  pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", name),
-		}
+		return nil, diag.UndefinedSymbol("name", name, pin)
 	}
 
 	var e Exp
@@ -466,10 +480,7 @@ func (s *Scope) TranslateChain(hint *Hint, exp ast.Chain) (Exp, diag.Error) {
 		sname := p.Name.Str
 		symbol := unit.Scope.Lookup(sname)
 		if symbol == nil {
-			return nil, &diag.SimpleMessageError{
-				Pin:  p.Name.Pin,
-				Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", sname),
-			}
+			return nil, diag.UndefinedSymbol("name", sname, p.Name.Pin)
 		}
 		if symbol.Kind != smk.Fun {
 			return nil, &diag.SimpleMessageError{
@@ -600,15 +611,22 @@ func (s *Scope) applyIndexPart(hint *Hint, exp Exp, part ast.Index) (Exp, diag.E
 	typ := exp.Type()
 	switch typ.Kind {
 	case tpk.Array:
-		panic("not implemented")
+		return nil, diag.Unsupported("array index", index.Span())
 	case tpk.Span:
 		return &SpanIndex{
 			Exp:   exp,
 			Index: index,
 			typ:   typ.Def.(Span).Type,
 		}, nil
+	case tpk.String:
+		// string has the same layout as span of bytes
+		return &SpanIndex{
+			Exp:   exp,
+			Index: index,
+			typ:   s.Types.Known.U8,
+		}, nil
 	case tpk.CapBuf:
-		panic("not implemented")
+		return nil, diag.Unsupported("capped buffer index", index.Span())
 	default:
 		return nil, &diag.SimpleMessageError{
 			Pin:  index.Span().Pin,
@@ -673,6 +691,8 @@ func (s *Scope) applySelectPart(exp Exp, part ast.Select) (Exp, diag.Error) {
 		return s.applySelectToSpan(exp, part)
 	case tpk.Pointer, tpk.Ref:
 		return s.applySelectToPointer(exp, part)
+	case tpk.String:
+		return s.applySelectToString(exp, part)
 	case tpk.Custom:
 		if typ.Def.(*Custom).Type.Kind == tpk.Struct {
 			return s.applySelectToStruct(exp, part)
@@ -682,7 +702,37 @@ func (s *Scope) applySelectPart(exp Exp, part ast.Select) (Exp, diag.Error) {
 			Text: fmt.Sprintf("cannot apply select part to %s type", typ),
 		}
 	default:
-		panic(fmt.Sprintf("unexpected %s type", typ))
+		return nil, &diag.SimpleMessageError{
+			Pin:  part.Name.Pin,
+			Text: fmt.Sprintf("cannot apply select part to %s type", typ),
+		}
+	}
+}
+
+// String has the same layout as span of bytes, thus
+// selecting its fields is represented by span nodes.
+func (s *Scope) applySelectToString(exp Exp, part ast.Select) (Exp, diag.Error) {
+	name := part.Name.Str
+	pin := part.Name.Pin
+
+	switch name {
+	case "len":
+		return &SelectSpanLen{
+			Exp: exp,
+			Pin: pin,
+			typ: s.Types.Known.Uint,
+		}, nil
+	case "ptr":
+		return &SelectSpanPtr{
+			Exp: exp,
+			Pin: pin,
+			typ: s.Types.getArrayPointer(s.Types.Known.U8),
+		}, nil
+	default:
+		return nil, &diag.SimpleMessageError{
+			Pin:  pin,
+			Text: fmt.Sprintf("string does not have \"%s\" field", name),
+		}
 	}
 }
 
@@ -694,7 +744,7 @@ func (s *Scope) applySelectToStruct(exp Exp, part ast.Select) (Exp, diag.Error)
 	c := typ.Def.(*Custom)
 	m := c.getMethod(name)
 	if m != nil {
-		panic("not implemented")
+		return nil, diag.Unsupported("method call on struct value", sm.Span{Pin: pin, Len: uint32(len(name))})
 	}
 
 	f := c.Type.Def.(*Struct).getField(name)
@@ -862,10 +912,7 @@ func (s *Scope) translateSymbolExp(sym ast.Symbol) (Exp, diag.Error) {
 
 	symbol := s.Lookup(name)
 	if symbol == nil {
-		return nil, &diag.SimpleMessageError{
-			Pin:  pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", name),
-		}
+		return nil, diag.UndefinedSymbol("name", name, pin)
 	}
 
 	switch symbol.Kind {
@@ -901,10 +948,7 @@ func (s *Scope) translateBinaryExp(hint *Hint, exp ast.Binary) (Exp, diag.Error)
 	if ta.Kind == tpk.Boolean && ta.IsStatic() {
 		// tb is not static here
 		if tb.Kind != tpk.Boolean {
-			return nil, &diag.SimpleMessageError{
-				Pin:  b.Span().Pin,
-				Text: fmt.Sprintf("incompatible types in binary expression bool and %s", tb),
-			}
+			return nil, errBinaryTypes(fmt.Sprintf("incompatible types in binary expression bool and %s", tb), a, b, exp.Op)
 		}
 
 		v := a.(*Boolean).Val
@@ -930,10 +974,7 @@ func (s *Scope) translateBinaryExp(hint *Hint, exp ast.Binary) (Exp, diag.Error)
 	if tb.Kind == tpk.Boolean && tb.IsStatic() {
 		// ta is not static here
 		if ta.Kind != tpk.Boolean {
-			return nil, &diag.SimpleMessageError{
-				Pin:  a.Span().Pin,
-				Text: fmt.Sprintf("incompatible types in binary expression %s and bool", ta),
-			}
+			return nil, errBinaryTypes(fmt.Sprintf("incompatible types in binary expression %s and bool", ta), a, b, exp.Op)
 		}
 
 		v := b.(*Boolean).Val
@@ -997,10 +1038,7 @@ func (x *TypeIndex) deduceBinaryExpType(a, b Exp, op BinOp) (*Type, diag.Error)
 		}
 	}
 
-	return nil, &diag.SimpleMessageError{
-		Pin:  op.Pin,
-		Text: fmt.Sprintf("type %s and %s are incompatible for binary operation", ta, tb),
-	}
+	return nil, errBinaryTypes(fmt.Sprintf("type %s and %s are incompatible for binary operation", ta, tb), a, b, op)
 }
 
 // type checks binary expression and returns its resulting type
@@ -1046,17 +1084,11 @@ func (x *TypeIndex) deduceBinaryExpTypeA(a, b Exp, op BinOp) (*Type, diag.Error)
 			}
 			return x.getBinaryForIntegerType(tb, op)
 		default:
-			return nil, &diag.SimpleMessageError{
-				Pin:  op.Pin,
-				Text: fmt.Sprintf("binary operation on incompatible types %s and %s", ta, tb),
-			}
+			return nil, errBinaryTypes(fmt.Sprintf("binary operation on incompatible types %s and %s", ta, tb), a, b, op)
 		}
 	case tpk.Pointer, tpk.ArrayPointer, tpk.VoidPointer:
 		if ta.Kind != tpk.Nil {
-			return nil, &diag.SimpleMessageError{
-				Pin:  op.Pin,
-				Text: fmt.Sprintf("binary operation on incompatible types %s and %s", ta, tb),
-			}
+			return nil, errBinaryTypes(fmt.Sprintf("binary operation on incompatible types %s and %s", ta, tb), a, b, op)
 		}
 
 		switch op.Kind {
@@ -0,0 +1,16 @@
+package stg
+
+import (
+	"github.com/mebyus/ku/goku/compiler/ast"
+	"github.com/mebyus/ku/goku/compiler/diag"
+)
+
+// translateSizeExp evaluates "#size(T)" expression into static integer
+// which equals byte size of type T under target data model.
+func (s *Scope) translateSizeExp(e ast.Size) (Exp, diag.Error) {
+	typ, err := s.LookupType(e.Exp)
+	if err != nil {
+		return nil, err
+	}
+	return s.Types.MakeInteger(e.Exp.Span().Pin, uint64(typ.Size)), nil
+}
@@ -9,6 +9,10 @@ type String struct {
 
 	Val string
 
+	// Length of string literal in source text. Equals 0 for
+	// evaluated strings.
+	Len uint32
+
 	typ *Type
 }
 
@@ -17,7 +21,7 @@ func (s String) Type() *Type {
 }
 
 func (s String) Span() sm.Span {
-	return sm.Span{Pin: s.Pin}
+	return sm.Span{Pin: s.Pin, Len: s.Len}
 }
 
 func (s String) String() string {
@@ -31,10 +35,11 @@ func (s String) String() string {
 // Explicit interface implementation check.
 var _ Exp = String{}
 
-// MakeInteger create non-negative static unsized integer.
-func (x *TypeIndex) MakeString(pin sm.Pin, v string) String {
+// MakeString create static string from literal with a given span.
+func (x *TypeIndex) MakeString(span sm.Span, v string) String {
 	return String{
-		Pin: pin,
+		Pin: span.Pin,
+		Len: span.Len,
 		Val: v,
 		typ: x.Static.String,
 	}
@@ -13,8 +13,6 @@ func (s *Scope) InitGlobal(types *TypeIndex, gens *GenIndex) {
 	s.Gens = gens
 }
 
-const archPointerSize = 8
-
 func addBuiltinTypes(c *Common) {
 	addBuiltinType(c, "u8", c.Types.Known.U8)
 
@@ -30,7 +28,7 @@ func addBuiltinTypes(c *Common) {
 	addSignedIntegerType(c, "s128", 16)
 
 	addBuiltinType(c, "uint", c.Types.Known.Uint)
-	addSignedIntegerType(c, "sint", archPointerSize)
+	addSignedIntegerType(c, "sint", c.Types.Model.PointerSize)
 
 	addBuiltinType(c, "bool", c.Types.Known.Bool)
 	addUnsignedIntegerType(c, "rune", 4)
@@ -62,6 +60,7 @@ func addUnsignedIntegerType(c *Common, name string, size uint32) {
 	}
 	t := &Type{
 		Size:  size,
+		Align: c.Types.Model.align(size),
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.Integer,
 	}
@@ -76,6 +75,7 @@ func addSignedIntegerType(c *Common, name string, size uint32) {
 	}
 	t := &Type{
 		Size:  size,
+		Align: c.Types.Model.align(size),
 		Flags: TypeFlagBuiltin | TypeFlagSigned,
 		Kind:  tpk.Integer,
 	}
@@ -90,6 +90,7 @@ func addFloatType(c *Common, name string, size uint32) {
 	}
 	t := &Type{
 		Size:  size,
+		Align: c.Types.Model.align(size),
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.Float,
 	}
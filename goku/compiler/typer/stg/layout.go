@@ -0,0 +1,158 @@
+package stg
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mebyus/ku/goku/compiler/enums/tpk"
+)
+
+// DataModel describes sizes and alignments of builtin types on target
+// platform. Layout of composite types is derived from their elements
+// according to C rules, thus values can be passed to C code as is.
+type DataModel struct {
+	// Target name as used in command line options.
+	Name string
+
+	// Size of pointers, references, uint and sint.
+	PointerSize uint32
+
+	// Maximum alignment of builtin types. Types with greater natural
+	// alignment (for example u128) are aligned to this value instead.
+	MaxAlign uint32
+}
+
+var (
+	AMD64 = &DataModel{Name: "amd64", PointerSize: 8, MaxAlign: 16}
+	ARM64 = &DataModel{Name: "arm64", PointerSize: 8, MaxAlign: 16}
+	I386  = &DataModel{Name: "386", PointerSize: 4, MaxAlign: 4}
+)
+
+// DefaultDataModel is used when data model is not specified explicitly.
+var DefaultDataModel = AMD64
+
+var dataModels = []*DataModel{AMD64, ARM64, I386}
+
+// LookupDataModel returns data model by its name.
+func LookupDataModel(name string) (*DataModel, error) {
+	for _, m := range dataModels {
+		if m.Name == name {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown data model \"%s\"", name)
+}
+
+// align returns alignment of builtin type with a given size.
+func (m *DataModel) align(size uint32) uint32 {
+	if size == 0 {
+		return 1
+	}
+	return min(size, m.MaxAlign)
+}
+
+// alignUp rounds n up to a multiple of a.
+func alignUp(n, a uint32) uint32 {
+	return (n + a - 1) / a * a
+}
+
+// AlignOf returns alignment of a given type. Static types and types
+// without size have alignment of 1.
+func AlignOf(t *Type) uint32 {
+	if t.Align == 0 {
+		return 1
+	}
+	return t.Align
+}
+
+// layoutStruct assigns field offsets and computes size and alignment
+// of struct with a given fields.
+func layoutStruct(fields []Field) (size uint32, align uint32) {
+	align = 1
+	for i := range fields {
+		f := &fields[i]
+		a := AlignOf(f.Type)
+		size = alignUp(size, a)
+		f.Offset = size
+		size += f.Type.Size
+		align = max(align, a)
+	}
+	return alignUp(size, align), align
+}
+
+// layoutUnion computes size and alignment of union with a given fields.
+// All union fields have zero offset.
+func layoutUnion(fields []Field) (size uint32, align uint32) {
+	align = 1
+	for _, f := range fields {
+		size = max(size, f.Type.Size)
+		align = max(align, AlignOf(f.Type))
+	}
+	return alignUp(size, align), align
+}
+
+// layoutTuple computes element offsets, size and alignment of tuple
+// with a given element types. Tuple is laid out as struct.
+func layoutTuple(types []*Type) (offsets []uint32, size uint32, align uint32) {
+	offsets = make([]uint32, 0, len(types))
+	align = 1
+	for _, t := range types {
+		a := AlignOf(t)
+		size = alignUp(size, a)
+		offsets = append(offsets, size)
+		size += t.Size
+		align = max(align, a)
+	}
+	return offsets, alignUp(size, align), align
+}
+
+// Slot describes placement of struct or union field (or tuple element)
+// inside memory layout of its type.
+type Slot struct {
+	// Field name. For tuple elements equals element index.
+	Name string
+
+	Type *Type
+
+	Offset uint32
+
+	// Number of padding bytes which follow this slot.
+	Padding uint32
+}
+
+// Slots returns memory layout of struct, union or tuple type. Custom types
+// are resolved to their base type. Returns nil for other types.
+func (t *Type) Slots() []Slot {
+	typ := t
+	if typ.Kind == tpk.Custom {
+		typ = typ.Def.(*Custom).Type
+	}
+
+	var slots []Slot
+	switch typ.Kind {
+	case tpk.Struct:
+		for _, f := range typ.Def.(*Struct).Fields {
+			slots = append(slots, Slot{Name: f.Name, Type: f.Type, Offset: f.Offset})
+		}
+	case tpk.Union:
+		for _, f := range typ.Def.(*Union).Fields {
+			slots = append(slots, Slot{Name: f.Name, Type: f.Type})
+		}
+	case tpk.Tuple:
+		tuple := typ.Def.(Tuple)
+		for i, e := range tuple.Types {
+			slots = append(slots, Slot{Name: strconv.Itoa(i), Type: e, Offset: tuple.Offsets[i]})
+		}
+	default:
+		return nil
+	}
+
+	for i := range slots {
+		end := typ.Size
+		if typ.Kind != tpk.Union && i+1 < len(slots) {
+			end = slots[i+1].Offset
+		}
+		slots[i].Padding = end - slots[i].Offset - slots[i].Type.Size
+	}
+	return slots
+}
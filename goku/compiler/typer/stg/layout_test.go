@@ -0,0 +1,153 @@
+package stg
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mebyus/ku/goku/compiler/enums/tpk"
+)
+
+func makeInt(m *DataModel, size uint32) *Type {
+	return &Type{
+		Size:  size,
+		Align: m.align(size),
+		Kind:  tpk.Integer,
+	}
+}
+
+func TestLayoutStruct(t *testing.T) {
+	tests := []struct {
+		name  string
+		model *DataModel
+
+		// field sizes, all fields are integers
+		sizes []uint32
+
+		offsets []uint32
+		size    uint32
+		align   uint32
+	}{
+		{
+			name:    "1 single field",
+			model:   AMD64,
+			sizes:   []uint32{4},
+			offsets: []uint32{0},
+			size:    4,
+			align:   4,
+		},
+		{
+			name:    "2 padding between fields",
+			model:   AMD64,
+			sizes:   []uint32{1, 8, 2},
+			offsets: []uint32{0, 8, 16},
+			size:    24,
+			align:   8,
+		},
+		{
+			name:    "3 no padding",
+			model:   AMD64,
+			sizes:   []uint32{8, 4, 2, 1, 1},
+			offsets: []uint32{0, 8, 12, 14, 15},
+			size:    16,
+			align:   8,
+		},
+		{
+			name:    "4 tail padding",
+			model:   AMD64,
+			sizes:   []uint32{4, 1},
+			offsets: []uint32{0, 4},
+			size:    8,
+			align:   4,
+		},
+		{
+			name:    "5 max alignment",
+			model:   I386,
+			sizes:   []uint32{1, 8, 16},
+			offsets: []uint32{0, 4, 12},
+			size:    28,
+			align:   4,
+		},
+		{
+			name:    "6 u128",
+			model:   AMD64,
+			sizes:   []uint32{1, 16},
+			offsets: []uint32{0, 16},
+			size:    32,
+			align:   16,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []Field
+			for _, size := range tt.sizes {
+				fields = append(fields, Field{Type: makeInt(tt.model, size)})
+			}
+
+			size, align := layoutStruct(fields)
+			var offsets []uint32
+			for _, f := range fields {
+				offsets = append(offsets, f.Offset)
+			}
+			if !reflect.DeepEqual(offsets, tt.offsets) {
+				t.Errorf("layoutStruct() offsets = %v, want %v", offsets, tt.offsets)
+			}
+			if size != tt.size {
+				t.Errorf("layoutStruct() size = %d, want %d", size, tt.size)
+			}
+			if align != tt.align {
+				t.Errorf("layoutStruct() align = %d, want %d", align, tt.align)
+			}
+
+			// tuple with the same element types has identical layout
+			var types []*Type
+			for _, f := range fields {
+				types = append(types, f.Type)
+			}
+			offsets, size, align = layoutTuple(types)
+			if !reflect.DeepEqual(offsets, tt.offsets) || size != tt.size || align != tt.align {
+				t.Errorf("layoutTuple() = (%v, %d, %d), want (%v, %d, %d)",
+					offsets, size, align, tt.offsets, tt.size, tt.align)
+			}
+		})
+	}
+}
+
+func TestType_Slots(t *testing.T) {
+	m := AMD64
+	u8 := makeInt(m, 1)
+	u16 := makeInt(m, 2)
+	u64 := makeInt(m, 8)
+
+	fields := []Field{
+		{Name: "a", Type: u8},
+		{Name: "b", Type: u64},
+		{Name: "c", Type: u16},
+	}
+	size, align := layoutStruct(fields)
+	s := &Type{Def: &Struct{Fields: fields}, Size: size, Align: align, Kind: tpk.Struct}
+
+	want := []Slot{
+		{Name: "a", Type: u8, Offset: 0, Padding: 7},
+		{Name: "b", Type: u64, Offset: 8, Padding: 0},
+		{Name: "c", Type: u16, Offset: 16, Padding: 6},
+	}
+	got := s.Slots()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Slots() = %+v, want %+v", got, want)
+	}
+
+	fields = []Field{
+		{Name: "a", Type: u16},
+		{Name: "b", Type: u64},
+	}
+	size, align = layoutUnion(fields)
+	u := &Type{Def: &Union{Fields: fields}, Size: size, Align: align, Kind: tpk.Union}
+	want = []Slot{
+		{Name: "a", Type: u16, Offset: 0, Padding: 6},
+		{Name: "b", Type: u64, Offset: 0, Padding: 0},
+	}
+	got = u.Slots()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Slots() = %+v, want %+v", got, want)
+	}
+}
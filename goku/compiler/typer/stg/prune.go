@@ -7,17 +7,49 @@ import (
 	"github.com/mebyus/ku/goku/compiler/enums/smk"
 )
 
-// Prune tree of symbols starting from given nodes.
-func Prune(symbols []*Symbol) {
+// Prune tree of symbols starting from given nodes. Code symbols (functions,
+// methods, tests and unit-level variables) of the given units which cannot be
+// reached from roots are marked for skip. Skip flag is cleared on reachable ones,
+// since unit-level usage analysis done by typer knows nothing about the roots.
+//
+// Returns number of symbols reachable from roots.
+func Prune(units []*Unit, roots []*Symbol) int {
 	var p SymWalker
 	p.init()
 
-	for _, s := range symbols {
+	for _, s := range roots {
 		p.add(s)
 	}
 	p.walk()
 
-	fmt.Printf("tree contains %d symbol(s)\n", len(p.visited))
+	for _, u := range units {
+		for _, s := range u.Scope.Symbols {
+			if !isCodeSymbol(s) {
+				continue
+			}
+
+			_, ok := p.visited[s]
+			if ok {
+				s.ClearSkip()
+			} else {
+				s.MarkSkip()
+			}
+		}
+	}
+
+	return len(p.visited)
+}
+
+// Returns true for symbols which produce code or data in compiled program.
+func isCodeSymbol(s *Symbol) bool {
+	switch s.Kind {
+	case smk.Fun, smk.Method, smk.Test:
+		return true
+	case smk.Var:
+		return s.Scope.Kind == sck.Unit
+	default:
+		return false
+	}
 }
 
 type SymWalker struct {
@@ -89,7 +121,7 @@ func (n *Inspector) init() {
 }
 
 func (n *Inspector) add(s *Symbol) {
-	if s.Kind == smk.Fun || s.Kind == smk.Method || (s.Kind == smk.Var && s.Scope.Kind == sck.Unit) {
+	if isCodeSymbol(s) {
 		n.set[s] = struct{}{}
 	}
 }
@@ -110,7 +142,11 @@ func (n *Inspector) take() []*Symbol {
 // Result can include itself for recursive symbols.
 func (n *Inspector) GetUsedSymbols(symbol *Symbol) []*Symbol {
 	switch symbol.Kind {
-	case smk.Fun, smk.Method:
+	case smk.Fun, smk.Method, smk.Test:
+		if symbol.IsStub() {
+			// stubs do not have a body
+			return nil
+		}
 		n.inspectFun(symbol.Def.(*Fun))
 	case smk.Var:
 		// do nothing
@@ -151,6 +187,9 @@ func (n *Inspector) inspectStatement(stm Statement) {
 	case *Assign:
 		n.inspectExp(s.Exp)
 		n.inspectExp(s.Target)
+	case *OpAssign:
+		n.inspectExp(s.Exp)
+		n.inspectExp(s.Target)
 	case *If:
 		n.inspectIf(s)
 	case *Invoke:
@@ -164,6 +203,16 @@ func (n *Inspector) inspectStatement(stm Statement) {
 		n.inspectBlock(&s.Body)
 	case *Must:
 		n.inspectExp(s.Exp)
+	case *Panic:
+		n.inspectExp(s.Exp)
+	case *ForRange:
+		if s.Start != nil {
+			n.inspectExp(s.Start)
+		}
+		n.inspectExp(s.End)
+		n.inspectBlock(&s.Body)
+	case *MatchInteger:
+		n.inspectMatch(s)
 	default:
 		panic(fmt.Sprintf("unexpected (%T) statement", s))
 	}
@@ -180,9 +229,21 @@ func (n *Inspector) inspectIf(f *If) {
 	}
 }
 
+func (n *Inspector) inspectMatch(m *MatchInteger) {
+	n.inspectExp(m.Exp)
+	for _, c := range m.Cases {
+		n.inspectExpList(c.List)
+		n.inspectBlock(&c.Body)
+	}
+
+	if m.Else != nil {
+		n.inspectBlock(m.Else)
+	}
+}
+
 func (n *Inspector) inspectExp(exp Exp) {
 	switch e := exp.(type) {
-	case *Integer, *String, *Nil, *Rune, *Boolean:
+	case *Integer, String, *Nil, *Rune, *Boolean:
 		// do nothing
 	case *SymExp:
 		n.add(e.Symbol)
@@ -197,11 +258,50 @@ func (n *Inspector) inspectExp(exp Exp) {
 		n.inspectExp(e.B)
 	case *DerefSelectField:
 		n.inspectExp(e.Exp)
+	case *SelectField:
+		n.inspectExp(e.Exp)
+	case *Unary:
+		n.inspectExp(e.Exp)
+	case *BoolExp:
+		n.inspectExp(e.Exp)
+	case *SelectSpanLen:
+		n.inspectExp(e.Exp)
+	case *SelectSpanPtr:
+		n.inspectExp(e.Exp)
+	case *DerefSelectMapNum:
+		n.inspectExp(e.Exp)
+	case *SpanIndex:
+		n.inspectExp(e.Exp)
+		n.inspectExp(e.Index)
+	case *DerefIndex:
+		n.inspectExp(e.Exp)
+		n.inspectExp(e.Index)
+	case *SliceArrayRef:
+		n.inspectExp(e.Exp)
+		n.inspectExp(e.Index)
+	case *SpanSlice:
+		n.inspectExp(e.Exp)
+		n.inspectOptExp(e.Start)
+		n.inspectOptExp(e.End)
+	case *MakeSpan:
+		n.inspectExp(e.Exp)
+		n.inspectOptExp(e.Start)
+		n.inspectExp(e.End)
+	case *BoundMethod:
+		n.add(e.Symbol)
+		n.inspectExp(e.Receiver)
 	default:
 		panic(fmt.Sprintf("unexpected (%T) expression", e))
 	}
 }
 
+func (n *Inspector) inspectOptExp(exp Exp) {
+	if exp == nil {
+		return
+	}
+	n.inspectExp(exp)
+}
+
 func (n *Inspector) inspectExpList(list []Exp) {
 	for _, exp := range list {
 		n.inspectExp(exp)
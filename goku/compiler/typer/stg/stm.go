@@ -34,6 +34,20 @@ type Assign struct {
 	Target Exp
 }
 
+// OpAssign represents operation assignment, for example "a += b".
+// Unlike equivalent simple assignment "a = a + b" assign target
+// expression is evaluated only once.
+type OpAssign struct {
+	// Value which is used as second operand. Always not nil.
+	Exp Exp
+
+	// Assign target. Always not nil.
+	Target Exp
+
+	// Binary operation applied to target and value.
+	Op BinOp
+}
+
 type If struct {
 	// Always has at least 1 element.
 	// First element is the first if branch.
@@ -51,7 +51,7 @@ type Symbol struct {
 
 	// Link name. Not empty only if it differs from standard link name
 	// mangling algorithm.
-	// Link string
+	Link string
 
 	// Source position of symbol origin (where this symbol was declared).
 	Pin sm.Pin
@@ -118,7 +118,11 @@ func (s *Symbol) IsPublic() bool {
 }
 
 func (s *Symbol) IsExport() bool {
-	return s.Flags*SymbolExport != 0
+	return s.Flags&SymbolExport != 0
+}
+
+func (s *Symbol) IsStub() bool {
+	return s.Flags&SymbolStub != 0
 }
 
 func (s *Symbol) IsLocal() bool {
@@ -129,6 +133,10 @@ func (s *Symbol) MarkSkip() {
 	s.Flags |= SymbolSkip
 }
 
+func (s *Symbol) ClearSkip() {
+	s.Flags &^= SymbolSkip
+}
+
 func (s *Symbol) ShouldSkip() bool {
 	return s.Flags&SymbolSkip != 0
 }
@@ -28,6 +28,10 @@ type Type struct {
 	// consecutive elements of this type inside an array.
 	Size uint32
 
+	// Alignment of this type's value in bytes. Equals 0 for static
+	// types, which do not have runtime representation.
+	Align uint32
+
 	// Bit flags with additional type properties. Actual meaning may differ
 	// upon Kind.
 	Flags TypeFlag
@@ -97,6 +101,8 @@ func (t *Type) String() string {
 		return "*" + t.Def.(Pointer).Type.String()
 	case tpk.ArrayPointer:
 		return "[*]" + t.Def.(ArrayPointer).Type.String()
+	case tpk.ArrayRef:
+		return "[&]" + t.Def.(ArrayRef).Type.String()
 	case tpk.Ref:
 		return "&" + t.Def.(Ref).Type.String()
 	case tpk.Span:
@@ -110,6 +116,19 @@ func (t *Type) String() string {
 	case tpk.Map:
 		m := t.Def.(*Map)
 		return "map(" + m.Key.String() + ", " + m.Value.String() + ")"
+	case tpk.Struct:
+		return "struct {" + joinFields(t.Def.(*Struct).Fields) + "}"
+	case tpk.Union:
+		return "union {" + joinFields(t.Def.(*Union).Fields) + "}"
+	case tpk.Tuple:
+		var s string
+		for i, e := range t.Def.(Tuple).Types {
+			if i != 0 {
+				s += ", "
+			}
+			s += e.String()
+		}
+		return "(" + s + ")"
 	default:
 		return fmt.Sprintf("???(%d)", t.Kind)
 	}
@@ -120,6 +139,17 @@ func (t *Type) String() string {
 	return s
 }
 
+func joinFields(fields []Field) string {
+	var s string
+	for i, f := range fields {
+		if i != 0 {
+			s += ", "
+		}
+		s += f.Name + ": " + f.Type.String()
+	}
+	return s
+}
+
 // TypeHash is a pseudo-unique type identifier which depends purely on type definition.
 // Value of type hash must not depend on runtime pointer values of specific types,
 // symbols, order of type definitions or usages.
@@ -287,6 +317,9 @@ func (CapBuf) Kind() tpk.Kind {
 type Tuple struct {
 	// Always not nil.
 	Types []*Type
+
+	// Byte offset of each element inside tuple.
+	Offsets []uint32
 }
 
 // Explicit interface implementation check.
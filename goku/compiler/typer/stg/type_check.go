@@ -33,10 +33,7 @@ func CheckAssign(want *Type, exp Exp) diag.Error {
 			}
 		}
 
-		return &diag.SimpleMessageError{
-			Pin:  exp.Span().Pin,
-			Text: fmt.Sprintf("incompatible types %s and %s", want, typ),
-		}
+		return errIncompatibleType(exp, want, typ)
 	}
 
 	if typ.Kind == tpk.Integer && want.Kind == tpk.Integer {
@@ -58,9 +55,57 @@ func CheckAssign(want *Type, exp Exp) diag.Error {
 		return nil
 	}
 
-	return &diag.SimpleMessageError{
-		Pin:  exp.Span().Pin,
+	return errIncompatibleType(exp, want, typ)
+}
+
+// errIncompatibleType creates diagnostic for expression which type
+// does not match the expected one.
+func errIncompatibleType(exp Exp, want, typ *Type) diag.Error {
+	return &diag.Diagnostic{
 		Text: fmt.Sprintf("incompatible types %s and %s", want, typ),
+		Primary: diag.Label{
+			Text: fmt.Sprintf("has type %s", typ),
+			Span: exp.Span(),
+		},
+		Notes: []string{fmt.Sprintf("expected type %s", want)},
+		Code:  diag.CodeIncompatibleType,
+	}
+}
+
+// CheckOpAssign checks that binary operation from operation assignment
+// can be applied to assign target and value. Result of the operation
+// must have the same type as target.
+func (x *TypeIndex) CheckOpAssign(target, exp Exp, op BinOp) diag.Error {
+	typ, err := x.deduceBinaryExpType(target, exp, op)
+	if err != nil {
+		return err
+	}
+	want := target.Type()
+	if typ != want {
+		return errIncompatibleType(exp, want, typ)
+	}
+	return nil
+}
+
+// errBinaryTypes creates diagnostic for binary operation on operands
+// with incompatible types.
+func errBinaryTypes(text string, a, b Exp, op BinOp) diag.Error {
+	return &diag.Diagnostic{
+		Text: text,
+		Primary: diag.Label{
+			Span: sm.Span{Pin: op.Pin, Len: uint32(len(op.Kind.String()))},
+		},
+		Secondary: []diag.Label{
+			{
+				Text: fmt.Sprintf("has type %s", a.Type()),
+				Span: a.Span(),
+			},
+			{
+				Text: fmt.Sprintf("has type %s", b.Type()),
+				Span: b.Span(),
+			},
+		},
+		Code: diag.CodeBinaryTypes,
 	}
 }
 
@@ -13,6 +13,10 @@ import (
 )
 
 type TypeIndex struct {
+	// Data model of compilation target. Determines sizes and
+	// alignments of builtin types.
+	Model *DataModel
+
 	Static StaticTypes
 
 	Known KnownTypes
@@ -145,62 +149,75 @@ type KnownTypes struct {
 	Error *Type
 }
 
-func (t *KnownTypes) Init() {
+func (t *KnownTypes) Init(m *DataModel) {
+	p := m.PointerSize
+
 	t.Void = &Type{
 		Size:  0,
+		Align: 1,
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.Void,
 	}
 	t.VoidPointer = &Type{
-		Size:  archPointerSize,
+		Size:  p,
+		Align: p,
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.VoidPointer,
 	}
 	t.VoidRef = &Type{
-		Size:  archPointerSize,
+		Size:  p,
+		Align: p,
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.VoidRef,
 	}
 	t.U8 = &Type{
 		Size:  1,
+		Align: 1,
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.Integer,
 	}
 	t.Uint = &Type{
-		Size:  archPointerSize,
+		Size:  p,
+		Align: p,
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.Integer,
 	}
 	t.Str = &Type{
-		Size:  2 * archPointerSize,
+		Size:  2 * p,
+		Align: p,
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.String,
 	}
 	t.Bool = &Type{
 		Size:  1,
+		Align: 1,
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.Boolean,
 	}
 	t.SpanU8 = &Type{
-		Def:  Span{Type: t.U8},
-		Size: 2 * archPointerSize,
-		Kind: tpk.Span,
+		Def:   Span{Type: t.U8},
+		Size:  2 * p,
+		Align: p,
+		Kind:  tpk.Span,
 	}
 	t.ErrId = &Type{
-		Size:  archPointerSize,
+		Size:  p,
+		Align: p,
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.ErrId,
 	}
 	t.Error = &Type{
-		Size:  2 * archPointerSize,
+		Size:  2 * p,
+		Align: p,
 		Flags: TypeFlagBuiltin,
 		Kind:  tpk.Error,
 	}
 }
 
-func (x *TypeIndex) Init() {
+func (x *TypeIndex) Init(m *DataModel) {
+	x.Model = m
 	x.Static.Init()
-	x.Known.Init()
+	x.Known.Init(m)
 
 	x.Spans = make(map[*Type]*Type)
 	x.CapBufs = make(map[*Type]*Type)
@@ -267,10 +284,7 @@ func (s *Scope) lookupTypeName(p ast.TypeName) (*Type, diag.Error) {
 
 	symbol := s.Lookup(name)
 	if symbol == nil {
-		return nil, &diag.SimpleMessageError{
-			Pin:  pin,
-			Text: fmt.Sprintf("type name \"%s\" refers to undefined symbol", name),
-		}
+		return nil, diag.UndefinedSymbol("type name", name, pin)
 	}
 	if symbol.Kind != smk.Type {
 		return nil, &diag.SimpleMessageError{
@@ -286,10 +300,7 @@ func (s *Scope) lookupTypeFullName(p ast.TypeFullName) (*Type, diag.Error) {
 	iname := p.Import.Str
 	m := s.Lookup(iname)
 	if m == nil {
-		return nil, &diag.SimpleMessageError{
-			Pin:  p.Name.Pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", iname),
-		}
+		return nil, diag.UndefinedSymbol("name", iname, p.Import.Pin)
 	}
 	if m.Kind != smk.Import {
 		return nil, &diag.SimpleMessageError{
@@ -302,10 +313,7 @@ func (s *Scope) lookupTypeFullName(p ast.TypeFullName) (*Type, diag.Error) {
 	name := p.Name.Str
 	symbol := unit.Scope.Lookup(name)
 	if symbol == nil {
-		return nil, &diag.SimpleMessageError{
-			Pin:  p.Name.Pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", name), // TODO: error text with unit name
-		}
+		return nil, diag.UndefinedSymbol("name", name, p.Name.Pin) // TODO: error text with unit name
 	}
 	if symbol.Kind != smk.Type {
 		return nil, &diag.SimpleMessageError{
@@ -357,9 +365,10 @@ func (s *Scope) lookupArray(a ast.Array) (*Type, diag.Error) {
 		return typ, nil
 	}
 	typ = &Type{
-		Def:  def,
-		Size: t.Size * uint32(size),
-		Kind: tpk.Array,
+		Def:   def,
+		Size:  t.Size * uint32(size),
+		Align: t.Align,
+		Kind:  tpk.Array,
 	}
 	s.Types.Arrays[def] = typ
 
@@ -377,9 +386,10 @@ func (s *Scope) lookupPointer(p ast.Pointer) (*Type, diag.Error) {
 		return typ, nil
 	}
 	typ = &Type{
-		Def:  Pointer{Type: t},
-		Size: archPointerSize,
-		Kind: tpk.Pointer,
+		Def:   Pointer{Type: t},
+		Size:  s.Types.Model.PointerSize,
+		Align: s.Types.Model.PointerSize,
+		Kind:  tpk.Pointer,
 	}
 	s.Types.Pointers[t] = typ
 
@@ -405,9 +415,10 @@ func (s *Scope) lookupArrayRef(p ast.ArrayRef) (*Type, diag.Error) {
 		return typ, nil
 	}
 	typ = &Type{
-		Def:  ArrayRef{Type: t},
-		Size: archPointerSize,
-		Kind: tpk.ArrayRef,
+		Def:   ArrayRef{Type: t},
+		Size:  s.Types.Model.PointerSize,
+		Align: s.Types.Model.PointerSize,
+		Kind:  tpk.ArrayRef,
 	}
 	s.Types.ArrayRefs[t] = typ
 
@@ -443,9 +454,10 @@ func (s *Scope) lookupCapBuf(p ast.CapBuf) (*Type, diag.Error) {
 		return typ, nil
 	}
 	typ = &Type{
-		Def:  CapBuf{Type: t},
-		Size: 3 * archPointerSize,
-		Kind: tpk.CapBuf,
+		Def:   CapBuf{Type: t},
+		Size:  3 * s.Types.Model.PointerSize,
+		Align: s.Types.Model.PointerSize,
+		Kind:  tpk.CapBuf,
 	}
 	s.Types.CapBufs[t] = typ
 
@@ -465,7 +477,6 @@ func (s *Scope) lookupFields(list []ast.Field) (string, []Field, diag.Error) {
 		fields = append(fields, Field{
 			Name: name,
 			Type: t,
-			// TODO: calculate offset
 		})
 		n += len(name)
 	}
@@ -502,10 +513,12 @@ func (s *Scope) lookupStruct(p ast.Struct) (*Type, diag.Error) {
 			return t, nil
 		}
 	}
+	size, align := layoutStruct(fields)
 	typ := &Type{
-		// TODO: calculate size
-		Def:  &Struct{Fields: fields},
-		Kind: tpk.Struct,
+		Def:   &Struct{Fields: fields},
+		Size:  size,
+		Align: align,
+		Kind:  tpk.Struct,
 	}
 	s.Types.Structs[key] = append(s.Types.Structs[key], typ)
 	return typ, nil
@@ -528,24 +541,22 @@ func (s *Scope) lookupUnion(u ast.Union) (*Type, diag.Error) {
 		}
 	}
 
-	var size uint32
-	for _, f := range fields {
-		size += f.Type.Size
-	}
-	if size == 0 {
-		panic("not implemented")
-	}
-
+	size, align := layoutUnion(fields)
 	typ := &Type{
-		Size: size,
-		Def:  &Union{Fields: fields},
-		Kind: tpk.Union,
+		Def:   &Union{Fields: fields},
+		Size:  size,
+		Align: align,
+		Kind:  tpk.Union,
 	}
 	s.Types.Unions[key] = append(s.Types.Unions[key], typ)
 	return typ, nil
 }
 
 func (s *Scope) lookupMap(m ast.Map) (*Type, diag.Error) {
+	if !supportMaps {
+		return nil, diag.Unsupported("map type", m.Span())
+	}
+
 	key, err := s.LookupType(m.Key)
 	if err != nil {
 		return nil, err
@@ -586,10 +597,7 @@ func (s *Scope) createEnumType(enum ast.Enum) (*Type, diag.Error) {
 	pin := enum.Base.Name.Pin
 	symbol := s.Lookup(name)
 	if symbol == nil {
-		return nil, &diag.SimpleMessageError{
-			Pin:  pin,
-			Text: fmt.Sprintf("type name \"%s\" refers to undefined symbol", name),
-		}
+		return nil, diag.UndefinedSymbol("type name", name, pin)
 	}
 	if symbol.Kind != smk.Type {
 		return nil, &diag.SimpleMessageError{
@@ -627,6 +635,7 @@ func (s *Scope) createEnumType(enum ast.Enum) (*Type, diag.Error) {
 		Def:   def,
 		Flags: typ.Flags & TypeFlagSigned,
 		Size:  typ.Size,
+		Align: typ.Align,
 		Kind:  tpk.Enum,
 	}
 	if len(enum.Entries) == 0 {
@@ -717,10 +726,12 @@ func (x *TypeIndex) getTuple(types []*Type) *Type {
 	if ok {
 		return typ
 	}
+	offsets, size, align := layoutTuple(types)
 	typ = &Type{
-		// TODO: calculate size
-		Def:  Tuple{Types: types},
-		Kind: tpk.Tuple,
+		Def:   Tuple{Types: types, Offsets: offsets},
+		Size:  size,
+		Align: align,
+		Kind:  tpk.Tuple,
 	}
 	x.Tuples[key] = typ
 	return typ
@@ -732,9 +743,10 @@ func (x *TypeIndex) getRef(t *Type) *Type {
 		return typ
 	}
 	typ = &Type{
-		Def:  Ref{Type: t},
-		Size: archPointerSize,
-		Kind: tpk.Ref,
+		Def:   Ref{Type: t},
+		Size:  x.Model.PointerSize,
+		Align: x.Model.PointerSize,
+		Kind:  tpk.Ref,
 	}
 	x.Refs[t] = typ
 	return typ
@@ -746,9 +758,10 @@ func (x *TypeIndex) getSpan(t *Type) *Type {
 		return typ
 	}
 	typ = &Type{
-		Def:  Span{Type: t},
-		Size: 2 * archPointerSize,
-		Kind: tpk.Span,
+		Def:   Span{Type: t},
+		Size:  2 * x.Model.PointerSize,
+		Align: x.Model.PointerSize,
+		Kind:  tpk.Span,
 	}
 	x.Spans[t] = typ
 	return typ
@@ -760,9 +773,10 @@ func (x *TypeIndex) getArrayPointer(t *Type) *Type {
 		return typ
 	}
 	typ = &Type{
-		Def:  ArrayPointer{Type: t},
-		Size: archPointerSize,
-		Kind: tpk.ArrayPointer,
+		Def:   ArrayPointer{Type: t},
+		Size:  x.Model.PointerSize,
+		Align: x.Model.PointerSize,
+		Kind:  tpk.ArrayPointer,
 	}
 	x.ArrayPointers[t] = typ
 	return typ
@@ -7,6 +7,10 @@ import (
 	"github.com/mebyus/ku/goku/compiler/enums/tpk"
 )
 
+// Maps are fully typed, but generated code does not have runtime hash table
+// implementation yet. Until then usage of map types is rejected upon lookup.
+const supportMaps = false
+
 type Map struct {
 	mapkv
 
@@ -36,9 +40,11 @@ func (m *Map) getMethod(name string) *Symbol {
 }
 
 func (x *TypeIndex) newMapType(kv mapkv) *Type {
+	// map value is a handle to runtime hash table
 	typ := &Type{
-		// TODO: calculate size
-		Kind: tpk.Map,
+		Size:  x.Model.PointerSize,
+		Align: x.Model.PointerSize,
+		Kind:  tpk.Map,
 	}
 	r := x.getRef(typ)
 
@@ -150,17 +150,18 @@ func (t *Typer) Translate(texts []*ast.Text) diag.Error {
 	t.Alloc(texts)
 	t.init()
 
-	err := t.Index()
-	if err != nil {
-		return err
-	}
-
-	err = t.Scan()
-	if err != nil {
-		return err
-	}
+	// index errors do not prevent scanning symbols which were
+	// indexed successfully, thus all errors are gathered in one report
+	t.Index()
+	t.Scan()
+	return t.Err()
+}
 
-	return nil
+// Err returns all errors reported during translation sorted by
+// position in source code. Returns nil if there were no errors.
+func (t *Typer) Err() diag.Error {
+	report := diag.Report{Errors: t.errors}
+	return report.Err()
 }
 
 func (t *Typer) init() {
@@ -197,11 +198,7 @@ func (t *Typer) Index() diag.Error {
 	// t.index
 	t.indexMethods()
 
-	if len(t.errors) != 0 {
-		// need to refactor this to enable reporting multiple errors at once
-		return t.errors[0]
-	}
-	return nil
+	return t.Err()
 }
 
 func (t *Typer) Scan() diag.Error {
@@ -224,9 +221,5 @@ func (t *Typer) Scan() diag.Error {
 		t.scanVarSymbols()
 	}
 
-	if len(t.errors) != 0 {
-		// need to refactor this to enable reporting multiple errors at once
-		return t.errors[0]
-	}
-	return nil
+	return t.Err()
 }
@@ -236,10 +236,7 @@ func (t *Typer) scanSymbolExp(sym ast.Symbol) diag.Error {
 	name := sym.Name
 	s := t.unit.Scope.Lookup(name)
 	if s == nil {
-		return &diag.SimpleMessageError{
-			Pin:  sym.Pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", name),
-		}
+		return diag.UndefinedSymbol("name", name, sym.Pin)
 	}
 	if s.Kind != smk.Const {
 		return &diag.SimpleMessageError{
@@ -288,10 +285,7 @@ func (t *Typer) scanTypeFullName(p ast.TypeFullName) diag.Error {
 	iname := p.Import.Str
 	m := t.unit.Scope.Lookup(iname)
 	if m == nil {
-		return &diag.SimpleMessageError{
-			Pin:  p.Name.Pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", iname),
-		}
+		return diag.UndefinedSymbol("name", iname, p.Import.Pin)
 	}
 	if m.Kind != smk.Import {
 		return &diag.SimpleMessageError{
@@ -304,10 +298,7 @@ func (t *Typer) scanTypeFullName(p ast.TypeFullName) diag.Error {
 	name := p.Name.Str
 	s := unit.Scope.Lookup(name)
 	if s == nil {
-		return &diag.SimpleMessageError{
-			Pin:  p.Name.Pin,
-			Text: fmt.Sprintf("name \"%s\" refers to undefined symbol", name),
-		}
+		return diag.UndefinedSymbol("name", name, p.Name.Pin)
 	}
 	if s.Kind != smk.Type {
 		return &diag.SimpleMessageError{
@@ -329,10 +320,7 @@ func (t *Typer) scanTypeName(p ast.TypeName) diag.Error {
 	name := p.Name.Str
 	s := t.unit.Scope.Lookup(name)
 	if s == nil {
-		return &diag.SimpleMessageError{
-			Pin:  p.Name.Pin,
-			Text: fmt.Sprintf("type name \"%s\" refers to undefined symbol", name),
-		}
+		return diag.UndefinedSymbol("type name", name, p.Name.Pin)
 	}
 	if s.Kind != smk.Type {
 		return &diag.SimpleMessageError{
@@ -3,6 +3,7 @@ package stg
 import (
 	"sort"
 
+	"github.com/mebyus/ku/goku/compiler/diag"
 	"github.com/mebyus/ku/goku/compiler/enums/sck"
 	"github.com/mebyus/ku/goku/compiler/enums/smk"
 	"github.com/mebyus/ku/goku/compiler/sm"
@@ -24,6 +25,11 @@ type Unit struct {
 	// List of test functions inside this unit.
 	Tests []*Symbol
 
+	// Errors found inside bodies of functions, methods and tests of
+	// prelude unit. Each of them must be reported only if corresponding
+	// symbol is reachable from program roots.
+	Deferred map[*Symbol]diag.Error
+
 	// Unit index assigned by order in which units are discovered
 	// during unit discovery phase (uwalk).
 	DiscoveryIndex uint32
@@ -6,6 +6,7 @@ import (
 	"github.com/mebyus/ku/goku/compiler/ast"
 	"github.com/mebyus/ku/goku/compiler/diag"
 	"github.com/mebyus/ku/goku/compiler/enums/aok"
+	"github.com/mebyus/ku/goku/compiler/enums/bok"
 	"github.com/mebyus/ku/goku/compiler/enums/exk"
 	"github.com/mebyus/ku/goku/compiler/enums/sck"
 	"github.com/mebyus/ku/goku/compiler/enums/smk"
@@ -13,26 +14,30 @@ import (
 	"github.com/mebyus/ku/goku/compiler/typer/stg"
 )
 
-func (t *Typer) translateSymbols() diag.Error {
+// translateSymbols translates bodies of all functions, methods and tests.
+// Each body is translated independently of others, thus an error inside
+// one body does not prevent checking the rest of them.
+func (t *Typer) translateSymbols() {
 	for _, f := range t.funs {
-		err := t.translateSymbolBody(f)
-		if err != nil {
-			return err
-		}
+		t.translateSymbol(f)
 	}
 	for _, m := range t.methods {
-		err := t.translateSymbolBody(m)
-		if err != nil {
-			return err
-		}
+		t.translateSymbol(m)
 	}
 	for _, ut := range t.unit.Tests {
-		err := t.translateSymbolBody(ut)
-		if err != nil {
-			return err
-		}
+		t.translateSymbol(ut)
+	}
+}
+
+func (t *Typer) translateSymbol(s *stg.Symbol) {
+	if t.failed[s] || t.unit.Deferred[s] != nil {
+		return
+	}
+
+	err := t.translateSymbolBody(s)
+	if err != nil && !t.deferError(s, err) {
+		t.report(err)
 	}
-	return nil
 }
 
 // get function or method body
@@ -278,8 +283,46 @@ func (t *Typer) translateOpAssign(a ast.Assign) (stg.Statement, diag.Error) {
 		return nil, err
 	}
 
-	// TODO: need separate statement type for this
-	return nil, nil
+	op := stg.BinOp{
+		Pin:  a.Op.Pin,
+		Kind: assignBinOp(a.Op.Kind),
+	}
+	err = t.ctx.Types.CheckOpAssign(target, exp, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stg.OpAssign{
+		Target: target,
+		Exp:    exp,
+		Op:     op,
+	}, nil
+}
+
+// Returns binary operator which corresponds to a given operation assignment.
+func assignBinOp(k aok.Kind) bok.Kind {
+	switch k {
+	case aok.Add:
+		return bok.Add
+	case aok.Sub:
+		return bok.Sub
+	case aok.Mul:
+		return bok.Mul
+	case aok.Div:
+		return bok.Div
+	case aok.Rem:
+		return bok.Mod
+	case aok.And:
+		return bok.BitAnd
+	case aok.Or:
+		return bok.BitOr
+	case aok.LeftShift:
+		return bok.LeftShift
+	case aok.RightShift:
+		return bok.RightShift
+	default:
+		panic(fmt.Sprintf("unexpected %s (=%d) assign operator", k, k))
+	}
 }
 
 func (t *Typer) translateWalrusAssign(a ast.Assign) (stg.Statement, diag.Error) {
@@ -791,7 +834,6 @@ func (t *Typer) translateIf(f ast.If) (stg.Statement, diag.Error) {
 		k += 1
 	}
 
-	const debug = false
 	if k != len(branches) {
 		if debug {
 			fmt.Printf("branch %d/%d alive\n", k, len(branches))
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"github.com/mebyus/ku/goku/compiler/enums/bok"
+	"github.com/mebyus/ku/goku/compiler/enums/uok"
 	"github.com/mebyus/ku/goku/compiler/sm"
 )
 
@@ -69,3 +70,26 @@ type BinOp struct {
 	Pin  sm.Pin
 	Kind bok.Kind
 }
+
+type Unary struct {
+	nodeExp
+
+	Op UnaryOp
+
+	Exp Exp
+}
+
+// UnaryOp represents unary operator inside expression.
+type UnaryOp struct {
+	Pin  sm.Pin
+	Kind uok.Kind
+}
+
+// Paren represents expression enclosed in parenthesis.
+type Paren struct {
+	nodeExp
+
+	Exp Exp
+
+	Pin sm.Pin
+}
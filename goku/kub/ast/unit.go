@@ -67,6 +67,35 @@ type Exe struct {
 	Block
 }
 
+// If represents conditional directive.
+//
+//	if <exp> {
+//		// directives
+//	} else if <exp> {
+//		// directives
+//	} else {
+//		// directives
+//	}
+//
+// Directives from the first clause whose condition evaluates to true are
+// applied. If no condition holds then directives from else block are applied
+// (if it is present).
+type If struct {
+	nodeDir
+
+	// Always has at least one element.
+	Clauses []IfClause
+
+	// Equals nil if there is no else block.
+	Else *Block
+}
+
+type IfClause struct {
+	Exp Exp
+
+	Block
+}
+
 type Unit struct {
 	Dirs []Dir
 }
@@ -25,12 +25,24 @@ type BuildConfig struct {
 	BuildKind bk.Kind
 
 	BuildMode bm.Mode
+
+	// Platform which build produces code for. Empty fields
+	// default to host operating system and architecture.
+	Target eval.Target
+
+	// User-defined variables which are made available to build scripts.
+	Vars map[string]string
+
+	// Optional. Only has effect for test executable builds.
+	// Selects tests to be included into test driver, see selectTests
+	// for matching rules.
+	TestName string
 }
 
-func genUnitsToFile(config *BuildConfig, out string, items []sm.QueueItem) error {
+func genUnitsToFile(config *BuildConfig, out string, items []sm.QueueItem) ([]*sm.Unit, error) {
 	genOut, err := os.Create(out)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer genOut.Close()
 
@@ -38,17 +50,22 @@ func genUnitsToFile(config *BuildConfig, out string, items []sm.QueueItem) error
 }
 
 func populateEnv(config *BuildConfig) {
-	env := eval.NewEnv()
+	env := eval.NewEnv(config.Target)
 	config.Env = env
 
 	env.BuildKind = config.BuildKind
 	env.BuildMode = config.BuildMode
 
 	env.Set("KU.BUILD.KIND.TEST", eval.Integer{Val: uint64(bk.Test)})
+	for name, val := range config.Vars {
+		env.Set(name, eval.String{Val: val})
+	}
 }
 
 // Generate C code for specified units (by their paths in items).
-func genUnits(config *BuildConfig, out io.Writer, items []sm.QueueItem) error {
+// Returns all units which were reached (directly or through imports)
+// during the walk.
+func genUnits(config *BuildConfig, out io.Writer, items []sm.QueueItem) ([]*sm.Unit, error) {
 	populateEnv(config)
 
 	walker := Walker{
@@ -56,7 +73,7 @@ func genUnits(config *BuildConfig, out io.Writer, items []sm.QueueItem) error {
 	}
 	units, err := walker.WalkFrom(items...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	m := make(map[sm.UnitPath]uint32, len(units))
@@ -94,7 +111,7 @@ func genUnits(config *BuildConfig, out io.Writer, items []sm.QueueItem) error {
 	var s graphs.Scout
 	cycle := s.RankOrFindCycle(&g)
 	if cycle != nil {
-		return fmt.Errorf("import cycle: %v", cycle.Nodes)
+		return nil, fmt.Errorf("import cycle: %v", cycle.Nodes)
 	}
 
 	var texts []*sm.Text
@@ -105,7 +122,11 @@ func genUnits(config *BuildConfig, out io.Writer, items []sm.QueueItem) error {
 		}
 	}
 
-	return genTexts(config, out, texts)
+	err = genTexts(config, out, texts)
+	if err != nil {
+		return nil, err
+	}
+	return units, nil
 }
 
 func genTexts(c *BuildConfig, out io.Writer, texts []*sm.Text) error {
@@ -160,6 +181,11 @@ func genTexts(c *BuildConfig, out io.Writer, texts []*sm.Text) error {
 		return nil
 	}
 
+	tests, err = selectTests(tests, c.TestName)
+	if err != nil {
+		return err
+	}
+
 	g.Reset()
 	g.MainTestDriver(tests)
 	_, err = g.WriteTo(out)
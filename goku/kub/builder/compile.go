@@ -1,11 +1,25 @@
 package builder
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mebyus/ku/goku/compiler/cc"
 	"github.com/mebyus/ku/goku/compiler/enums/bk"
+	"github.com/mebyus/ku/goku/compiler/enums/bm"
 	"github.com/mebyus/ku/goku/compiler/sm"
 )
 
 type CompileConfig struct {
+	// Required. Source map pool from which texts were loaded.
+	Pool *sm.Pool
+
+	// Optional. List of additional include directories for C compiler.
+	//
+	// Directories of all C texts are always added to this list, thus
+	// local headers can be included as usual.
 	IncludeDirs []string
 
 	// Optional. Specifies directory to be used as root for storing test executable
@@ -23,10 +37,105 @@ type CompileConfig struct {
 	BuildKind bk.Kind
 }
 
+func (c *CompileConfig) checkAndSetDefaults() error {
+	err := c.BuildKind.Valid()
+	if err != nil {
+		return err
+	}
+	if c.Pool == nil {
+		return errors.New("nil source pool")
+	}
+	for _, dir := range c.IncludeDirs {
+		if strings.TrimSpace(dir) == "" {
+			return errors.New("empty include directory")
+		}
+	}
+
+	if c.OutputRootDir == "" {
+		c.OutputRootDir = defaultOutputRootDir
+	}
+	return nil
+}
+
 // CompileTexts compile one or more Ku or C source text into one object file.
+//
+// Texts are placed into generated C code in the order they are listed.
+// Name of the first text is used for naming intermediate and output files.
 func CompileTexts(c *CompileConfig, texts []*sm.Text) error {
 	if len(texts) == 0 {
-		panic("no texts")
+		return errors.New("no source texts")
 	}
-	return nil
+	err := c.checkAndSetDefaults()
+	if err != nil {
+		return err
+	}
+
+	name := textBaseName(texts[0])
+	codegenOutPath := filepath.Join(c.OutputRootDir, "genc", name+".kubgen.c")
+	err = mkdirForFile(codegenOutPath)
+	if err != nil {
+		return err
+	}
+
+	err = genTextsToFile(&BuildConfig{
+		Pool:      c.Pool,
+		BuildKind: c.BuildKind,
+		BuildMode: bm.Obj,
+	}, codegenOutPath, texts)
+	if err != nil {
+		return err
+	}
+
+	objPath := c.OutputPath
+	if objPath == "" {
+		objPath = filepath.Join(c.OutputRootDir, "obj", name+".o")
+	}
+	err = mkdirForFile(objPath)
+	if err != nil {
+		return err
+	}
+
+	return cc.CompileObj(objPath, codegenOutPath, c.BuildKind, includeDirs(c.IncludeDirs, texts))
+}
+
+func genTextsToFile(config *BuildConfig, out string, texts []*sm.Text) error {
+	genOut, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer genOut.Close()
+
+	populateEnv(config)
+	return genTexts(config, genOut, texts)
+}
+
+// Returns list of include directories which consists of explicitly specified
+// directories followed by directories of C texts. Generated C code is placed
+// in separate directory, so without the latter local includes would break.
+func includeDirs(dirs []string, texts []*sm.Text) []string {
+	list := append([]string(nil), dirs...)
+	set := make(map[string]struct{}, len(dirs))
+	for _, dir := range dirs {
+		set[dir] = struct{}{}
+	}
+
+	for _, text := range texts {
+		if text.Ext != ".c" && text.Ext != ".h" {
+			continue
+		}
+
+		dir := filepath.Dir(text.Path)
+		_, ok := set[dir]
+		if ok {
+			continue
+		}
+		set[dir] = struct{}{}
+		list = append(list, dir)
+	}
+	return list
+}
+
+func textBaseName(text *sm.Text) string {
+	base := filepath.Base(text.Path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
 }
@@ -0,0 +1,51 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/mebyus/ku/goku/compiler/enums/bk"
+	"github.com/mebyus/ku/goku/compiler/sm"
+)
+
+func TestCompileTextsConfigErrors(t *testing.T) {
+	text := sm.NewText("a.ku", []byte("fun foo() {}\n"))
+
+	tests := []struct {
+		name   string
+		config CompileConfig
+		texts  []*sm.Text
+	}{
+		{
+			name:   "1 no texts",
+			config: CompileConfig{Pool: sm.New(), BuildKind: bk.Debug},
+			texts:  nil,
+		},
+		{
+			name:   "2 unspecified build kind",
+			config: CompileConfig{Pool: sm.New()},
+			texts:  []*sm.Text{text},
+		},
+		{
+			name:   "3 nil pool",
+			config: CompileConfig{BuildKind: bk.Debug},
+			texts:  []*sm.Text{text},
+		},
+		{
+			name: "4 empty include directory",
+			config: CompileConfig{
+				Pool:        sm.New(),
+				BuildKind:   bk.Debug,
+				IncludeDirs: []string{"include", " "},
+			},
+			texts: []*sm.Text{text},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CompileTexts(&tt.config, tt.texts)
+			if err == nil {
+				t.Errorf("CompileTexts() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package builder
+
+import (
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/kub/eval"
+)
+
+// Gathers link entries for executable produced from a given module.
+//
+// Resulting list starts with entries of the module itself. Then follow entries
+// of other package modules whose units were reached during the walk
+// (directly or through imports). This way module which wraps C library
+// can declare its link entries once and all executables (including test
+// ones) which use its units will be linked against that library.
+func collectLinks(pkg *eval.Package, mod *eval.Module, units []*sm.Unit) []string {
+	set := sm.NewPathSet()
+	for _, u := range units {
+		set.Add(u.Path)
+	}
+
+	links := append([]string(nil), mod.Links...)
+	for _, m := range pkg.Modules {
+		if m.Name == mod.Name || len(m.Links) == 0 {
+			continue
+		}
+		if moduleReached(set, &m) {
+			links = append(links, m.Links...)
+		}
+	}
+	return links
+}
+
+func moduleReached(set sm.PathSet, m *eval.Module) bool {
+	for _, u := range m.Units {
+		if set.Has(sm.Local(u)) {
+			return true
+		}
+	}
+	return false
+}
@@ -40,6 +40,11 @@ type Config struct {
 
 	// Required.
 	BuildKind bk.Kind
+
+	// Optional. Platform which module is built for.
+	//
+	// Host operating system and architecture are used for empty fields.
+	Target eval.Target
 }
 
 type TestModuleConfig struct {
@@ -49,7 +54,10 @@ type TestModuleConfig struct {
 	// from all gathered tests. All other tests are dropped and only selected one
 	// gets included into test executable.
 	//
-	// Leads to error if there is no test with such name inside the module.
+	// Besides exact test name this field may contain a glob pattern or a name prefix,
+	// in that case all matching tests are selected.
+	//
+	// Leads to error if there is no matching test inside the module.
 	//
 	// Useful when user needs to debug chosen test by launching executable inside debugger.
 	TestName string
@@ -126,11 +134,7 @@ func BuildModule(config *BuildModuleConfig) error {
 		return fmt.Errorf("package has no module \"%s\"", name)
 	}
 	if mod.Main == "" {
-		return buildObjectFromUnits(config, pool, ResolveConfig{
-			RootDir: pkg.RootDir,
-			MainDir: pkg.MainDir,
-			UnitDir: pkg.UnitDir,
-		}, mod.Units)
+		return buildObjectFromUnits(config, pool, pkg, mod.Units)
 	}
 
 	codegenOutPath := config.getCodegenOutPath(name)
@@ -139,10 +143,12 @@ func BuildModule(config *BuildModuleConfig) error {
 		return err
 	}
 
-	err = genUnitsToFile(&BuildConfig{
+	units, err := genUnitsToFile(&BuildConfig{
 		Pool:      pool,
 		BuildKind: config.BuildKind,
 		BuildMode: bm.Exe,
+		Target:    config.Target,
+		Vars:      pkg.Vars,
 		ResolveConfig: ResolveConfig{
 			RootDir: pkg.RootDir,
 			MainDir: pkg.MainDir,
@@ -171,10 +177,10 @@ func BuildModule(config *BuildModuleConfig) error {
 		return err
 	}
 
-	return cc.CompileExe(exePath, codegenOutPath, config.BuildKind)
+	return cc.CompileExe(exePath, codegenOutPath, config.BuildKind, collectLinks(pkg, mod, units))
 }
 
-func buildObjectFromUnits(config *BuildModuleConfig, pool *sm.Pool, resolve ResolveConfig, units []string) error {
+func buildObjectFromUnits(config *BuildModuleConfig, pool *sm.Pool, pkg *eval.Package, units []string) error {
 	name := config.ModuleName
 	codegenOutPath := config.getCodegenOutPath(name)
 	err := mkdirForFile(codegenOutPath)
@@ -182,11 +188,17 @@ func buildObjectFromUnits(config *BuildModuleConfig, pool *sm.Pool, resolve Reso
 		return err
 	}
 
-	err = genUnitsToFile(&BuildConfig{
-		Pool:          pool,
-		BuildKind:     config.BuildKind,
-		BuildMode:     bm.Obj,
-		ResolveConfig: resolve,
+	_, err = genUnitsToFile(&BuildConfig{
+		Pool:      pool,
+		BuildKind: config.BuildKind,
+		BuildMode: bm.Obj,
+		Target:    config.Target,
+		Vars:      pkg.Vars,
+		ResolveConfig: ResolveConfig{
+			RootDir: pkg.RootDir,
+			MainDir: pkg.MainDir,
+			UnitDir: pkg.UnitDir,
+		},
 	},
 		codegenOutPath, makeQueueItems(units))
 	if err != nil {
@@ -204,7 +216,7 @@ func buildObjectFromUnits(config *BuildModuleConfig, pool *sm.Pool, resolve Reso
 		return err
 	}
 
-	return cc.CompileObj(exePath, codegenOutPath, config.BuildKind)
+	return cc.CompileObj(exePath, codegenOutPath, config.BuildKind, nil)
 }
 
 func BuildAndRunModule(config *BuildAndRunModuleConfig) error {
@@ -234,10 +246,12 @@ func BuildAndRunModule(config *BuildAndRunModuleConfig) error {
 		return err
 	}
 
-	err = genUnitsToFile(&BuildConfig{
+	units, err := genUnitsToFile(&BuildConfig{
 		Pool:      pool,
 		BuildKind: config.BuildKind,
 		BuildMode: bm.Exe,
+		Target:    config.Target,
+		Vars:      pkg.Vars,
 		ResolveConfig: ResolveConfig{
 			RootDir: pkg.RootDir,
 			MainDir: pkg.MainDir,
@@ -261,7 +275,7 @@ func BuildAndRunModule(config *BuildAndRunModuleConfig) error {
 		return err
 	}
 
-	err = cc.CompileExe(exePath, codegenOutPath, config.BuildKind)
+	err = cc.CompileExe(exePath, codegenOutPath, config.BuildKind, collectLinks(pkg, mod, units))
 	if err != nil {
 		return err
 	}
@@ -294,10 +308,13 @@ func TestModule(config *TestModuleConfig) error {
 		return err
 	}
 
-	err = genUnitsToFile(&BuildConfig{
+	units, err := genUnitsToFile(&BuildConfig{
 		Pool:      pool,
 		BuildKind: config.BuildKind,
 		BuildMode: bm.TestExe,
+		Target:    config.Target,
+		TestName:  config.TestName,
+		Vars:      pkg.Vars,
 		ResolveConfig: ResolveConfig{
 			RootDir: pkg.RootDir,
 			MainDir: pkg.MainDir,
@@ -314,7 +331,7 @@ func TestModule(config *TestModuleConfig) error {
 		return err
 	}
 
-	err = cc.CompileExe(testExePath, codegenOutPath, config.BuildKind)
+	err = cc.CompileExe(testExePath, codegenOutPath, config.BuildKind, collectLinks(pkg, mod, units))
 	if err != nil {
 		return err
 	}
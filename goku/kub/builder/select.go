@@ -0,0 +1,58 @@
+package builder
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Selects tests from the list according to the given pattern.
+// Empty pattern selects all tests.
+//
+// Pattern is matched against test names in the following order:
+//
+//   - exact name match selects exactly one test
+//   - pattern with glob metacharacters ("*", "?", "[") selects all tests
+//     which match it (see path.Match for syntax)
+//   - otherwise pattern selects all tests whose names start with it
+//
+// Returns error listing available test names if pattern does not select
+// any test.
+func selectTests(tests []string, pattern string) ([]string, error) {
+	if pattern == "" {
+		return tests, nil
+	}
+
+	for _, t := range tests {
+		if t == pattern {
+			return []string{t}, nil
+		}
+	}
+
+	var selected []string
+	if strings.ContainsAny(pattern, "*?[") {
+		for _, t := range tests {
+			ok, err := path.Match(pattern, t)
+			if err != nil {
+				return nil, fmt.Errorf("bad test name pattern \"%s\": %w", pattern, err)
+			}
+			if ok {
+				selected = append(selected, t)
+			}
+		}
+	} else {
+		for _, t := range tests {
+			if strings.HasPrefix(t, pattern) {
+				selected = append(selected, t)
+			}
+		}
+	}
+
+	if len(selected) != 0 {
+		return selected, nil
+	}
+	if len(tests) == 0 {
+		return nil, fmt.Errorf("no test matches \"%s\": module has no tests", pattern)
+	}
+	return nil, fmt.Errorf("no test matches \"%s\", available tests:\n\t%s", pattern, strings.Join(tests, "\n\t"))
+}
@@ -0,0 +1,59 @@
+package builder
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSelectTests(t *testing.T) {
+	all := []string{"parse", "parse_int", "parse_float", "format_int", "hash"}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "1 empty pattern",
+			pattern: "",
+			want:    all,
+		},
+		{
+			name:    "2 exact name",
+			pattern: "parse",
+			want:    []string{"parse"},
+		},
+		{
+			name:    "3 prefix",
+			pattern: "parse_",
+			want:    []string{"parse_int", "parse_float"},
+		},
+		{
+			name:    "4 glob",
+			pattern: "*_int",
+			want:    []string{"parse_int", "format_int"},
+		},
+		{
+			name:    "5 no match",
+			pattern: "encode",
+			wantErr: true,
+		},
+		{
+			name:    "6 bad glob",
+			pattern: "[",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectTests(all, tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectTests() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selectTests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/enums/bok"
+	"github.com/mebyus/ku/goku/compiler/enums/uok"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/kub/ast"
+)
+
+// EvalCond evaluates condition of build script directive.
+// Returns error if expression does not evaluate to boolean value.
+func EvalCond(env *Env, exp ast.Exp) (bool, diag.Error) {
+	value, err := evalScriptExp(env, exp)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := value.(Boolean)
+	if !ok {
+		return false, &diag.SimpleMessageError{
+			Pin:  valuePin(value),
+			Text: fmt.Sprintf("condition must be boolean, not %s", valueKind(value)),
+		}
+	}
+	return b.Val, nil
+}
+
+func evalScriptExp(env *Env, exp ast.Exp) (Value, diag.Error) {
+	switch e := exp.(type) {
+	case nil:
+		panic("nil expression")
+	case ast.String:
+		return String{
+			Val: e.Val,
+			Pin: e.Pin,
+		}, nil
+	case ast.Integer:
+		return Integer{
+			Val: e.Val,
+			Pin: e.Pin,
+		}, nil
+	case ast.Name:
+		return evalName(env, e)
+	case ast.Paren:
+		return evalScriptExp(env, e.Exp)
+	case ast.Unary:
+		return evalScriptUnary(env, e)
+	case ast.Binary:
+		return evalScriptBinary(env, e)
+	default:
+		panic(fmt.Sprintf("unexpected expression (%T)", e))
+	}
+}
+
+// Names listed below are predefined and always available for lookup:
+//
+//	true, false  - boolean constants
+//	build.kind   - build kind string ("debug", "test", "safe", "fast")
+//	build.mode   - build mode string ("obj", "exe", "exe.test")
+//	target.os    - target operating system ("linux")
+//	target.arch  - target architecture ("amd64")
+//
+// All other names are looked up among values stored inside environment.
+func evalName(env *Env, name ast.Name) (Value, diag.Error) {
+	pin := name.Parts[0].Pin
+	s := joinNameParts(name.Parts)
+
+	switch s {
+	case "true":
+		return Boolean{Val: true, Pin: pin}, nil
+	case "false":
+		return Boolean{Val: false, Pin: pin}, nil
+	case "build.kind":
+		return String{Val: env.BuildKind.String(), Pin: pin}, nil
+	case "build.mode":
+		return String{Val: env.BuildMode.String(), Pin: pin}, nil
+	case "target.os":
+		return String{Val: env.OS, Pin: pin}, nil
+	case "target.arch":
+		return String{Val: env.Arch, Pin: pin}, nil
+	}
+
+	v, ok := env.Get(s)
+	if !ok {
+		return nil, &diag.SimpleMessageError{
+			Pin:  pin,
+			Text: fmt.Sprintf("unknown name \"%s\"", s),
+		}
+	}
+	return v, nil
+}
+
+func evalScriptUnary(env *Env, u ast.Unary) (Value, diag.Error) {
+	value, err := evalScriptExp(env, u.Exp)
+	if err != nil {
+		return nil, err
+	}
+
+	b, ok := value.(Boolean)
+	if !ok || u.Op.Kind != uok.Not {
+		return nil, &diag.SimpleMessageError{
+			Pin:  u.Op.Pin,
+			Text: fmt.Sprintf("unary operation \"%s\" not supported on %s value", u.Op.Kind, valueKind(value)),
+		}
+	}
+	return Boolean{
+		Val: !b.Val,
+		Pin: u.Op.Pin,
+	}, nil
+}
+
+func evalScriptBinary(env *Env, bin ast.Binary) (Value, diag.Error) {
+	a, err := evalScriptExp(env, bin.A)
+	if err != nil {
+		return nil, err
+	}
+
+	switch bin.Op.Kind {
+	case bok.And, bok.Or:
+		x, ok := a.(Boolean)
+		if !ok {
+			return nil, &diag.SimpleMessageError{
+				Pin:  bin.Op.Pin,
+				Text: fmt.Sprintf("binary operation \"%s\" not supported on %s value", bin.Op.Kind, valueKind(a)),
+			}
+		}
+		if (bin.Op.Kind == bok.And) != x.Val {
+			// short-circuit evaluation: false && ..., true || ...
+			return x, nil
+		}
+	}
+
+	b, err := evalScriptExp(env, bin.B)
+	if err != nil {
+		return nil, err
+	}
+	if valueKind(a) != valueKind(b) {
+		return nil, &diag.SimpleMessageError{
+			Pin:  bin.Op.Pin,
+			Text: fmt.Sprintf("binary operation \"%s\" on mismatched values (%s, %s)", bin.Op.Kind, valueKind(a), valueKind(b)),
+		}
+	}
+
+	pin := valuePin(a)
+	switch a := a.(type) {
+	case Boolean:
+		b := b.(Boolean)
+		switch bin.Op.Kind {
+		case bok.And, bok.Or:
+			// left side did not short-circuit, thus result is determined by right side
+			return Boolean{Val: b.Val, Pin: pin}, nil
+		case bok.Equal:
+			return Boolean{Val: a.Val == b.Val, Pin: pin}, nil
+		case bok.NotEqual:
+			return Boolean{Val: a.Val != b.Val, Pin: pin}, nil
+		}
+	case String:
+		b := b.(String)
+		switch bin.Op.Kind {
+		case bok.Equal:
+			return Boolean{Val: a.Val == b.Val, Pin: pin}, nil
+		case bok.NotEqual:
+			return Boolean{Val: a.Val != b.Val, Pin: pin}, nil
+		}
+	case Integer:
+		b := b.(Integer)
+		switch bin.Op.Kind {
+		case bok.Equal:
+			return Boolean{Val: a.Val == b.Val, Pin: pin}, nil
+		case bok.NotEqual:
+			return Boolean{Val: a.Val != b.Val, Pin: pin}, nil
+		case bok.Less:
+			return Boolean{Val: a.Val < b.Val, Pin: pin}, nil
+		case bok.LessOrEqual:
+			return Boolean{Val: a.Val <= b.Val, Pin: pin}, nil
+		case bok.Greater:
+			return Boolean{Val: a.Val > b.Val, Pin: pin}, nil
+		case bok.GreaterOrEqual:
+			return Boolean{Val: a.Val >= b.Val, Pin: pin}, nil
+		}
+	default:
+		panic(fmt.Sprintf("unexpected value (%T)", a))
+	}
+
+	return nil, &diag.SimpleMessageError{
+		Pin:  bin.Op.Pin,
+		Text: fmt.Sprintf("binary operation \"%s\" not supported on %s values", bin.Op.Kind, valueKind(a)),
+	}
+}
+
+func valueKind(v Value) string {
+	switch v.(type) {
+	case Integer:
+		return "integer"
+	case String:
+		return "string"
+	case Boolean:
+		return "boolean"
+	default:
+		panic(fmt.Sprintf("unexpected value (%T)", v))
+	}
+}
+
+func valuePin(v Value) sm.Pin {
+	switch v := v.(type) {
+	case Integer:
+		return v.Pin
+	case String:
+		return v.Pin
+	case Boolean:
+		return v.Pin
+	default:
+		panic(fmt.Sprintf("unexpected value (%T)", v))
+	}
+}
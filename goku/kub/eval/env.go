@@ -1,21 +1,68 @@
 package eval
 
 import (
+	"runtime"
+
 	"github.com/mebyus/ku/goku/compiler/enums/bk"
 	"github.com/mebyus/ku/goku/compiler/enums/bm"
 )
 
+// Target describes platform which build produces code for.
+type Target struct {
+	// Operating system name, for example "linux".
+	OS string
+
+	// Architecture name, for example "amd64".
+	Arch string
+}
+
+// HostTarget returns target which matches host operating system and architecture.
+func HostTarget() Target {
+	return Target{
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+	}
+}
+
 type Env struct {
 	BuildKind bk.Kind
 	BuildMode bm.Mode
 
+	// Target operating system name, for example "linux".
+	OS string
+
+	// Target architecture name, for example "amd64".
+	Arch string
+
 	m map[string]Value
 }
 
-func NewEnv() *Env {
-	return &Env{m: make(map[string]Value)}
+// NewEnv creates environment for a given target. Empty target fields
+// are filled from host operating system and architecture.
+func NewEnv(target Target) *Env {
+	host := HostTarget()
+	if target.OS == "" {
+		target.OS = host.OS
+	}
+	if target.Arch == "" {
+		target.Arch = host.Arch
+	}
+
+	return &Env{
+		OS:   target.OS,
+		Arch: target.Arch,
+
+		m: make(map[string]Value),
+	}
 }
 
 func (e *Env) Set(name string, value Value) {
 	e.m[name] = value
 }
+
+// Get returns value stored under a given name.
+// Second return value is false if there is no such value.
+func (e *Env) Get(name string) (Value, bool) {
+	v, ok := e.m[name]
+	return v, ok
+}
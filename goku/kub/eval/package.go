@@ -11,6 +11,11 @@ import (
 type Package struct {
 	Modules []Module
 
+	// User-defined variables from set blocks. Maps variable name
+	// to its value. Build scripts can access these variables by name
+	// inside conditional directives.
+	Vars map[string]string
+
 	MainDir string
 	UnitDir string
 	RootDir string
@@ -93,10 +98,31 @@ func evalSet(pkg *Package, set ast.Set) diag.Error {
 		pkg.MainDir = getStringFromExp(set.Exp)
 	case "root.dir":
 		pkg.RootDir = getStringFromExp(set.Exp)
+	default:
+		if isReservedVarName(name) {
+			return &diag.SimpleMessageError{
+				Pin:  set.Name.Parts[0].Pin,
+				Text: fmt.Sprintf("variable name \"%s\" is reserved", name),
+			}
+		}
+		if pkg.Vars == nil {
+			pkg.Vars = make(map[string]string)
+		}
+		pkg.Vars[name] = getStringFromExp(set.Exp)
 	}
 	return nil
 }
 
+// Returns true if name clashes with predefined names available
+// inside build scripts.
+func isReservedVarName(name string) bool {
+	switch name {
+	case "true", "false":
+		return true
+	}
+	return strings.HasPrefix(name, "build.") || strings.HasPrefix(name, "target.")
+}
+
 func getStringFromExp(exp ast.Exp) string {
 	switch e := exp.(type) {
 	case ast.String:
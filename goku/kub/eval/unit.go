@@ -93,12 +93,31 @@ func (r *Interpreter) dir(dir ast.Dir) diag.Error {
 			return nil
 		}
 		return r.eval(d.Dirs)
+	case ast.If:
+		return r.cond(d)
 	default:
 		panic(fmt.Sprintf("unexpected node %T", d))
 	}
 	return nil
 }
 
+func (r *Interpreter) cond(d ast.If) diag.Error {
+	for _, c := range d.Clauses {
+		ok, err := EvalCond(r.env, c.Exp)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return r.eval(c.Dirs)
+		}
+	}
+
+	if d.Else == nil {
+		return nil
+	}
+	return r.eval(d.Else.Dirs)
+}
+
 // returns true if all strings in slice are unique
 func checkUnique(ss []string) bool {
 	if len(ss) < 2 {
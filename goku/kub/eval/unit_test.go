@@ -0,0 +1,121 @@
+package eval
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mebyus/ku/goku/compiler/enums/bk"
+	"github.com/mebyus/ku/goku/compiler/enums/bm"
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/kub/parser"
+)
+
+func TestEvalUnitIf(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		target  Target
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "1 true condition",
+			src:    `if target.os == "linux" { include "a.ku"; }`,
+			target: Target{OS: "linux", Arch: "amd64"},
+			want:   []string{"a.ku"},
+		},
+		{
+			name:   "2 false condition",
+			src:    `if target.os == "linux" { include "a.ku"; }`,
+			target: Target{OS: "darwin", Arch: "arm64"},
+			want:   nil,
+		},
+		{
+			name:   "3 else",
+			src:    `if target.arch == "amd64" { include "a.ku"; } else { include "b.ku"; }`,
+			target: Target{OS: "linux", Arch: "arm64"},
+			want:   []string{"b.ku"},
+		},
+		{
+			name: "4 else if chain",
+			src: `
+if target.arch == "amd64" {
+	include "a.ku";
+} else if target.arch == "arm64" {
+	include "b.ku";
+} else {
+	include "c.ku";
+}`,
+			target: Target{OS: "linux", Arch: "arm64"},
+			want:   []string{"b.ku"},
+		},
+		{
+			name: "5 nested",
+			src: `
+if target.os == "linux" {
+	include "a.ku";
+	if target.arch == "amd64" && build.kind != "fast" {
+		include "b.ku";
+	} else {
+		include "c.ku";
+	}
+}
+include "d.ku";`,
+			target: Target{OS: "linux", Arch: "amd64"},
+			want:   []string{"a.ku", "b.ku", "d.ku"},
+		},
+		{
+			name:   "6 short circuit skips unknown name",
+			src:    `if false && unknown { include "a.ku"; }`,
+			target: Target{OS: "linux", Arch: "amd64"},
+			want:   nil,
+		},
+		{
+			name:    "7 unknown name",
+			src:     `if target.cpu == "x86" { include "a.ku"; }`,
+			target:  Target{OS: "linux", Arch: "amd64"},
+			wantErr: true,
+		},
+		{
+			name:    "8 unknown name in else if",
+			src:     `if false {} else if foo { include "a.ku"; }`,
+			target:  Target{OS: "linux", Arch: "amd64"},
+			wantErr: true,
+		},
+		{
+			name:    "9 non-boolean condition",
+			src:     `if target.os { include "a.ku"; }`,
+			target:  Target{OS: "linux", Arch: "amd64"},
+			wantErr: true,
+		},
+		{
+			name:    "10 mismatched operands",
+			src:     `if target.os == 1 { include "a.ku"; }`,
+			target:  Target{OS: "linux", Arch: "amd64"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.FromText(sm.NewText("unit.kub", []byte(tt.src)))
+			unit, err := p.Unit()
+			if err != nil {
+				t.Fatalf("Unit() error = %v", err)
+			}
+
+			env := NewEnv(tt.target)
+			env.BuildKind = bk.Debug
+			env.BuildMode = bm.Exe
+			u, err := EvalUnit(env, unit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvalUnit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !slices.Equal(u.Includes, tt.want) {
+				t.Errorf("EvalUnit() includes = %v, want %v", u.Includes, tt.want)
+			}
+		})
+	}
+}
@@ -320,6 +320,16 @@ func (lx *Lexer) other() token.Token {
 			return lx.twoBytesToken(token.NotEqual)
 		}
 		return lx.oneByteToken(token.Not)
+	case '&':
+		if lx.next() == '&' {
+			return lx.twoBytesToken(token.And)
+		}
+		return lx.illegalByteToken()
+	case '|':
+		if lx.next() == '|' {
+			return lx.twoBytesToken(token.Or)
+		}
+		return lx.illegalByteToken()
 	default:
 		return lx.illegalByteToken()
 	}
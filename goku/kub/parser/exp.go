@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/enums/bok"
+	"github.com/mebyus/ku/goku/compiler/enums/uok"
+	"github.com/mebyus/ku/goku/kub/ast"
+	"github.com/mebyus/ku/goku/kub/token"
+)
+
+// Exp parses expression used in conditional directives.
+//
+//	Exp => Primary { BinOp Primary }
+func (p *Parser) Exp() (ast.Exp, diag.Error) {
+	return p.binary(0)
+}
+
+// Parses binary expression using precedence climbing. Only operators
+// with binding power greater than a given one are consumed.
+func (p *Parser) binary(power int) (ast.Exp, diag.Error) {
+	a, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := binOpFromToken(p.peek.Kind)
+		if !ok || op.Power() <= power {
+			return a, nil
+		}
+		pin := p.peek.Pin
+		p.advance() // skip operator
+
+		b, err := p.binary(op.Power())
+		if err != nil {
+			return nil, err
+		}
+		a = ast.Binary{
+			Op: ast.BinOp{
+				Pin:  pin,
+				Kind: op,
+			},
+			A: a,
+			B: b,
+		}
+	}
+}
+
+func (p *Parser) primary() (ast.Exp, diag.Error) {
+	switch p.peek.Kind {
+	case token.String:
+		exp := ast.String{
+			Val: p.peek.Data,
+			Pin: p.peek.Pin,
+		}
+		p.advance() // skip string
+		return exp, nil
+	case token.DecInteger, token.HexInteger:
+		if p.peek.Data != "" {
+			return nil, &diag.SimpleMessageError{
+				Pin:  p.peek.Pin,
+				Text: "integer literal is too large",
+			}
+		}
+		exp := ast.Integer{
+			Val: p.peek.Val,
+			Pin: p.peek.Pin,
+		}
+		p.advance() // skip integer
+		return exp, nil
+	case token.Not:
+		pin := p.peek.Pin
+		p.advance() // skip "!"
+
+		exp, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		return ast.Unary{
+			Op: ast.UnaryOp{
+				Pin:  pin,
+				Kind: uok.Not,
+			},
+			Exp: exp,
+		}, nil
+	case token.LeftParen:
+		pin := p.peek.Pin
+		p.advance() // skip "("
+
+		exp, err := p.Exp()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek.Kind != token.RightParen {
+			return nil, p.unexpected()
+		}
+		p.advance() // skip ")"
+
+		return ast.Paren{
+			Exp: exp,
+			Pin: pin,
+		}, nil
+	default:
+		return p.name()
+	}
+}
+
+func (p *Parser) name() (ast.Name, diag.Error) {
+	var parts []ast.Word
+
+	part, err := p.namePart()
+	if err != nil {
+		return ast.Name{}, err
+	}
+	parts = append(parts, part)
+
+	for p.peek.Kind == token.Period {
+		p.advance() // skip "."
+
+		part, err := p.namePart()
+		if err != nil {
+			return ast.Name{}, err
+		}
+		parts = append(parts, part)
+	}
+
+	return ast.Name{Parts: parts}, nil
+}
+
+func binOpFromToken(k token.Kind) (bok.Kind, bool) {
+	switch k {
+	case token.Equal:
+		return bok.Equal, true
+	case token.NotEqual:
+		return bok.NotEqual, true
+	case token.LeftAngle:
+		return bok.Less, true
+	case token.RightAngle:
+		return bok.Greater, true
+	case token.LessOrEqual:
+		return bok.LessOrEqual, true
+	case token.GreaterOrEqual:
+		return bok.GreaterOrEqual, true
+	case token.And:
+		return bok.And, true
+	case token.Or:
+		return bok.Or, true
+	default:
+		return 0, false
+	}
+}
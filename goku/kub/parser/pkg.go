@@ -73,26 +73,10 @@ func (p *Parser) namePart() (ast.Word, diag.Error) {
 }
 
 func (p *Parser) set() (ast.Set, diag.Error) {
-	var parts []ast.Word
-
-	part, err := p.namePart()
+	name, err := p.name()
 	if err != nil {
 		return ast.Set{}, err
 	}
-	parts = append(parts, part)
-
-	for {
-		if p.peek.Kind != token.Period {
-			break
-		}
-		p.advance() // skip "."
-
-		part, err := p.namePart()
-		if err != nil {
-			return ast.Set{}, err
-		}
-		parts = append(parts, part)
-	}
 
 	if p.peek.Kind != token.Assign {
 		return ast.Set{}, p.unexpected()
@@ -114,7 +98,7 @@ func (p *Parser) set() (ast.Set, diag.Error) {
 	p.advance() // skip ";"
 
 	return ast.Set{
-		Name: ast.Name{Parts: parts},
+		Name: name,
 		Exp:  exp,
 	}, nil
 }
@@ -241,6 +225,12 @@ func (p *Parser) linkEntry() (ast.LinkEntry, diag.Error) {
 	}
 	pin := p.peek.Pin
 	val := p.peek.Data
+	if val == "" {
+		return ast.LinkEntry{}, &diag.SimpleMessageError{
+			Pin:  pin,
+			Text: "empty link entry",
+		}
+	}
 	p.advance() // skip string
 
 	if p.peek.Kind != token.Semicolon {
@@ -42,6 +42,8 @@ func (p *Parser) dir() (ast.Dir, diag.Error) {
 		return p.test()
 	case token.Exe:
 		return p.exe()
+	case token.If:
+		return p.ifDir()
 	default:
 		return nil, p.unexpected()
 	}
@@ -165,6 +167,59 @@ func (p *Parser) exe() (ast.Exe, diag.Error) {
 	return ast.Exe{Block: block}, nil
 }
 
+func (p *Parser) ifDir() (ast.If, diag.Error) {
+	var clauses []ast.IfClause
+
+	clause, err := p.ifClause()
+	if err != nil {
+		return ast.If{}, err
+	}
+	clauses = append(clauses, clause)
+
+	for p.peek.Kind == token.Else {
+		p.advance() // skip "else"
+
+		if p.peek.Kind == token.If {
+			clause, err := p.ifClause()
+			if err != nil {
+				return ast.If{}, err
+			}
+			clauses = append(clauses, clause)
+			continue
+		}
+
+		block, err := p.block()
+		if err != nil {
+			return ast.If{}, err
+		}
+		return ast.If{
+			Clauses: clauses,
+			Else:    &block,
+		}, nil
+	}
+
+	return ast.If{Clauses: clauses}, nil
+}
+
+func (p *Parser) ifClause() (ast.IfClause, diag.Error) {
+	p.advance() // skip "if"
+
+	exp, err := p.Exp()
+	if err != nil {
+		return ast.IfClause{}, err
+	}
+
+	block, err := p.block()
+	if err != nil {
+		return ast.IfClause{}, err
+	}
+
+	return ast.IfClause{
+		Exp:   exp,
+		Block: block,
+	}, nil
+}
+
 func (p *Parser) block() (ast.Block, diag.Error) {
 	if p.peek.Kind != token.LeftCurly {
 		return ast.Block{}, p.unexpected()
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mebyus/ku/goku/compiler/sm"
+	"github.com/mebyus/ku/goku/kub/ast"
+)
+
+// formatDirs produces compact textual representation of parsed directives
+// suitable for comparison in tests. Conditions are not included.
+func formatDirs(dirs []ast.Dir) string {
+	var parts []string
+	for _, dir := range dirs {
+		switch d := dir.(type) {
+		case ast.Include:
+			parts = append(parts, "include("+d.Val+")")
+		case ast.Import:
+			parts = append(parts, "import("+d.Val+")")
+		case ast.Test:
+			parts = append(parts, "test{"+formatDirs(d.Dirs)+"}")
+		case ast.Exe:
+			parts = append(parts, "exe{"+formatDirs(d.Dirs)+"}")
+		case ast.If:
+			var s strings.Builder
+			for i, c := range d.Clauses {
+				if i != 0 {
+					s.WriteString("else ")
+				}
+				s.WriteString("if{" + formatDirs(c.Dirs) + "}")
+			}
+			if d.Else != nil {
+				s.WriteString("else{" + formatDirs(d.Else.Dirs) + "}")
+			}
+			parts = append(parts, s.String())
+		default:
+			panic(fmt.Sprintf("unexpected directive (%T)", d))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParseUnitIf(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "1 single clause",
+			src:  `if target.os == "linux" { include "a.ku"; }`,
+			want: "if{include(a.ku)}",
+		},
+		{
+			name: "2 else",
+			src:  `if true { include "a.ku"; } else { include "b.ku"; }`,
+			want: "if{include(a.ku)}else{include(b.ku)}",
+		},
+		{
+			name: "3 else if chain",
+			src: `
+if target.arch == "amd64" {
+	include "a.ku";
+} else if target.arch == "arm64" {
+	include "b.ku";
+} else {
+	include "c.ku";
+}
+include "d.ku";`,
+			want: "if{include(a.ku)}else if{include(b.ku)}else{include(c.ku)} include(d.ku)",
+		},
+		{
+			name: "4 nested",
+			src: `
+if target.os == "linux" {
+	if target.arch == "amd64" {
+		include "a.ku";
+	} else {
+		include "b.ku";
+	}
+	test {
+		if build.kind != "fast" { include "c.ku"; }
+	}
+}`,
+			want: "if{if{include(a.ku)}else{include(b.ku)} test{if{include(c.ku)}}}",
+		},
+		{
+			name: "5 empty blocks",
+			src:  `if false {} else {}`,
+			want: "if{}else{}",
+		},
+		{
+			name:    "6 missing block",
+			src:     `if true include "a.ku";`,
+			wantErr: true,
+		},
+		{
+			name:    "7 else without if",
+			src:     `else { include "a.ku"; }`,
+			wantErr: true,
+		},
+		{
+			name:    "8 missing condition",
+			src:     `if { include "a.ku"; }`,
+			wantErr: true,
+		},
+		{
+			name:    "9 unclosed block",
+			src:     `if true { include "a.ku";`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := FromText(sm.NewText("unit.kub", []byte(tt.src)))
+			unit, err := p.Unit()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := formatDirs(unit.Dirs)
+			if got != tt.want {
+				t.Errorf("Unit() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -22,6 +22,8 @@ var literal = [...]string{
 	LessOrEqual:    "<=",
 	GreaterOrEqual: ">=",
 	Not:            "!",
+	And:            "&&",
+	Or:             "||",
 	Semicolon:      ";",
 	Period:         ".",
 	Assign:         "=",
@@ -24,6 +24,8 @@ const (
 	LeftAngle      // <
 	RightAngle     // >
 	Not            // !
+	And            // &&
+	Or             // ||
 
 	// Brackets
 
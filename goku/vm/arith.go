@@ -0,0 +1,180 @@
+package vm
+
+import (
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// execArith executes instruction with generic layout.
+func (m *Machine) execArith(op opc.Opcode, lt uint8) (uint64, *RuntimeError) {
+	var data []byte // instruction data
+	var size uint64
+	var b uint64 // second source operand
+	var err *RuntimeError
+	switch opc.Layout(lt) {
+	case opc.RegRegReg:
+		size = 3
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		b, err = m.get(opc.Register(data[2]))
+	case opc.RegRegVal32:
+		size = 6
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		b = uint64(val32(data[2:]))
+	case opc.RegRegVal64:
+		size = 10
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		b = val64(data[2:])
+	default:
+		return 0, &RuntimeError{
+			Code: ErrorBadVariant,
+			Aux:  uint64(lt),
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	a, err := m.get(opc.Register(data[1]))
+	if err != nil {
+		return 0, err
+	}
+
+	var v uint64
+	switch op {
+	case opc.Add:
+		v = a + b
+	case opc.Sub:
+		v = a - b
+	case opc.Mul:
+		v = a * b
+	case opc.Div:
+		if b == 0 {
+			return 0, &RuntimeError{Code: ErrorDivByZero}
+		}
+		v = a / b
+	case opc.Rem:
+		if b == 0 {
+			return 0, &RuntimeError{Code: ErrorDivByZero}
+		}
+		v = a % b
+	case opc.And:
+		v = a & b
+	case opc.Or:
+		v = a | b
+	case opc.Xor:
+		v = a ^ b
+	case opc.Shl:
+		v = a << (b & 63)
+	case opc.Shr:
+		v = a >> (b & 63)
+	case opc.SDiv:
+		if b == 0 {
+			return 0, &RuntimeError{Code: ErrorDivByZero}
+		}
+		v = uint64(int64(a) / int64(b))
+	case opc.SRem:
+		if b == 0 {
+			return 0, &RuntimeError{Code: ErrorDivByZero}
+		}
+		v = uint64(int64(a) % int64(b))
+	case opc.Sar:
+		v = uint64(int64(a) >> (b & 63))
+	default:
+		panic("unexpected opcode " + op.String())
+	}
+
+	err = m.set(opc.Register(data[0]), v)
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// execInc executes Inc or Dec instruction.
+func (m *Machine) execInc(lt uint8, dec bool) (uint64, *RuntimeError) {
+	variant, tiny := opc.DecodeIncLayout(lt)
+
+	var data []byte // instruction data
+	var size uint64
+	var val uint64 // increment value
+	var err *RuntimeError
+	switch variant {
+	case opc.IncTiny:
+		size = 1
+		data, err = m.idata(size)
+		val = uint64(tiny)
+	case opc.IncReg:
+		size = 2
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		val, err = m.get(opc.Register(data[1]))
+	case opc.IncVal32:
+		size = 5
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		val = uint64(val32(data[1:]))
+	case opc.IncVal64:
+		size = 9
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		val = val64(data[1:])
+	default:
+		return 0, &RuntimeError{
+			Code: ErrorBadVariant,
+			Aux:  uint64(variant),
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	r := opc.Register(data[0])
+	v, err := m.get(r)
+	if err != nil {
+		return 0, err
+	}
+	if dec {
+		v -= val
+	} else {
+		v += val
+	}
+	err = m.set(r, v)
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (m *Machine) execClear(lt uint8) (uint64, *RuntimeError) {
+	if opc.Layout(lt) != opc.ClearReg {
+		return 0, &RuntimeError{
+			Code: ErrorBadVariant,
+			Aux:  uint64(lt),
+		}
+	}
+
+	const size = 1
+	data, err := m.idata(size)
+	if err != nil {
+		return 0, err
+	}
+	err = m.set(opc.Register(data[0]), 0)
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
@@ -0,0 +1,30 @@
+package asm
+
+import (
+	"github.com/mebyus/ku/goku/vm/ir"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+func (a *Assembler) binReg(t ir.BinReg) {
+	a.opcode(t.Op)
+	a.layout(opc.RegRegReg)
+	a.register(t.Dest)
+	a.register(t.A)
+	a.register(t.B)
+}
+
+func (a *Assembler) binVal(t ir.BinVal) {
+	a.opcode(t.Op)
+	if t.Val <= 0xFFFFFFFF {
+		a.layout(opc.RegRegVal32)
+		a.register(t.Dest)
+		a.register(t.A)
+		a.val32(uint32(t.Val))
+		return
+	}
+
+	a.layout(opc.RegRegVal64)
+	a.register(t.Dest)
+	a.register(t.A)
+	a.val64(t.Val)
+}
@@ -4,12 +4,19 @@ import (
 	"encoding/binary"
 	"fmt"
 
+	"github.com/mebyus/ku/goku/compiler/sm"
 	"github.com/mebyus/ku/goku/vm/ir"
 	"github.com/mebyus/ku/goku/vm/kvx"
 	"github.com/mebyus/ku/goku/vm/opc"
 )
 
 func Assemble(prog *ir.Program) *kvx.Program {
+	return AssembleText(prog, nil)
+}
+
+// AssembleText is the same as Assemble, but also creates source map
+// when source text of the program is available.
+func AssembleText(prog *ir.Program, text *sm.Text) *kvx.Program {
 	a := Assembler{
 		tab: OffsetsTable{
 			Data:      make([]uint32, len(prog.Data)),
@@ -24,9 +31,64 @@ func Assemble(prog *ir.Program) *kvx.Program {
 	a.patchCalls()
 	a.patchJumps()
 
+	a.prog.EntryPoint = a.tab.Functions[prog.EntryFun]
+	if len(prog.FunNames) != 0 {
+		a.prog.Symbols = a.symbols(prog)
+	}
+	if text != nil && len(a.lines) != 0 {
+		a.prog.Source = a.source(text)
+	}
+
 	return &a.prog
 }
 
+// symbols creates symbol table from program names and offsets table.
+func (a *Assembler) symbols(prog *ir.Program) *kvx.SymbolTable {
+	t := &kvx.SymbolTable{
+		Funs:   make([]kvx.Symbol, 0, len(prog.FunNames)),
+		Labels: make([]kvx.Symbol, 0, len(prog.LabelNames)),
+	}
+	for i, name := range prog.FunNames {
+		t.Funs = append(t.Funs, kvx.Symbol{
+			Name:   name,
+			Offset: a.tab.Functions[i],
+		})
+	}
+	for i, name := range prog.LabelNames {
+		t.Labels = append(t.Labels, kvx.Symbol{
+			Name:   name,
+			Offset: a.tab.Labels[i],
+		})
+	}
+
+	// functions and labels are encoded in order of their definition,
+	// thus offsets are already sorted
+	return t
+}
+
+// source creates source map from instruction pins.
+func (a *Assembler) source(text *sm.Text) *kvx.SourceMap {
+	m := &kvx.SourceMap{
+		Path:  text.Path,
+		Lines: make([]kvx.Line, 0, len(a.lines)),
+	}
+	for _, l := range a.lines {
+		pos := sm.FindTextPos(text.Data, l.pin.Pos().Offset)
+		m.Lines = append(m.Lines, kvx.Line{
+			Offset: l.offset,
+			Line:   pos.Line,
+			Column: pos.Column,
+		})
+	}
+	return m
+}
+
+// instruction offset in text segment with its source position
+type linePin struct {
+	pin    sm.Pin
+	offset uint32
+}
+
 type OffsetsTable struct {
 	// Translates data entry integer name to its offset
 	// in data segment.
@@ -51,6 +113,9 @@ type Assembler struct {
 	tab OffsetsTable
 
 	patch PatchTable
+
+	// Source positions of encoded instructions in order of encoding.
+	lines []linePin
 }
 
 func (a *Assembler) encodeDataSegment(data []ir.DataEntry) {
@@ -76,7 +141,15 @@ func (a *Assembler) encodeFun(f ir.Fun) {
 	a.alignFun()
 	a.tab.Functions[f.Name] = a.textOffset()
 
-	for _, atom := range f.Atoms {
+	hasPins := len(f.Pins) == len(f.Atoms)
+	for i, atom := range f.Atoms {
+		_, place := atom.(ir.Place)
+		if hasPins && !place {
+			a.lines = append(a.lines, linePin{
+				pin:    f.Pins[i],
+				offset: a.textOffset(),
+			})
+		}
 		a.encodeAtom(atom)
 	}
 }
@@ -100,8 +173,12 @@ func (a *Assembler) encodeAtom(atom ir.Atom) {
 		a.tab.Labels[t.Label] = a.textOffset()
 	case ir.CallFun:
 		a.callFun(t)
+	case ir.CallReg:
+		a.callReg(t)
 	case ir.JumpLabel:
 		a.jumpLabel(t)
+	case ir.JumpReg:
+		a.jumpReg(t)
 	case ir.ClearReg:
 		a.clearReg(t)
 	case ir.IncReg:
@@ -112,8 +189,34 @@ func (a *Assembler) encodeAtom(atom ir.Atom) {
 		a.setReg(t)
 	case ir.SetVal:
 		a.setVal(t)
+	case ir.SetData:
+		a.setData(t)
+	case ir.DecReg:
+		a.decReg(t)
+	case ir.DecVal:
+		a.decVal(t)
+	case ir.TestReg:
+		a.testReg(t)
 	case ir.TestVal:
 		a.testVal(t)
+	case ir.PushReg:
+		a.pushReg(t)
+	case ir.PushVal:
+		a.pushVal(t)
+	case ir.PopReg:
+		a.popReg(t)
+	case ir.BinReg:
+		a.binReg(t)
+	case ir.BinVal:
+		a.binVal(t)
+	case ir.LoadReg:
+		a.loadReg(t)
+	case ir.LoadVal:
+		a.loadVal(t)
+	case ir.StoreReg:
+		a.storeReg(t)
+	case ir.StoreVal:
+		a.storeVal(t)
 	default:
 		panic(fmt.Sprintf("unexpected atom (%T)", t))
 	}
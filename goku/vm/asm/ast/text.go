@@ -5,6 +5,9 @@ import "github.com/mebyus/ku/goku/compiler/sm"
 type Text struct {
 	Functions []Fun
 
+	// List of data entries in order of declaration.
+	Data []Data
+
 	// Optional. Contains empty Name field if absent.
 	Entry Entry
 }
@@ -14,3 +17,12 @@ type Entry struct {
 	Name string
 	Pin  sm.Pin
 }
+
+// Data represents data entry declaration in program text.
+type Data struct {
+	// Raw (unescaped) data bytes.
+	Val string
+
+	Name string
+	Pin  sm.Pin
+}
@@ -27,3 +27,10 @@ func (a *Assembler) callFun(t ir.CallFun) {
 
 	a.val32(address)
 }
+
+// encode call instruction with address stored in register.
+func (a *Assembler) callReg(t ir.CallReg) {
+	a.opcode(opc.Call)
+	a.layout(opc.CallReg)
+	a.register(t.Reg)
+}
@@ -26,6 +26,6 @@ func CompileText(text *sm.Text) (*kvx.Program, error) {
 	if err != nil {
 		return nil, err
 	}
-	prog := Assemble(p)
+	prog := AssembleText(p, text)
 	return prog, nil
 }
@@ -0,0 +1,121 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/vm/asm/ast"
+	"github.com/mebyus/ku/goku/vm/ir"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+var binOpcodes = map[string]opc.Opcode{
+	"add": opc.Add,
+	"sub": opc.Sub,
+	"mul": opc.Mul,
+	"div": opc.Div,
+	"rem": opc.Rem,
+	"and": opc.And,
+	"or":  opc.Or,
+	"xor": opc.Xor,
+	"shl": opc.Shl,
+	"shr": opc.Shr,
+
+	"sdiv": opc.SDiv,
+	"srem": opc.SRem,
+	"sar":  opc.Sar,
+}
+
+// Translates instruction with generic layout. Accepts two forms:
+//
+//	add dest, a, b;
+//	add dest, b;     // same as: add dest, dest, b;
+//
+// Last operand may be register or immediate value.
+func (c *Compiler) translateBin(s ast.Instruction) (ir.Atom, diag.Error) {
+	op := binOpcodes[s.Mnemonic]
+
+	var dest, a ast.Register
+	var b ast.Operand
+	switch len(s.Operands) {
+	case 2:
+		r, err := destRegister(s, s.Operands[0])
+		if err != nil {
+			return nil, err
+		}
+		dest = r
+		a = r
+		b = s.Operands[1]
+	case 3:
+		r, err := destRegister(s, s.Operands[0])
+		if err != nil {
+			return nil, err
+		}
+		dest = r
+
+		o := s.Operands[1]
+		r, ok := o.(ast.Register)
+		if !ok {
+			return nil, &diag.SimpleMessageError{
+				Pin:  s.Pin,
+				Text: fmt.Sprintf("bad operand: want register, got (%T)", o),
+			}
+		}
+		a = r
+		b = s.Operands[2]
+	default:
+		return nil, wrongOperandsNumber(s.Pin, len(s.Operands), "2-3")
+	}
+
+	switch o := b.(type) {
+	case ast.Register:
+		return ir.BinReg{
+			Op:   op,
+			Dest: dest.Name,
+			A:    a.Name,
+			B:    o.Name,
+		}, nil
+	case ast.Integer:
+		return ir.BinVal{
+			Op:   op,
+			Dest: dest.Name,
+			A:    a.Name,
+			Val:  o.Val,
+		}, nil
+	default:
+		return nil, &diag.SimpleMessageError{
+			Pin:  s.Pin,
+			Text: fmt.Sprintf("bad operand: source must be register or immediate, got (%T)", o),
+		}
+	}
+}
+
+func (c *Compiler) translateClear(s ast.Instruction) (ir.Atom, diag.Error) {
+	if len(s.Operands) != 1 {
+		return nil, wrongOperandsNumber(s.Pin, len(s.Operands), "1")
+	}
+	r, err := destRegister(s, s.Operands[0])
+	if err != nil {
+		return nil, err
+	}
+	return ir.ClearReg{Reg: r.Name}, nil
+}
+
+// destRegister checks that operand is a register which can be used
+// as instruction destination.
+func destRegister(s ast.Instruction, op ast.Operand) (ast.Register, diag.Error) {
+	r, ok := op.(ast.Register)
+	if !ok {
+		return ast.Register{}, &diag.SimpleMessageError{
+			Pin:  s.Pin,
+			Text: fmt.Sprintf("bad operand: destination must be register, got (%T)", op),
+		}
+	}
+	if r.Name.Special() && r.Name != opc.RegSC {
+		return ast.Register{}, &diag.SimpleMessageError{
+			Pin:  s.Pin,
+			Text: fmt.Sprintf("bad operand: %s instruction cannot have \"%s\" register as destination", s.Mnemonic, r.Name),
+		}
+	}
+	return r, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/compiler/sm"
 	"github.com/mebyus/ku/goku/vm/asm/ast"
 	"github.com/mebyus/ku/goku/vm/ir"
 )
@@ -18,6 +19,7 @@ func Compile(text *ast.Text) (*ir.Program, diag.Error) {
 			Functions: make([]ir.Fun, 0, len(text.Functions)),
 		},
 		funs:   make(map[string]ir.FunName, len(text.Functions)),
+		data:   make(map[string]ir.Data, len(text.Data)),
 		labels: make(map[string]ir.Label), // TODO: we can optimize this allocation after function indexing
 	}
 
@@ -25,6 +27,10 @@ func Compile(text *ast.Text) (*ir.Program, diag.Error) {
 	if err != nil {
 		return nil, err
 	}
+	err = c.translateData(text.Data)
+	if err != nil {
+		return nil, err
+	}
 
 	entry := text.Entry.Name
 	i, ok := c.funs[entry]
@@ -50,6 +56,9 @@ type Compiler struct {
 	// Maps function string name to its integer name.
 	funs map[string]ir.FunName
 
+	// Maps data entry string name to its integer name.
+	data map[string]ir.Data
+
 	// Maps label string name to its integer name.
 	//
 	// Only contains labels for currently translated function.
@@ -67,6 +76,39 @@ func (c *Compiler) indexFunctions(funs []ast.Fun) diag.Error {
 			}
 		}
 		c.funs[name] = ir.FunName(i)
+		c.prog.FunNames = append(c.prog.FunNames, name)
+	}
+	return nil
+}
+
+func (c *Compiler) translateData(data []ast.Data) diag.Error {
+	for i, d := range data {
+		name := d.Name
+		_, ok := c.data[name]
+		if ok {
+			return &diag.SimpleMessageError{
+				Pin:  d.Pin,
+				Text: fmt.Sprintf("data \"%s\" was already declared in program", name),
+			}
+		}
+		_, ok = c.funs[name]
+		if ok {
+			return &diag.SimpleMessageError{
+				Pin:  d.Pin,
+				Text: fmt.Sprintf("data \"%s\" has the same name as function", name),
+			}
+		}
+		if d.Val == "" {
+			return &diag.SimpleMessageError{
+				Pin:  d.Pin,
+				Text: fmt.Sprintf("data \"%s\" is empty", name),
+			}
+		}
+		c.data[name] = ir.Data(i)
+		c.prog.Data = append(c.prog.Data, ir.DataEntry{
+			Val:  d.Val,
+			Name: ir.Data(i),
+		})
 	}
 	return nil
 }
@@ -100,6 +142,7 @@ func (c *Compiler) translateFunction(name ir.FunName, f *ast.Fun) diag.Error {
 			}
 		}
 		c.labels[label] = ir.Label(count)
+		c.prog.LabelNames = append(c.prog.LabelNames, label)
 		count += 1
 	}
 	c.prog.LabelsCount = count
@@ -111,6 +154,7 @@ func (c *Compiler) translateFunction(name ir.FunName, f *ast.Fun) diag.Error {
 			return err
 		}
 		fun.Atoms = append(fun.Atoms, a)
+		fun.Pins = append(fun.Pins, atomPin(atom))
 	}
 	c.prog.Functions = append(c.prog.Functions, fun)
 	return nil
@@ -131,6 +175,8 @@ func (c *Compiler) translateAtom(atom ast.Atom) (ir.Atom, diag.Error) {
 			return ir.Nop{}, nil
 		case "trap":
 			return ir.Trap{}, nil
+		case "syscall":
+			return ir.SysCall{}, nil
 		case "jump":
 			return c.translateJump(a)
 		case "call":
@@ -147,6 +193,15 @@ func (c *Compiler) translateAtom(atom ast.Atom) (ir.Atom, diag.Error) {
 			return c.translateSet(a)
 		case "test":
 			return c.translateTest(a)
+		case "clear":
+			return c.translateClear(a)
+		case "add", "sub", "mul", "div", "rem", "and", "or", "xor", "shl", "shr",
+			"sdiv", "srem", "sar":
+			return c.translateBin(a)
+		case "load":
+			return c.translateLoad(a)
+		case "store":
+			return c.translateStore(a)
 		default:
 			return nil, &diag.SimpleMessageError{
 				Pin:  a.Pin,
@@ -157,3 +212,14 @@ func (c *Compiler) translateAtom(atom ast.Atom) (ir.Atom, diag.Error) {
 		panic(fmt.Sprintf("unexpected atom type (%T)", a))
 	}
 }
+
+func atomPin(atom ast.Atom) sm.Pin {
+	switch a := atom.(type) {
+	case ast.Place:
+		return a.Pin
+	case ast.Instruction:
+		return a.Pin
+	default:
+		panic(fmt.Sprintf("unexpected atom type (%T)", a))
+	}
+}
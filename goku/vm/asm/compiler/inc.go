@@ -53,9 +53,15 @@ func (c *Compiler) translateInc(s ast.Instruction) (ir.Atom, diag.Error) {
 				Source: o.Name,
 			}, nil
 		case ast.Integer:
-			panic("stub")
+			return ir.IncVal{
+				Dest: dest.Name,
+				Val:  o.Val,
+			}, nil
 		default:
-			panic("stub")
+			return nil, &diag.SimpleMessageError{
+				Pin:  s.Pin,
+				Text: fmt.Sprintf("bad operand: source must be register or immediate, got (%T)", o),
+			}
 		}
 	default:
 		return nil, wrongOperandsNumber(s.Pin, len(s.Operands), "1-2")
@@ -84,9 +90,40 @@ func (c *Compiler) translateDec(s ast.Instruction) (ir.Atom, diag.Error) {
 			Val:  1,
 		}, nil
 	case 2:
+		op := s.Operands[0]
+		dest, ok := op.(ast.Register)
+		if !ok {
+			return nil, &diag.SimpleMessageError{
+				Pin:  s.Pin,
+				Text: fmt.Sprintf("bad operand: want register, got (%T)", op),
+			}
+		}
+		if dest.Name.Special() {
+			return nil, &diag.SimpleMessageError{
+				Pin:  s.Pin,
+				Text: "dec instruction cannot have special register as destination operand",
+			}
+		}
+
+		op = s.Operands[1]
+		switch o := op.(type) {
+		case ast.Register:
+			return ir.DecReg{
+				Dest:   dest.Name,
+				Source: o.Name,
+			}, nil
+		case ast.Integer:
+			return ir.DecVal{
+				Dest: dest.Name,
+				Val:  o.Val,
+			}, nil
+		default:
+			return nil, &diag.SimpleMessageError{
+				Pin:  s.Pin,
+				Text: fmt.Sprintf("bad operand: source must be register or immediate, got (%T)", o),
+			}
+		}
 	default:
 		return nil, wrongOperandsNumber(s.Pin, len(s.Operands), "1-2")
 	}
-
-	panic("stub")
 }
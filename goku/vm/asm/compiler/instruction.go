@@ -20,7 +20,15 @@ func wrongOperandsNumber(pin sm.Pin, got int, want string) diag.Error {
 func (c *Compiler) translateTest(s ast.Instruction) (ir.Atom, diag.Error) {
 	switch len(s.Operands) {
 	case 1:
-		panic("stub")
+		op := s.Operands[0]
+		reg, ok := op.(ast.Register)
+		if !ok {
+			return nil, &diag.SimpleMessageError{
+				Pin:  s.Pin,
+				Text: fmt.Sprintf("bad operand: want register, got (%T)", op),
+			}
+		}
+		return ir.TestVal{Dest: reg.Name}, nil
 	case 2:
 		op := s.Operands[0]
 		reg, ok := op.(ast.Register)
@@ -34,7 +42,10 @@ func (c *Compiler) translateTest(s ast.Instruction) (ir.Atom, diag.Error) {
 		source := s.Operands[1]
 		switch c := source.(type) {
 		case ast.Register:
-			panic("stub")
+			return ir.TestReg{
+				Dest:   reg.Name,
+				Source: c.Name,
+			}, nil
 		case ast.Integer:
 			return ir.TestVal{
 				Dest: reg.Name,
@@ -47,7 +58,7 @@ func (c *Compiler) translateTest(s ast.Instruction) (ir.Atom, diag.Error) {
 			}
 		}
 	default:
-		return nil, wrongOperandsNumber(s.Pin, len(s.Operands), "2")
+		return nil, wrongOperandsNumber(s.Pin, len(s.Operands), "1-2")
 	}
 }
 
@@ -70,21 +81,33 @@ func (c *Compiler) translateSet(s ast.Instruction) (ir.Atom, diag.Error) {
 		}
 
 		source := s.Operands[1]
-		switch c := source.(type) {
+		switch o := source.(type) {
 		case ast.Register:
 			return ir.SetReg{
 				Dest:   d.Name,
-				Source: c.Name,
+				Source: o.Name,
 			}, nil
 		case ast.Integer:
 			return ir.SetVal{
 				Dest: d.Name,
-				Val:  c.Val,
+				Val:  o.Val,
+			}, nil
+		case ast.Symbol:
+			data, ok := c.data[o.Name]
+			if !ok {
+				return nil, &diag.SimpleMessageError{
+					Pin:  o.Pin,
+					Text: fmt.Sprintf("data \"%s\" not declared", o.Name),
+				}
+			}
+			return ir.SetData{
+				Dest: d.Name,
+				Data: data,
 			}, nil
 		default:
 			return nil, &diag.SimpleMessageError{
 				Pin:  s.Pin,
-				Text: fmt.Sprintf("bad operand: source must be register or immediate, got (%T)", c),
+				Text: fmt.Sprintf("bad operand: source must be register, immediate or data, got (%T)", o),
 			}
 		}
 	default:
@@ -101,8 +124,22 @@ func (c *Compiler) translateJump(s ast.Instruction) (ir.Atom, diag.Error) {
 		flag = opc.FlagZ
 	case "nz":
 		flag = opc.FlagNZ
+	case "l":
+		flag = opc.FlagL
 	case "le":
 		flag = opc.FlagLE
+	case "g":
+		flag = opc.FlagG
+	case "ge":
+		flag = opc.FlagGE
+	case "b":
+		flag = opc.FlagB
+	case "be":
+		flag = opc.FlagBE
+	case "a":
+		flag = opc.FlagA
+	case "ae":
+		flag = opc.FlagAE
 	default:
 		return nil, &diag.SimpleMessageError{
 			Pin:  s.Pin,
@@ -113,11 +150,17 @@ func (c *Compiler) translateJump(s ast.Instruction) (ir.Atom, diag.Error) {
 	switch len(s.Operands) {
 	case 1:
 		d := s.Operands[0]
+		if r, ok := d.(ast.Register); ok {
+			return ir.JumpReg{
+				Reg:  r.Name,
+				Flag: flag,
+			}, nil
+		}
 		label, ok := d.(ast.Label)
 		if !ok {
 			return nil, &diag.SimpleMessageError{
 				Pin:  s.Pin,
-				Text: fmt.Sprintf("bad operand: destination must be label, got (%T)", d),
+				Text: fmt.Sprintf("bad operand: destination must be label or register, got (%T)", d),
 			}
 		}
 		l, ok := c.labels[label.Name]
@@ -144,11 +187,14 @@ func (c *Compiler) translateCall(s ast.Instruction) (ir.Atom, diag.Error) {
 	switch len(s.Operands) {
 	case 1:
 		d := s.Operands[0]
+		if r, ok := d.(ast.Register); ok {
+			return ir.CallReg{Reg: r.Name}, nil
+		}
 		symbol, ok := d.(ast.Symbol)
 		if !ok {
 			return nil, &diag.SimpleMessageError{
 				Pin:  s.Pin,
-				Text: fmt.Sprintf("bad operand: destination must be symbol, got (%T)", d),
+				Text: fmt.Sprintf("bad operand: destination must be symbol or register, got (%T)", d),
 			}
 		}
 		fun, ok := c.funs[symbol.Name]
@@ -0,0 +1,172 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/mebyus/ku/goku/compiler/diag"
+	"github.com/mebyus/ku/goku/vm/asm/ast"
+	"github.com/mebyus/ku/goku/vm/ir"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// Returns size of accessed memory based on instruction variant.
+// Defaults to 8 bytes if variant is not specified.
+func memSize(s ast.Instruction) (opc.MemSize, diag.Error) {
+	switch s.Variant {
+	case "u8":
+		return opc.Mem8, nil
+	case "u16":
+		return opc.Mem16, nil
+	case "u32":
+		return opc.Mem32, nil
+	case "", "u64":
+		return opc.Mem64, nil
+	default:
+		return 0, &diag.SimpleMessageError{
+			Pin:  s.Pin,
+			Text: fmt.Sprintf("bad %s size \"%s\"", s.Mnemonic, s.Variant),
+		}
+	}
+}
+
+// memory operand decoded from instruction operands
+type memOperand struct {
+	// Immediate pointer. Used only if pointer is not in register.
+	val uint64
+
+	offset uint32
+
+	reg opc.Register
+
+	// true if pointer is stored in register
+	isReg bool
+}
+
+// Translates pointer operand and optional offset.
+func translateMemOperand(s ast.Instruction, ptr ast.Operand, offset ast.Operand) (memOperand, diag.Error) {
+	switch p := ptr.(type) {
+	case ast.Register:
+		m := memOperand{reg: p.Name, isReg: true}
+		if offset == nil {
+			return m, nil
+		}
+		v, ok := offset.(ast.Integer)
+		if !ok {
+			return memOperand{}, &diag.SimpleMessageError{
+				Pin:  s.Pin,
+				Text: fmt.Sprintf("bad operand: offset must be immediate, got (%T)", offset),
+			}
+		}
+		if v.Val > 0xFFFFFFFF {
+			return memOperand{}, &diag.SimpleMessageError{
+				Pin:  v.Pin,
+				Text: fmt.Sprintf("offset (=0x%X) does not fit into 32 bits", v.Val),
+			}
+		}
+		m.offset = uint32(v.Val)
+		return m, nil
+	case ast.Integer:
+		if offset != nil {
+			return memOperand{}, &diag.SimpleMessageError{
+				Pin:  s.Pin,
+				Text: "offset cannot be used with immediate pointer",
+			}
+		}
+		return memOperand{val: p.Val}, nil
+	default:
+		return memOperand{}, &diag.SimpleMessageError{
+			Pin:  s.Pin,
+			Text: fmt.Sprintf("bad operand: pointer must be register or immediate, got (%T)", p),
+		}
+	}
+}
+
+// Translates load instruction:
+//
+//	load.u32 dest, ptr;
+//	load.u32 dest, ptr, offset;
+func (c *Compiler) translateLoad(s ast.Instruction) (ir.Atom, diag.Error) {
+	size, err := memSize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var offset ast.Operand
+	switch len(s.Operands) {
+	case 2:
+	case 3:
+		offset = s.Operands[2]
+	default:
+		return nil, wrongOperandsNumber(s.Pin, len(s.Operands), "2-3")
+	}
+
+	dest, err := destRegister(s, s.Operands[0])
+	if err != nil {
+		return nil, err
+	}
+	m, err := translateMemOperand(s, s.Operands[1], offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.isReg {
+		return ir.LoadVal{
+			Size: size,
+			Dest: dest.Name,
+			Ptr:  m.val,
+		}, nil
+	}
+	return ir.LoadReg{
+		Size:   size,
+		Dest:   dest.Name,
+		Ptr:    m.reg,
+		Offset: m.offset,
+	}, nil
+}
+
+// Translates store instruction:
+//
+//	store.u32 ptr, source;
+//	store.u32 ptr, source, offset;
+func (c *Compiler) translateStore(s ast.Instruction) (ir.Atom, diag.Error) {
+	size, err := memSize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var offset ast.Operand
+	switch len(s.Operands) {
+	case 2:
+	case 3:
+		offset = s.Operands[2]
+	default:
+		return nil, wrongOperandsNumber(s.Pin, len(s.Operands), "2-3")
+	}
+
+	op := s.Operands[1]
+	source, ok := op.(ast.Register)
+	if !ok {
+		return nil, &diag.SimpleMessageError{
+			Pin:  s.Pin,
+			Text: fmt.Sprintf("bad operand: source must be register, got (%T)", op),
+		}
+	}
+	m, err := translateMemOperand(s, s.Operands[0], offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.isReg {
+		return ir.StoreVal{
+			Size:   size,
+			Source: source.Name,
+			Ptr:    m.val,
+		}, nil
+	}
+	return ir.StoreReg{
+		Size:   size,
+		Source: source.Name,
+		Ptr:    m.reg,
+		Offset: m.offset,
+	}, nil
+}
@@ -11,15 +11,23 @@ import (
 func (c *Compiler) translatePush(s ast.Instruction) (ir.Atom, diag.Error) {
 	switch len(s.Operands) {
 	case 1:
-		op := s.Operands[0]
-		reg, ok := op.(ast.Register)
-		if !ok {
+		switch o := s.Operands[0].(type) {
+		case ast.Register:
+			return ir.PushReg{Reg: o.Name}, nil
+		case ast.Integer:
+			if o.Val > 0xFFFFFFFF {
+				return nil, &diag.SimpleMessageError{
+					Pin:  o.Pin,
+					Text: fmt.Sprintf("pushed immediate value (=0x%X) does not fit into 32 bits", o.Val),
+				}
+			}
+			return ir.PushVal{Val: uint32(o.Val)}, nil
+		default:
 			return nil, &diag.SimpleMessageError{
 				Pin:  s.Pin,
-				Text: fmt.Sprintf("bad operand: want register, got (%T)", op),
+				Text: fmt.Sprintf("bad operand: want register or immediate, got (%T)", o),
 			}
 		}
-		return ir.PushReg{Reg: reg.Name}, nil
 	default:
 		return nil, wrongOperandsNumber(s.Pin, len(s.Operands), "1")
 	}
@@ -6,41 +6,58 @@ import (
 )
 
 func (a *Assembler) incReg(t ir.IncReg) {
-	a.opcode(opc.Inc)
-	a.layout(opc.IncReg)
-	a.register(t.Dest)
-	a.register(t.Source)
+	a.incRegOp(opc.Inc, t.Dest, t.Source)
 }
 
 func (a *Assembler) incVal(t ir.IncVal) {
-	v := t.Val
+	a.incValOp(opc.Inc, t.Dest, t.Val)
+}
+
+func (a *Assembler) decReg(t ir.DecReg) {
+	a.incRegOp(opc.Dec, t.Dest, t.Source)
+}
+
+func (a *Assembler) decVal(t ir.DecVal) {
+	a.incValOp(opc.Dec, t.Dest, t.Val)
+}
+
+// encode Inc or Dec instruction with register source.
+func (a *Assembler) incRegOp(op opc.Opcode, dest, source opc.Register) {
+	a.opcode(op)
+	a.layout(opc.IncReg)
+	a.register(dest)
+	a.register(source)
+}
+
+// encode Inc or Dec instruction with immediate source.
+func (a *Assembler) incValOp(op opc.Opcode, dest opc.Register, v uint64) {
 	if v < 16 {
-		a.incVal4(t.Dest, uint8(v))
+		a.incVal4(op, dest, uint8(v))
 		return
 	}
 	if v <= 0xFFFFFFFF {
-		a.incVal32(t.Dest, uint32(v))
+		a.incVal32(op, dest, uint32(v))
 		return
 	}
 
-	a.incVal64(t.Dest, v)
+	a.incVal64(op, dest, v)
 }
 
-func (a *Assembler) incVal4(dest opc.Register, v uint8) {
-	a.opcode(opc.Inc)
+func (a *Assembler) incVal4(op opc.Opcode, dest opc.Register, v uint8) {
+	a.opcode(op)
 	a.layout(opc.EncodeIncTinyLayout(v))
 	a.register(dest)
 }
 
-func (a *Assembler) incVal32(dest opc.Register, v uint32) {
-	a.opcode(opc.Inc)
+func (a *Assembler) incVal32(op opc.Opcode, dest opc.Register, v uint32) {
+	a.opcode(op)
 	a.layout(opc.IncVal32)
 	a.register(dest)
 	a.val32(v)
 }
 
-func (a *Assembler) incVal64(dest opc.Register, v uint64) {
-	a.opcode(opc.Inc)
+func (a *Assembler) incVal64(op opc.Opcode, dest opc.Register, v uint64) {
+	a.opcode(op)
 	a.layout(opc.IncVal64)
 	a.register(dest)
 	a.val64(v)
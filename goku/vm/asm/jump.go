@@ -27,3 +27,10 @@ func (a *Assembler) jumpLabel(t ir.JumpLabel) {
 
 	a.val32(address)
 }
+
+// encode jump instruction with address stored in register.
+func (a *Assembler) jumpReg(t ir.JumpReg) {
+	a.opcode(opc.Jump)
+	a.layout(opc.EncodeJumpLayout(t.Flag, opc.JumpReg))
+	a.register(t.Reg)
+}
@@ -0,0 +1,46 @@
+package asm
+
+import (
+	"github.com/mebyus/ku/goku/vm/ir"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+func (a *Assembler) loadReg(t ir.LoadReg) {
+	a.memReg(opc.Load, t.Size, t.Dest, t.Ptr, t.Offset)
+}
+
+func (a *Assembler) loadVal(t ir.LoadVal) {
+	a.memVal(opc.Load, t.Size, t.Dest, t.Ptr)
+}
+
+func (a *Assembler) storeReg(t ir.StoreReg) {
+	a.memReg(opc.Store, t.Size, t.Source, t.Ptr, t.Offset)
+}
+
+func (a *Assembler) storeVal(t ir.StoreVal) {
+	a.memVal(opc.Store, t.Size, t.Source, t.Ptr)
+}
+
+// encode Load or Store instruction with pointer stored in register.
+func (a *Assembler) memReg(op opc.Opcode, size opc.MemSize, reg, ptr opc.Register, offset uint32) {
+	a.opcode(op)
+	if offset == 0 {
+		a.layout(opc.EncodeMemLayout(size, opc.MemReg))
+		a.register(reg)
+		a.register(ptr)
+		return
+	}
+
+	a.layout(opc.EncodeMemLayout(size, opc.MemRegVal32))
+	a.register(reg)
+	a.register(ptr)
+	a.val32(offset)
+}
+
+// encode Load or Store instruction with immediate pointer.
+func (a *Assembler) memVal(op opc.Opcode, size opc.MemSize, reg opc.Register, ptr uint64) {
+	a.opcode(op)
+	a.layout(opc.EncodeMemLayout(size, opc.MemVal64))
+	a.register(reg)
+	a.val64(ptr)
+}
@@ -172,7 +172,7 @@ func unknownRegister(pin sm.Pin, s string) diag.Error {
 func parseRegisterName(pin sm.Pin, s string) (opc.Register, diag.Error) {
 	switch s {
 	case "sp":
-		return opc.RegFP, nil
+		return opc.RegSP, nil
 	case "ip":
 		return opc.RegIP, nil
 	case "sc":
@@ -30,7 +30,7 @@ func (p *Parser) top() diag.Error {
 	case tokens.Fun:
 		return p.topFun()
 	case tokens.Data:
-		panic("not implemented")
+		return p.topData()
 	case tokens.Entry:
 		return p.topEntry()
 	default:
@@ -66,3 +66,32 @@ func (p *Parser) topEntry() diag.Error {
 	}
 	return nil
 }
+
+func (p *Parser) topData() diag.Error {
+	p.advance() // skip "#data"
+
+	if p.peek.Kind != tokens.Word {
+		return p.unexpected()
+	}
+	name := p.peek.Data
+	pin := p.peek.Pin
+	p.advance() // skip data name
+
+	if p.peek.Kind != tokens.String {
+		return p.unexpected()
+	}
+	val := p.peek.Data
+	p.advance() // skip string
+
+	if p.peek.Kind != tokens.Semicolon {
+		return p.unexpected()
+	}
+	p.advance() // skip ";"
+
+	p.text.Data = append(p.text.Data, ast.Data{
+		Val:  val,
+		Name: name,
+		Pin:  pin,
+	})
+	return nil
+}
@@ -0,0 +1,24 @@
+package asm
+
+import (
+	"github.com/mebyus/ku/goku/vm/ir"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+func (a *Assembler) pushReg(t ir.PushReg) {
+	a.opcode(opc.Push)
+	a.layout(opc.PushReg)
+	a.register(t.Reg)
+}
+
+func (a *Assembler) pushVal(t ir.PushVal) {
+	a.opcode(opc.Push)
+	a.layout(opc.PushVal32)
+	a.val32(t.Val)
+}
+
+func (a *Assembler) popReg(t ir.PopReg) {
+	a.opcode(opc.Pop)
+	a.layout(opc.PopReg)
+	a.register(t.Reg)
+}
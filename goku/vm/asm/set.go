@@ -24,6 +24,7 @@ func (a *Assembler) setVal(t ir.SetVal) {
 	}
 	if v <= 0xFFFF {
 		a.setVal16(t.Dest, uint16(v))
+		return
 	}
 	if v <= 0xFFFFFFFF {
 		a.setVal32(t.Dest, uint32(v))
@@ -33,6 +34,16 @@ func (a *Assembler) setVal(t ir.SetVal) {
 	a.setVal64(t.Dest, v)
 }
 
+// Data segment number in pointer encoding. Must be the same as vm.SegData.
+const segData = 0x01
+
+func (a *Assembler) setData(t ir.SetData) {
+	// data segment is encoded before text, thus offset is already known;
+	// pointer always has segment in its highest byte, hence 64-bit layout
+	ptr := uint64(segData)<<56 | uint64(a.tab.Data[t.Data])
+	a.setVal64(t.Dest, ptr)
+}
+
 func (a *Assembler) setVal4(dest opc.Register, v uint8) {
 	a.opcode(opc.Set)
 	a.layout(opc.EncodeSetValLayout(opc.SetVal4, v))
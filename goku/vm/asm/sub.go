@@ -5,6 +5,13 @@ import (
 	"github.com/mebyus/ku/goku/vm/opc"
 )
 
+func (a *Assembler) testReg(t ir.TestReg) {
+	a.opcode(opc.Test)
+	a.layout(opc.EncodeTestLayout(opc.TestReg))
+	a.register(t.Dest)
+	a.register(t.Source)
+}
+
 func (a *Assembler) testVal(t ir.TestVal) {
 	v := t.Val
 	if v <= 0xF {
@@ -17,6 +24,7 @@ func (a *Assembler) testVal(t ir.TestVal) {
 	}
 	if v <= 0xFFFF {
 		a.testVal16(t.Dest, uint16(v))
+		return
 	}
 	if v <= 0xFFFFFFFF {
 		a.testVal32(t.Dest, uint32(v))
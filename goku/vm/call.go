@@ -12,7 +12,24 @@ func (m *Machine) execCall(lt uint8) (uint64, *RuntimeError) {
 
 	switch opc.Layout(lt) {
 	case opc.CallReg:
-		panic("stub")
+		size = 1
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		var val uint64
+		val, err = m.get(opc.Register(data[0]))
+		if err != nil {
+			return 0, err
+		}
+		segment, offset := getPointerSegmentAndOffset(val)
+		if segment != SegText {
+			return 0, &RuntimeError{
+				Code: ErrorNonTextJump,
+				Aux:  val,
+			}
+		}
+		addr = offset
 	case opc.CallVal32:
 		size = 4
 		data, err = m.idata(size)
@@ -0,0 +1,85 @@
+package vm
+
+import (
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+func (m *Machine) execTest(lt uint8) (uint64, *RuntimeError) {
+	variant, v := opc.DecodeTestLayout(lt)
+
+	var data []byte // instruction data
+	var size uint64
+	var y uint64 // compared value
+	var err *RuntimeError
+	switch variant {
+	case opc.TestReg:
+		size = 2
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		y, err = m.get(opc.Register(data[1]))
+	case opc.TestVal4:
+		y = uint64(v)
+		size = 1
+		data, err = m.idata(size)
+	case opc.TestVal8:
+		size = 2
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		y = uint64(data[1])
+	case opc.TestVal16:
+		size = 3
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		y = uint64(val16(data[1:]))
+	case opc.TestVal32:
+		size = 5
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		y = uint64(val32(data[1:]))
+	case opc.TestVal64:
+		size = 9
+		data, err = m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		y = val64(data[1:])
+	default:
+		return 0, &RuntimeError{
+			Code: ErrorBadVariant,
+			Aux:  uint64(variant),
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	x, err := m.get(opc.Register(data[0]))
+	if err != nil {
+		return 0, err
+	}
+	m.cf = compare(x, y)
+	return size, nil
+}
+
+// compare returns comparison flags for x and y.
+func compare(x, y uint64) uint64 {
+	var cf uint64
+	if x == y {
+		cf |= FlagZero
+	}
+	if int64(x) < int64(y) {
+		cf |= FlagLess
+	}
+	if x < y {
+		cf |= FlagBelow
+	}
+	return cf
+}
@@ -0,0 +1,318 @@
+package vm
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mebyus/ku/goku/vm/kvx"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// StopReason indicates why debugger stopped program execution.
+type StopReason uint8
+
+const (
+	// Requested number of instructions was executed.
+	StopStep StopReason = iota + 1
+
+	// Execution reached breakpoint.
+	StopBreak
+
+	// Watched register changed its value.
+	StopWatch
+
+	// Machine was halted by instruction or runtime error.
+	StopHalt
+)
+
+var stopText = [...]string{
+	0: "<nil>",
+
+	StopStep:  "step",
+	StopBreak: "breakpoint",
+	StopWatch: "watchpoint",
+	StopHalt:  "halt",
+}
+
+func (r StopReason) String() string {
+	return stopText[r]
+}
+
+// Stop describes where and why debugger stopped program execution.
+type Stop struct {
+	// Old and new value of watched register. Only for StopWatch.
+	Old uint64
+	New uint64
+
+	// Instruction pointer at stop.
+	IP uint64
+
+	Reason StopReason
+
+	// Watched register which changed its value. Only for StopWatch.
+	Reg opc.Register
+}
+
+// TraceEntry describes one executed instruction.
+type TraceEntry struct {
+	// Value of clock register before instruction execution.
+	Clock uint64
+
+	// Instruction offset in program text.
+	IP uint64
+}
+
+// DefaultTraceSize is number of last executed instructions kept in trace.
+const DefaultTraceSize = 256
+
+type watch struct {
+	// last observed register value
+	val uint64
+
+	reg opc.Register
+}
+
+// Debugger controls step-by-step execution of a program on machine.
+type Debugger struct {
+	m Machine
+
+	prog *kvx.Program
+
+	// set of breakpoint offsets in program text
+	breaks map[uint32]struct{}
+
+	watches []watch
+
+	// ring buffer of last executed instructions
+	trace []TraceEntry
+
+	// index of the next trace entry to overwrite
+	tpos int
+}
+
+// NewDebugger loads program into a new machine. Execution stops before
+// the first instruction of program entrypoint.
+func NewDebugger(prog *kvx.Program) (*Debugger, error) {
+	d := &Debugger{
+		prog:   prog,
+		breaks: make(map[uint32]struct{}),
+		trace:  make([]TraceEntry, 0, DefaultTraceSize),
+	}
+	err := d.m.Load(prog)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Machine returns debugged machine for state inspection.
+func (d *Debugger) Machine() *Machine {
+	return &d.m
+}
+
+// Program returns debugged program.
+func (d *Debugger) Program() *kvx.Program {
+	return d.prog
+}
+
+// Restart reloads program, keeping breakpoints and watchpoints.
+func (d *Debugger) Restart() error {
+	err := d.m.Load(d.prog)
+	if err != nil {
+		return err
+	}
+	d.trace = d.trace[:0]
+	d.tpos = 0
+	for i := range d.watches {
+		d.watches[i].val, _ = d.m.get(d.watches[i].reg)
+	}
+	return nil
+}
+
+// Step executes at most n instructions. Stops earlier on breakpoint,
+// watchpoint or halt.
+func (d *Debugger) Step(n uint64) Stop {
+	for i := uint64(0); i < n; i += 1 {
+		stop, ok := d.exec(i != 0)
+		if ok {
+			return stop
+		}
+	}
+	return Stop{Reason: StopStep, IP: d.m.ip}
+}
+
+// Continue executes program until it reaches breakpoint, watchpoint
+// or halts. At least one instruction is executed, thus continue from
+// breakpoint does not stop at the same breakpoint immediately.
+func (d *Debugger) Continue() Stop {
+	first := true
+	for {
+		stop, ok := d.exec(!first)
+		if ok {
+			return stop
+		}
+		first = false
+	}
+}
+
+// execute one instruction, returns true if execution must stop
+func (d *Debugger) exec(checkBreak bool) (Stop, bool) {
+	if d.m.halt {
+		return Stop{Reason: StopHalt, IP: d.m.ip}, true
+	}
+	if checkBreak {
+		_, ok := d.breaks[uint32(d.m.ip)]
+		if ok {
+			return Stop{Reason: StopBreak, IP: d.m.ip}, true
+		}
+	}
+
+	d.record(TraceEntry{Clock: d.m.clock, IP: d.m.ip})
+	d.m.Step()
+
+	for i := range d.watches {
+		w := &d.watches[i]
+		v, _ := d.m.get(w.reg)
+		if v != w.val {
+			stop := Stop{
+				Reason: StopWatch,
+				IP:     d.m.ip,
+				Reg:    w.reg,
+				Old:    w.val,
+				New:    v,
+			}
+			w.val = v
+			return stop, true
+		}
+	}
+	if d.m.halt {
+		return Stop{Reason: StopHalt, IP: d.m.ip}, true
+	}
+	return Stop{}, false
+}
+
+func (d *Debugger) record(e TraceEntry) {
+	if len(d.trace) < cap(d.trace) {
+		d.trace = append(d.trace, e)
+		return
+	}
+	d.trace[d.tpos] = e
+	d.tpos = (d.tpos + 1) % len(d.trace)
+}
+
+// Trace returns last executed instructions in execution order.
+func (d *Debugger) Trace() []TraceEntry {
+	t := make([]TraceEntry, 0, len(d.trace))
+	t = append(t, d.trace[d.tpos:]...)
+	t = append(t, d.trace[:d.tpos]...)
+	return t
+}
+
+// Break sets breakpoint at a given text offset.
+func (d *Debugger) Break(offset uint32) error {
+	if offset >= uint32(len(d.prog.Text)) {
+		return fmt.Errorf("offset 0x%08X is outside of program text", offset)
+	}
+	d.breaks[offset] = struct{}{}
+	return nil
+}
+
+// Unbreak removes breakpoint at a given text offset.
+func (d *Debugger) Unbreak(offset uint32) {
+	delete(d.breaks, offset)
+}
+
+// Breakpoints returns offsets of all breakpoints in ascending order.
+func (d *Debugger) Breakpoints() []uint32 {
+	list := make([]uint32, 0, len(d.breaks))
+	for offset := range d.breaks {
+		list = append(list, offset)
+	}
+	slices.Sort(list)
+	return list
+}
+
+// Watch sets watchpoint on a given register. Execution stops after
+// instruction which changed register value.
+func (d *Debugger) Watch(r opc.Register) error {
+	v, err := d.m.get(r)
+	if err != nil {
+		return fmt.Errorf("bad register %s", r)
+	}
+	for _, w := range d.watches {
+		if w.reg == r {
+			return nil
+		}
+	}
+	d.watches = append(d.watches, watch{reg: r, val: v})
+	return nil
+}
+
+// Unwatch removes watchpoint from a given register.
+func (d *Debugger) Unwatch(r opc.Register) {
+	for i, w := range d.watches {
+		if w.reg == r {
+			d.watches = append(d.watches[:i], d.watches[i+1:]...)
+			return
+		}
+	}
+}
+
+// Resolve translates location into text offset. Location may be
+// a number (decimal or hex with 0x prefix), function name or
+// label in the form "fun@.label". Names require program symbol table.
+func (d *Debugger) Resolve(loc string) (uint32, error) {
+	if loc == "" {
+		return 0, fmt.Errorf("empty location")
+	}
+	if loc[0] >= '0' && loc[0] <= '9' {
+		n, err := strconv.ParseUint(loc, 0, 32)
+		if err != nil {
+			return 0, fmt.Errorf("bad offset \"%s\"", loc)
+		}
+		return uint32(n), nil
+	}
+
+	t := d.prog.Symbols
+	if t == nil {
+		return 0, fmt.Errorf("program has no symbol table")
+	}
+	fun, label, hasLabel := strings.Cut(loc, "@.")
+	for i, f := range t.Funs {
+		if f.Name != fun {
+			continue
+		}
+		if !hasLabel {
+			return f.Offset, nil
+		}
+
+		end := uint32(len(d.prog.Text))
+		if i+1 < len(t.Funs) {
+			end = t.Funs[i+1].Offset
+		}
+		for _, l := range t.Labels {
+			if l.Name == label && f.Offset <= l.Offset && l.Offset < end {
+				return l.Offset, nil
+			}
+		}
+		return 0, fmt.Errorf("function \"%s\" has no label \"%s\"", fun, label)
+	}
+	return 0, fmt.Errorf("unknown function \"%s\"", fun)
+}
+
+// Symbolize returns human readable name for a given text offset,
+// for example "fib+0x1A". Returns hex offset if program has no symbols.
+func (d *Debugger) Symbolize(offset uint64) string {
+	if d.prog.Symbols != nil {
+		f, ok := d.prog.Symbols.FindFun(uint32(offset))
+		if ok {
+			if uint64(f.Offset) == offset {
+				return f.Name
+			}
+			return fmt.Sprintf("%s+0x%X", f.Name, offset-uint64(f.Offset))
+		}
+	}
+	return fmt.Sprintf("0x%08X", offset)
+}
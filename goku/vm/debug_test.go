@@ -0,0 +1,154 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mebyus/ku/goku/vm/asm"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+func TestDebugger(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+
+		// breakpoint locations
+		breaks []string
+
+		// watched registers
+		watches []opc.Register
+
+		// expected number of stops before halt
+		stops int
+
+		status uint64
+	}{
+		{
+			name:   "1 no breakpoints",
+			code:   code8,
+			stops:  0,
+			status: 8,
+		},
+		{
+			name:   "2 break on fib",
+			code:   code8,
+			breaks: []string{"fib"},
+			stops:  25,
+			status: 8,
+		},
+		{
+			name:   "3 break on label",
+			code:   code8,
+			breaks: []string{"fib@.exit"},
+			stops:  25,
+			status: 8,
+		},
+		{
+			name:   "4 break on offset",
+			code:   code7,
+			breaks: []string{"0"},
+			stops:  0,
+			status: 0x23,
+		},
+		{
+			name:    "5 watch register",
+			code:    code4,
+			watches: []opc.Register{opc.RegSC},
+			stops:   1,
+			status:  19,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := asm.Compile(strings.NewReader(tt.code))
+			if err != nil {
+				t.Errorf("asm.Compile() error = %v", err)
+				return
+			}
+			d, err := NewDebugger(prog)
+			if err != nil {
+				t.Errorf("NewDebugger() error = %v", err)
+				return
+			}
+			for _, loc := range tt.breaks {
+				offset, err := d.Resolve(loc)
+				if err != nil {
+					t.Errorf("Resolve(%s) error = %v", loc, err)
+					return
+				}
+				err = d.Break(offset)
+				if err != nil {
+					t.Errorf("Break(%s) error = %v", loc, err)
+					return
+				}
+			}
+			for _, r := range tt.watches {
+				err = d.Watch(r)
+				if err != nil {
+					t.Errorf("Watch(%s) error = %v", r, err)
+					return
+				}
+			}
+
+			stops := 0
+			for {
+				stop := d.Continue()
+				if stop.Reason == StopHalt {
+					break
+				}
+				stops += 1
+			}
+			if stops != tt.stops {
+				t.Errorf("stops = %d, want %d", stops, tt.stops)
+			}
+
+			exit := d.Machine().State()
+			if exit.Error != nil {
+				t.Errorf("exit.Error = (%d) %s", exit.Error.Code, exit.Error)
+				return
+			}
+			if exit.Status != tt.status {
+				t.Errorf("exit.Status = %d, want %d", exit.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestDebugger_Step(t *testing.T) {
+	prog, err := asm.Compile(strings.NewReader(code8))
+	if err != nil {
+		t.Fatalf("asm.Compile() error = %v", err)
+	}
+	var m Machine
+	exit := m.Exec(prog)
+
+	d, err := NewDebugger(prog)
+	if err != nil {
+		t.Fatalf("NewDebugger() error = %v", err)
+	}
+	stop := d.Step(3)
+	if stop.Reason != StopStep {
+		t.Errorf("stop.Reason = %s, want %s", stop.Reason, StopStep)
+	}
+	if len(d.Trace()) != 3 {
+		t.Errorf("len(Trace()) = %d, want 3", len(d.Trace()))
+	}
+
+	stop = d.Step(exit.Clock)
+	if stop.Reason != StopHalt {
+		t.Errorf("stop.Reason = %s, want %s", stop.Reason, StopHalt)
+	}
+	if d.Machine().State().Clock != exit.Clock {
+		t.Errorf("Clock = %d, want %d", d.Machine().State().Clock, exit.Clock)
+	}
+	trace := d.Trace()
+	want := min(exit.Clock, DefaultTraceSize)
+	if uint64(len(trace)) != want {
+		t.Fatalf("len(Trace()) = %d, want %d", len(trace), want)
+	}
+	last := trace[len(trace)-1]
+	if last.Clock != exit.Clock-1 {
+		t.Errorf("last.Clock = %d, want %d", last.Clock, exit.Clock-1)
+	}
+}
@@ -0,0 +1,324 @@
+package disasm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// Decode decodes one instruction at specified offset in program text.
+func Decode(text []byte, offset uint32) (Instruction, error) {
+	d := decoder{text: text, offset: offset}
+	return d.decode()
+}
+
+type decoder struct {
+	text []byte
+
+	// instruction being decoded
+	s Instruction
+
+	// offset of instruction start
+	offset uint32
+}
+
+func (d *decoder) decode() (Instruction, error) {
+	if uint64(d.offset)+2 > uint64(len(d.text)) {
+		return Instruction{}, fmt.Errorf("0x%08X: truncated instruction", d.offset)
+	}
+
+	op := opc.Opcode(d.text[d.offset])
+	lt := d.text[d.offset+1]
+	d.s = Instruction{
+		Offset: d.offset,
+		Size:   2,
+		Opcode: op,
+		Layout: lt,
+	}
+
+	var err error
+	switch op {
+	case opc.Sys:
+		err = d.sys(lt)
+	case opc.Jump:
+		err = d.jump(lt)
+	case opc.Call:
+		err = d.call(lt)
+	case opc.Push:
+		err = d.push(lt)
+	case opc.Pop, opc.Clear:
+		if lt != 0 {
+			return Instruction{}, d.badLayout()
+		}
+		d.s.Mnemonic = op.String()
+		err = d.regs(1)
+	case opc.Set, opc.Test:
+		err = d.set(op, lt)
+	case opc.Load, opc.Store:
+		err = d.mem(op, lt)
+	case opc.Inc, opc.Dec:
+		err = d.inc(op, lt)
+	case opc.Add, opc.Sub, opc.Mul, opc.Div, opc.Rem, opc.And, opc.Or, opc.Xor, opc.Shl, opc.Shr,
+		opc.SDiv, opc.SRem, opc.Sar:
+		err = d.arith(op, lt)
+	default:
+		return Instruction{}, fmt.Errorf("0x%08X: unknown opcode (=0x%02X)", d.offset, uint8(op))
+	}
+	if err != nil {
+		return Instruction{}, err
+	}
+	return d.s, nil
+}
+
+func (d *decoder) badLayout() error {
+	return fmt.Errorf("0x%08X: bad %s layout (=0x%02X)", d.offset, d.s.Opcode, d.s.Layout)
+}
+
+// returns next n bytes of instruction data and advances instruction size
+func (d *decoder) take(n uint32) ([]byte, error) {
+	start := uint64(d.offset) + uint64(d.s.Size)
+	end := start + uint64(n)
+	if end > uint64(len(d.text)) {
+		return nil, fmt.Errorf("0x%08X: truncated %s instruction data", d.offset, d.s.Opcode)
+	}
+	d.s.Size += n
+	return d.text[start:end], nil
+}
+
+func (d *decoder) add(kind OperandKind, v uint64) {
+	d.s.Operands = append(d.s.Operands, Operand{Kind: kind, Val: v})
+}
+
+// decode n register operands
+func (d *decoder) regs(n uint32) error {
+	data, err := d.take(n)
+	if err != nil {
+		return err
+	}
+	for _, r := range data {
+		reg := opc.Register(r)
+		if reg.Special() && reg > opc.RegClock {
+			return fmt.Errorf("0x%08X: bad register (=0x%02X)", d.offset, r)
+		}
+		d.add(Reg, uint64(r))
+	}
+	return nil
+}
+
+// decode immediate value of n bytes
+func (d *decoder) imm(kind OperandKind, n uint32) error {
+	data, err := d.take(n)
+	if err != nil {
+		return err
+	}
+
+	var v uint64
+	switch n {
+	case 1:
+		v = uint64(data[0])
+	case 2:
+		v = uint64(binary.LittleEndian.Uint16(data))
+	case 4:
+		v = uint64(binary.LittleEndian.Uint32(data))
+	case 8:
+		v = binary.LittleEndian.Uint64(data)
+	default:
+		panic(fmt.Sprintf("unexpected immediate size (=%d)", n))
+	}
+	d.add(kind, v)
+	return nil
+}
+
+var sysText = [...]string{
+	opc.Trap:    "trap",
+	opc.Halt:    "halt",
+	opc.Nop:     "nop",
+	opc.SysCall: "syscall",
+	opc.Ret:     "ret",
+}
+
+func (d *decoder) sys(lt uint8) error {
+	if int(lt) >= len(sysText) {
+		return d.badLayout()
+	}
+	d.s.Mnemonic = sysText[lt]
+	return nil
+}
+
+// Short names of jump flags as used in assembly.
+var flagText = [...]string{
+	opc.FlagZ:  "z",
+	opc.FlagNZ: "nz",
+	opc.FlagL:  "l",
+	opc.FlagLE: "le",
+	opc.FlagG:  "g",
+	opc.FlagGE: "ge",
+	opc.FlagB:  "b",
+	opc.FlagBE: "be",
+	opc.FlagA:  "a",
+	opc.FlagAE: "ae",
+}
+
+func (d *decoder) jump(lt uint8) error {
+	flag, variant := opc.DecodeJumpLayout(lt)
+	if int(flag) >= len(flagText) {
+		return d.badLayout()
+	}
+	d.s.Mnemonic = "jump"
+	d.s.Variant = flagText[flag]
+
+	switch variant {
+	case opc.JumpReg:
+		return d.regs(1)
+	case opc.JumpVal32:
+		return d.imm(Addr, 4)
+	default:
+		return d.badLayout()
+	}
+}
+
+func (d *decoder) call(lt uint8) error {
+	d.s.Mnemonic = "call"
+	switch opc.Layout(lt) {
+	case opc.CallReg:
+		return d.regs(1)
+	case opc.CallVal32:
+		return d.imm(Addr, 4)
+	default:
+		return d.badLayout()
+	}
+}
+
+func (d *decoder) push(lt uint8) error {
+	d.s.Mnemonic = "push"
+	switch opc.Layout(lt) {
+	case opc.PushReg:
+		return d.regs(1)
+	case opc.PushVal32:
+		return d.imm(Imm, 4)
+	default:
+		return d.badLayout()
+	}
+}
+
+// decode Set or Test instruction
+func (d *decoder) set(op opc.Opcode, lt uint8) error {
+	// Set and Test layouts have identical encoding
+	variant, v := opc.DecodeSetLayout(lt)
+	d.s.Mnemonic = op.String()
+
+	switch variant {
+	case opc.SetReg:
+		return d.regs(2)
+	case opc.SetVal4:
+		err := d.regs(1)
+		if err != nil {
+			return err
+		}
+		d.add(Imm, uint64(v))
+		return nil
+	case opc.SetVal8, opc.SetVal16, opc.SetVal32, opc.SetVal64:
+		err := d.regs(1)
+		if err != nil {
+			return err
+		}
+		// 8, 16, 32 or 64 bits
+		return d.imm(Imm, 1<<(variant-opc.SetVal8))
+	default:
+		return d.badLayout()
+	}
+}
+
+func (d *decoder) mem(op opc.Opcode, lt uint8) error {
+	size, variant := opc.DecodeMemLayout(lt)
+	if size > opc.Mem64 {
+		return d.badLayout()
+	}
+	d.s.Mnemonic = op.String()
+	d.s.Variant = size.String()
+
+	var err error
+	switch variant {
+	case opc.MemReg:
+		err = d.regs(2)
+	case opc.MemRegVal32:
+		err = d.regs(2)
+		if err != nil {
+			return err
+		}
+		err = d.imm(Imm, 4)
+	case opc.MemVal64:
+		err = d.regs(1)
+		if err != nil {
+			return err
+		}
+		err = d.imm(Ptr, 8)
+	default:
+		return d.badLayout()
+	}
+	if err != nil {
+		return err
+	}
+
+	if op == opc.Store {
+		// in assembly store takes pointer as first operand
+		o := d.s.Operands
+		o[0], o[1] = o[1], o[0]
+	}
+	return nil
+}
+
+func (d *decoder) inc(op opc.Opcode, lt uint8) error {
+	variant, tiny := opc.DecodeIncLayout(lt)
+	d.s.Mnemonic = op.String()
+
+	switch variant {
+	case opc.IncTiny:
+		err := d.regs(1)
+		if err != nil {
+			return err
+		}
+		d.add(Imm, uint64(tiny))
+		return nil
+	case opc.IncReg:
+		return d.regs(2)
+	case opc.IncVal32:
+		err := d.regs(1)
+		if err != nil {
+			return err
+		}
+		return d.imm(Imm, 4)
+	case opc.IncVal64:
+		err := d.regs(1)
+		if err != nil {
+			return err
+		}
+		return d.imm(Imm, 8)
+	default:
+		return d.badLayout()
+	}
+}
+
+func (d *decoder) arith(op opc.Opcode, lt uint8) error {
+	d.s.Mnemonic = op.String()
+
+	switch opc.Layout(lt) {
+	case opc.RegRegReg:
+		return d.regs(3)
+	case opc.RegRegVal32:
+		err := d.regs(2)
+		if err != nil {
+			return err
+		}
+		return d.imm(Imm, 4)
+	case opc.RegRegVal64:
+		err := d.regs(2)
+		if err != nil {
+			return err
+		}
+		return d.imm(Imm, 8)
+	default:
+		return d.badLayout()
+	}
+}
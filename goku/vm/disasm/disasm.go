@@ -0,0 +1,354 @@
+package disasm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/mebyus/ku/goku/compiler/char"
+	"github.com/mebyus/ku/goku/vm/kvx"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// Disassemble writes program in assembly form into w. Produced text
+// is accepted by assembler and encodes into identical program text.
+//
+// Function and label names are taken from program symbol table. Places
+// without symbols (or all places if program has no symbol table) get
+// generated names based on their text offsets.
+func Disassemble(w io.Writer, prog *kvx.Program) error {
+	p, err := Analyze(prog)
+	if err != nil {
+		return err
+	}
+	return p.Render(w)
+}
+
+// Program is a program text split into functions.
+type Program struct {
+	Funs []Fun
+
+	// Data segment split into entries. Boundaries between entries are
+	// recovered from data pointers found in program text.
+	Data []Data
+
+	// Name of entrypoint function.
+	Entry string
+
+	DataSize uint32
+
+	// Size of global segment. It cannot be represented in assembly.
+	GlobalSize uint32
+}
+
+// Data is a data segment entry.
+type Data struct {
+	Name string
+
+	Val []byte
+
+	// Offset of entry start in data segment.
+	Offset uint32
+}
+
+// Fun is a decoded function with labels placed inside its body.
+type Fun struct {
+	Name string
+
+	Code []Instruction
+
+	// Maps text offset to names of labels placed at that offset.
+	// Offset may be equal to function end offset, such labels are
+	// placed after the last instruction.
+	Labels map[uint32][]string
+
+	// Offset of function start in program text.
+	Start uint32
+
+	// Offset right after function last instruction.
+	End uint32
+}
+
+// Analyze decodes program text, splits it into functions and
+// recovers labels for jump targets.
+func Analyze(prog *kvx.Program) (*Program, error) {
+	if len(prog.Text) == 0 {
+		return nil, fmt.Errorf("empty program text")
+	}
+
+	var code []Instruction
+	// maps instruction offset to its index in code
+	index := make(map[uint32]int)
+	var offset uint32
+	for offset < uint32(len(prog.Text)) {
+		s, err := Decode(prog.Text, offset)
+		if err != nil {
+			return nil, err
+		}
+		index[offset] = len(code)
+		code = append(code, s)
+		offset += s.Size
+	}
+	end := offset
+
+	// maps function start offset to its name
+	names := make(map[uint32]string)
+	if prog.Symbols != nil {
+		for _, s := range prog.Symbols.Funs {
+			names[s.Offset] = s.Name
+		}
+	}
+	starts := []uint32{0, prog.EntryPoint}
+	for offset := range names {
+		starts = append(starts, offset)
+	}
+	for _, s := range code {
+		if s.Opcode != opc.Call {
+			continue
+		}
+		target, ok := s.Target()
+		if ok {
+			starts = append(starts, target)
+		}
+	}
+	slices.Sort(starts)
+	starts = slices.Compact(starts)
+
+	p := &Program{
+		DataSize:   uint32(len(prog.Data)),
+		GlobalSize: prog.GlobalSize,
+	}
+	p.splitData(prog.Data, code)
+	for i, start := range starts {
+		k, ok := index[start]
+		if !ok {
+			return nil, fmt.Errorf("function start 0x%08X is not at instruction boundary", start)
+		}
+
+		fend := end
+		if i+1 < len(starts) {
+			fend = starts[i+1]
+		}
+		j := len(code)
+		if i+1 < len(starts) {
+			j, ok = index[fend]
+			if !ok {
+				return nil, fmt.Errorf("function start 0x%08X is not at instruction boundary", fend)
+			}
+		}
+
+		name, ok := names[start]
+		if !ok {
+			name = fmt.Sprintf("f%08X", start)
+		}
+		p.Funs = append(p.Funs, Fun{
+			Name:   name,
+			Code:   code[k:j],
+			Labels: make(map[uint32][]string),
+			Start:  start,
+			End:    fend,
+		})
+	}
+
+	for i := range p.Funs {
+		f := &p.Funs[i]
+		if f.Start == prog.EntryPoint {
+			p.Entry = f.Name
+		}
+	}
+
+	if prog.Symbols != nil {
+		for _, s := range prog.Symbols.Labels {
+			f := p.findFun(s.Offset)
+			if f == nil {
+				return nil, fmt.Errorf("label \"%s\" (at 0x%08X) is outside of program text", s.Name, s.Offset)
+			}
+			_, ok := index[s.Offset]
+			if !ok && s.Offset != end {
+				return nil, fmt.Errorf("label \"%s\" (at 0x%08X) is not at instruction boundary", s.Name, s.Offset)
+			}
+			f.Labels[s.Offset] = append(f.Labels[s.Offset], s.Name)
+		}
+	}
+
+	for i := range p.Funs {
+		err := p.Funs[i].jumpLabels(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Data segment number in pointer encoding. Must be the same as vm.SegData.
+const segData = 0x01
+
+// returns data segment offset if instruction sets register to pointer
+// inside data segment
+func dataPointer(s *Instruction, size uint32) (uint32, bool) {
+	if s.Opcode != opc.Set || len(s.Operands) != 2 {
+		return 0, false
+	}
+	variant, _ := opc.DecodeSetLayout(s.Layout)
+	if variant != opc.SetVal64 {
+		// assembler always uses 64-bit layout for data pointers
+		return 0, false
+	}
+	v := s.Operands[1].Val
+	if v>>56 != segData {
+		return 0, false
+	}
+	offset := v & 0xFFFFFFFFFFFFFF
+	if offset >= uint64(size) {
+		return 0, false
+	}
+	return uint32(offset), true
+}
+
+// splits data segment into entries, each data pointer from program
+// text starts a new entry
+func (p *Program) splitData(data []byte, code []Instruction) {
+	if len(data) == 0 {
+		return
+	}
+
+	starts := []uint32{0}
+	for i := range code {
+		offset, ok := dataPointer(&code[i], p.DataSize)
+		if ok {
+			starts = append(starts, offset)
+		}
+	}
+	slices.Sort(starts)
+	starts = slices.Compact(starts)
+
+	p.Data = make([]Data, 0, len(starts))
+	for i, start := range starts {
+		end := p.DataSize
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		p.Data = append(p.Data, Data{
+			Name:   fmt.Sprintf("d%d", i),
+			Val:    data[start:end],
+			Offset: start,
+		})
+	}
+}
+
+// returns function which contains a given offset, offset of program
+// text end belongs to the last function
+func (p *Program) findFun(offset uint32) *Fun {
+	for i := range p.Funs {
+		f := &p.Funs[i]
+		if f.Start <= offset && offset < f.End {
+			return f
+		}
+	}
+	last := &p.Funs[len(p.Funs)-1]
+	if offset == last.End {
+		return last
+	}
+	return nil
+}
+
+// assigns labels to jump targets which do not have one
+func (f *Fun) jumpLabels(index map[uint32]int) error {
+	for _, s := range f.Code {
+		if s.Opcode != opc.Jump {
+			continue
+		}
+		target, ok := s.Target()
+		if !ok {
+			continue
+		}
+		if target < f.Start || target > f.End {
+			return fmt.Errorf("jump at 0x%08X targets 0x%08X outside of function \"%s\"", s.Offset, target, f.Name)
+		}
+		_, ok = index[target]
+		if !ok && target != f.End {
+			return fmt.Errorf("jump at 0x%08X targets 0x%08X which is not at instruction boundary", s.Offset, target)
+		}
+		if len(f.Labels[target]) == 0 {
+			f.Labels[target] = []string{fmt.Sprintf("L%08X", target)}
+		}
+	}
+	return nil
+}
+
+// Render writes program in assembly form into w.
+func (p *Program) Render(w io.Writer) error {
+	b := bufio.NewWriter(w)
+
+	if p.GlobalSize != 0 {
+		fmt.Fprintf(b, "// global segment (%d bytes) is not representable in assembly\n", p.GlobalSize)
+	}
+
+	// maps data segment offset to entry name
+	data := make(map[uint32]string, len(p.Data))
+	for _, d := range p.Data {
+		data[d.Offset] = d.Name
+		fmt.Fprintf(b, "#data %s \"%s\";\n", d.Name, char.Escape(string(d.Val)))
+	}
+	if len(p.Data) != 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "#entry %s;\n", p.Entry)
+
+	// maps function start offset to its name
+	funs := make(map[uint32]string, len(p.Funs))
+	for _, f := range p.Funs {
+		funs[f.Start] = f.Name
+	}
+
+	for _, f := range p.Funs {
+		fmt.Fprintf(b, "\n#fun %s {\n", f.Name)
+		for _, s := range f.Code {
+			for _, l := range f.Labels[s.Offset] {
+				fmt.Fprintf(b, "@.%s:\n", l)
+			}
+			b.WriteString("\t")
+			b.WriteString(f.render(&s, funs, data, p.DataSize))
+			b.WriteString(";\n")
+		}
+		for _, l := range f.Labels[f.End] {
+			fmt.Fprintf(b, "@.%s:\n", l)
+		}
+		b.WriteString("}\n")
+	}
+
+	return b.Flush()
+}
+
+// render instruction with jump and call targets, and data pointers
+// replaced by names
+func (f *Fun) render(s *Instruction, funs map[uint32]string, data map[uint32]string, size uint32) string {
+	offset, ok := dataPointer(s, size)
+	if ok {
+		return s.Mnemonic + "\t" + s.Operands[0].String() + ", " + data[offset]
+	}
+
+	target, ok := s.Target()
+	if !ok {
+		return s.String()
+	}
+
+	var name string
+	if s.Opcode == opc.Call {
+		name = funs[target]
+	} else {
+		name = "@." + f.Labels[target][0]
+	}
+
+	var g strings.Builder
+	g.WriteString(s.Mnemonic)
+	if s.Variant != "" {
+		g.WriteByte('.')
+		g.WriteString(s.Variant)
+	}
+	g.WriteByte('\t')
+	g.WriteString(name)
+	return g.String()
+}
@@ -0,0 +1,189 @@
+package disasm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mebyus/ku/goku/vm/asm"
+)
+
+func TestDisassemble(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+
+		// lines which must be present in disassembly
+		lines []string
+	}{
+		{
+			name: "1 halt",
+			code: `
+#entry start;
+
+#fun start {
+	halt;
+}
+`,
+		},
+		{
+			name: "2 entry is not first",
+			code: `
+#entry start;
+
+#fun main {
+	set		#:sc, 0x23;
+	ret;
+}
+
+#fun start {
+	call	main;
+	halt;
+}
+`,
+		},
+		{
+			name: "3 jumps and calls",
+			code: `
+#entry start;
+
+#fun start {
+	set		#:r0, 6;
+	call 	fib;
+	set 	#:sc, #:r0;
+	halt;
+}
+
+#fun fib {
+	test 		#:r0, 1;
+	jump.le		@.exit;
+
+	dec		#:r0;
+	push	#:r0;
+	call	fib;
+	pop		#:r1;
+	push	#:r0;
+	set		#:r0, #:r1;
+	dec		#:r0;
+	call	fib;
+	pop		#:r1;
+	inc		#:r0, #:r1;
+
+@.exit:
+	ret;
+}
+`,
+		},
+		{
+			name: "4 all layouts",
+			code: `
+#entry start;
+
+#fun start {
+@.top:
+	nop;
+	set		#:r1, 0x0300000000000000;
+	set		#:r2, 0x1234;
+	set		#:r3, 0x12345678;
+	set		#:r4, 200;
+	store.u16	#:r1, #:r2, 8;
+	store	#:r1, #:r2;
+	store.u8	0x0300000000000010, #:r2;
+	load.u32	#:r3, #:r1, 8;
+	load	#:r3, #:r1;
+	load.u8	#:r5, 0x0300000000000010;
+	add		#:r3, #:r1, #:r2;
+	sub		#:r3, 7;
+	mul		#:r3, 0x123456789A;
+	div		#:r3, #:r3, #:r4;
+	rem		#:r3, 3;
+	and		#:r3, 0xFF;
+	or		#:r3, #:r2;
+	xor		#:r3, 1;
+	shl		#:r3, 2;
+	shr		#:r3, #:r4;
+	inc		#:r3, 100;
+	inc		#:r3, 0x1234567890;
+	dec		#:r3, #:r4;
+	clear	#:r3;
+	push	7;
+	pop		#:r6;
+	test	#:r3, #:r4;
+	test	#:r3, 0x1234;
+	jump.nz	@.top;
+	jump.ae	@.end;
+	jump	#:r7;
+	call	#:r7;
+	syscall;
+	trap;
+@.end:
+}
+`,
+		},
+		{
+			name: "5 data",
+			code: `
+#data hello "Hello, world!\n";
+#data quote "say \"hi\"\t\\";
+#data tail "not referenced";
+
+#entry start;
+
+#fun start {
+	set		#:r1, hello;
+	set		#:r2, quote;
+	set		#:r3, hello;
+	set		#:r4, 0x0100000000001000;
+	halt;
+}
+`,
+			lines: []string{
+				`#data d0 "Hello, world!\n";`,
+				`#data d1 "say \"hi\"\t\\not referenced";`,
+				"\tset\t#:r1, d0;",
+				"\tset\t#:r2, d1;",
+				"\tset\t#:r3, d0;",
+				"\tset\t#:r4, 0x100000000001000;",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := asm.Compile(strings.NewReader(tt.code))
+			if err != nil {
+				t.Fatalf("asm.Compile() error = %v", err)
+			}
+
+			for _, strip := range []bool{false, true} {
+				if strip {
+					want.Symbols = nil
+				}
+
+				var out bytes.Buffer
+				err = Disassemble(&out, want)
+				if err != nil {
+					t.Fatalf("Disassemble(strip=%v) error = %v", strip, err)
+				}
+
+				got, err := asm.Compile(bytes.NewReader(out.Bytes()))
+				if err != nil {
+					t.Fatalf("asm.Compile(strip=%v) error = %v, text:\n%s", strip, err, out.String())
+				}
+				if !bytes.Equal(got.Text, want.Text) {
+					t.Errorf("reassembled text (strip=%v) differs, disassembly:\n%s", strip, out.String())
+				}
+				if got.EntryPoint != want.EntryPoint {
+					t.Errorf("reassembled EntryPoint (strip=%v) = 0x%X, want 0x%X", strip, got.EntryPoint, want.EntryPoint)
+				}
+				if !bytes.Equal(got.Data, want.Data) {
+					t.Errorf("reassembled Data (strip=%v) = %q, want %q", strip, got.Data, want.Data)
+				}
+				for _, line := range tt.lines {
+					if !strings.Contains(out.String(), line+"\n") {
+						t.Errorf("disassembly (strip=%v) does not contain line %q:\n%s", strip, line, out.String())
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+/*
+Package disasm decodes VM instructions from program text and renders
+them in assembly form.
+*/
+package disasm
@@ -0,0 +1,111 @@
+package disasm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// OperandKind indicates how operand value should be interpreted.
+type OperandKind uint8
+
+const (
+	// Register name.
+	Reg OperandKind = iota + 1
+
+	// Immediate integer value.
+	Imm
+
+	// Immediate pointer value (with segment in highest byte).
+	Ptr
+
+	// Address in program text. Used by jump and call instructions.
+	Addr
+)
+
+// Operand describes decoded instruction operand.
+type Operand struct {
+	// Register name for Reg operand, value for other kinds.
+	Val uint64
+
+	Kind OperandKind
+}
+
+func (o Operand) String() string {
+	switch o.Kind {
+	case Reg:
+		return "#:" + opc.Register(o.Val).String()
+	case Imm:
+		if o.Val < 10 {
+			return strconv.FormatUint(o.Val, 10)
+		}
+		return "0x" + strings.ToUpper(strconv.FormatUint(o.Val, 16))
+	case Ptr:
+		return "0x" + leftPad(strconv.FormatUint(o.Val, 16), 16)
+	case Addr:
+		return "0x" + leftPad(strconv.FormatUint(o.Val, 16), 8)
+	default:
+		panic("unexpected operand kind " + strconv.Itoa(int(o.Kind)))
+	}
+}
+
+func leftPad(s string, n int) string {
+	s = strings.ToUpper(s)
+	if len(s) >= n {
+		return s
+	}
+	return strings.Repeat("0", n-len(s)) + s
+}
+
+// Instruction describes decoded instruction.
+type Instruction struct {
+	Operands []Operand
+
+	// Instruction mnemonic as used in assembly.
+	Mnemonic string
+
+	// Optional mnemonic variant, for example jump condition.
+	Variant string
+
+	// Offset of instruction in program text.
+	Offset uint32
+
+	// Total instruction size in bytes (including opcode and layout).
+	Size uint32
+
+	Opcode opc.Opcode
+
+	Layout uint8
+}
+
+// String renders instruction in assembly form without trailing semicolon.
+func (s *Instruction) String() string {
+	var b strings.Builder
+	b.WriteString(s.Mnemonic)
+	if s.Variant != "" {
+		b.WriteByte('.')
+		b.WriteString(s.Variant)
+	}
+	for i, o := range s.Operands {
+		if i == 0 {
+			b.WriteByte('\t')
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(o.String())
+	}
+	return b.String()
+}
+
+// Target returns text address of jump or call instruction with
+// immediate destination. Returns false for other instructions.
+func (s *Instruction) Target() (uint32, bool) {
+	if s.Opcode != opc.Jump && s.Opcode != opc.Call {
+		return 0, false
+	}
+	if len(s.Operands) != 1 || s.Operands[0].Kind != Addr {
+		return 0, false
+	}
+	return uint32(s.Operands[0].Val), true
+}
@@ -0,0 +1,70 @@
+package disasm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mebyus/ku/goku/vm/kvx"
+)
+
+// Listing writes all instructions from program text into w. Each line
+// contains instruction offset, its raw bytes and assembly form.
+//
+// If program has symbol table, then function names and labels are
+// printed before instructions they point to.
+func Listing(w io.Writer, prog *kvx.Program) error {
+	b := bufio.NewWriter(w)
+
+	var funs, labels []kvx.Symbol
+	if prog.Symbols != nil {
+		funs = prog.Symbols.Funs
+		labels = prog.Symbols.Labels
+	}
+
+	var offset uint32
+	for offset < uint32(len(prog.Text)) {
+		for len(funs) != 0 && funs[0].Offset <= offset {
+			if funs[0].Offset == offset {
+				fmt.Fprintf(b, "\n%s:\n", funs[0].Name)
+			}
+			funs = funs[1:]
+		}
+		for len(labels) != 0 && labels[0].Offset <= offset {
+			if labels[0].Offset == offset {
+				fmt.Fprintf(b, "@.%s:\n", labels[0].Name)
+			}
+			labels = labels[1:]
+		}
+
+		s, err := Decode(prog.Text, offset)
+		if err != nil {
+			b.Flush()
+			return err
+		}
+		raw := prog.Text[offset : offset+s.Size]
+		fmt.Fprintf(b, "  %08X  %-33s  %s\n", offset, hexBytes(raw), s.String())
+		offset += s.Size
+	}
+
+	return b.Flush()
+}
+
+// format bytes as space separated hex pairs, long sequences are truncated
+func hexBytes(raw []byte) string {
+	const max = 10
+
+	var g strings.Builder
+	for i, x := range raw {
+		if i == max {
+			g.WriteString(" ..")
+			break
+		}
+		if i != 0 {
+			g.WriteByte(' ')
+		}
+		fmt.Fprintf(&g, "%02X", x)
+	}
+	return g.String()
+}
@@ -0,0 +1,143 @@
+package vm
+
+import (
+	"slices"
+)
+
+// HeapAlign is alignment of heap blocks returned by allocation syscall.
+const HeapAlign = 8
+
+// Heap block descriptor.
+type block struct {
+	// Offset in heap segment.
+	offset uint32
+
+	// Size in bytes, always multiple of HeapAlign.
+	size uint32
+}
+
+// heapAllocator implements first-fit allocation of heap blocks.
+// Block metadata is kept outside of machine memory, thus programs
+// cannot corrupt it.
+type heapAllocator struct {
+	// Maps offset of live block to its size.
+	live map[uint32]uint32
+
+	// Free blocks sorted by offset. Adjacent free blocks are
+	// always merged.
+	free []block
+}
+
+func (a *heapAllocator) reset() {
+	clear(a.live)
+	a.free = a.free[:0]
+}
+
+func alignHeap(n uint64) uint64 {
+	return (n + HeapAlign - 1) &^ (HeapAlign - 1)
+}
+
+// allocate heap block of at least n bytes, returns pointer to the
+// block and ok flag
+func (m *Machine) heapAlloc(n uint64) (uint64, bool) {
+	if n == 0 || n > MaxHeapSize {
+		return 0, false
+	}
+	size := uint32(alignHeap(n))
+	a := &m.alloc
+	if a.live == nil {
+		a.live = make(map[uint32]uint32)
+	}
+
+	for i, b := range a.free {
+		if b.size < size {
+			continue
+		}
+		if b.size == size {
+			a.free = slices.Delete(a.free, i, i+1)
+		} else {
+			a.free[i] = block{
+				offset: b.offset + size,
+				size:   b.size - size,
+			}
+		}
+		clear(m.heap[b.offset : b.offset+size])
+		a.live[b.offset] = size
+		return uint64(SegHeap)<<56 | uint64(b.offset), true
+	}
+
+	// no suitable free block, place new block at the end of heap
+	start := alignHeap(uint64(len(m.heap)))
+	end := start + uint64(size)
+	if end > MaxHeapSize {
+		return 0, false
+	}
+	m.heap = append(m.heap, make([]byte, end-uint64(len(m.heap)))...)
+	a.live[uint32(start)] = size
+	return uint64(SegHeap)<<56 | start, true
+}
+
+// free heap block previously obtained from heapAlloc
+func (m *Machine) heapFree(ptr uint64) *RuntimeError {
+	segment, offset := getPointerSegmentAndOffset(ptr)
+	const high = 0x00FFFFFF00000000
+	a := &m.alloc
+	size, ok := a.live[offset]
+	if segment != SegHeap || ptr&high != 0 || !ok {
+		return &RuntimeError{
+			Code: ErrorBadFree,
+			Aux:  ptr,
+		}
+	}
+	delete(a.live, offset)
+
+	i, _ := slices.BinarySearchFunc(a.free, offset, func(b block, offset uint32) int {
+		return int(int64(b.offset) - int64(offset))
+	})
+	a.free = slices.Insert(a.free, i, block{offset: offset, size: size})
+
+	// merge with next block
+	if i+1 < len(a.free) && a.free[i].offset+a.free[i].size == a.free[i+1].offset {
+		a.free[i].size += a.free[i+1].size
+		a.free = slices.Delete(a.free, i+1, i+2)
+	}
+	// merge with previous block
+	if i > 0 && a.free[i-1].offset+a.free[i-1].size == a.free[i].offset {
+		a.free[i-1].size += a.free[i].size
+		a.free = slices.Delete(a.free, i, i+1)
+		i -= 1
+	}
+
+	// give memory back if freed block is at the end of heap
+	b := a.free[i]
+	if uint64(b.offset)+uint64(b.size) == uint64(len(m.heap)) {
+		m.heap = m.heap[:b.offset]
+		a.free = a.free[:i]
+	}
+	return nil
+}
+
+// reports whether any of n bytes at offset lie inside free heap block
+func (a *heapAllocator) freed(offset, n uint32) bool {
+	// first free block which ends after accessed offset
+	i, _ := slices.BinarySearchFunc(a.free, offset, func(b block, offset uint32) int {
+		end := uint64(b.offset) + uint64(b.size)
+		if end <= uint64(offset) {
+			return -1
+		}
+		return 1
+	})
+	if i >= len(a.free) {
+		return false
+	}
+	return uint64(a.free[i].offset) < uint64(offset)+uint64(n)
+}
+
+// HeapBlocks returns number of live heap blocks and their total size.
+func (m *Machine) HeapBlocks() (int, uint64) {
+	var total uint64
+	for _, size := range m.alloc.live {
+		total += uint64(size)
+	}
+	return len(m.alloc.live), total
+}
@@ -0,0 +1,87 @@
+package vm
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// Host provides machine access to outside world. All syscalls which
+// interact with environment outside of machine memory go through host.
+type Host interface {
+	// Write bytes to file descriptor. Returns number of written bytes.
+	Write(fd uint64, b []byte) (int, error)
+
+	// Read bytes from file descriptor. Returns number of read bytes.
+	// Zero bytes and nil error mean end of input.
+	Read(fd uint64, b []byte) (int, error)
+
+	// Clock returns host monotonic time in nanoseconds.
+	Clock() uint64
+}
+
+// Standard file descriptors available to programs.
+const (
+	FdStdin  = 0
+	FdStdout = 1
+	FdStderr = 2
+)
+
+// IOHost implements Host on top of arbitrary readers and writers.
+// Nil fields act as closed descriptors.
+type IOHost struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Clock start time. Zero value means start of unix epoch.
+	Start time.Time
+}
+
+// Explicit interface implementation check.
+var _ Host = &IOHost{}
+
+// NewOSHost creates host connected to process standard streams.
+func NewOSHost() *IOHost {
+	return &IOHost{
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Start:  time.Now(),
+	}
+}
+
+var errBadDescriptor = errors.New("bad file descriptor")
+
+func (h *IOHost) Write(fd uint64, b []byte) (int, error) {
+	var w io.Writer
+	switch fd {
+	case FdStdout:
+		w = h.Stdout
+	case FdStderr:
+		w = h.Stderr
+	}
+	if w == nil {
+		return 0, errBadDescriptor
+	}
+	return w.Write(b)
+}
+
+func (h *IOHost) Read(fd uint64, b []byte) (int, error) {
+	if fd != FdStdin || h.Stdin == nil {
+		return 0, errBadDescriptor
+	}
+	n, err := h.Stdin.Read(b)
+	if err == io.EOF {
+		return n, nil
+	}
+	return n, err
+}
+
+func (h *IOHost) Clock() uint64 {
+	if h.Start.IsZero() {
+		return uint64(time.Now().UnixNano())
+	}
+	return uint64(time.Since(h.Start).Nanoseconds())
+}
@@ -0,0 +1,74 @@
+package vm
+
+import (
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// Registers is a snapshot of machine registers.
+type Registers struct {
+	// General-purpose registers.
+	R [64]uint64
+
+	IP    uint64
+	SP    uint64
+	FP    uint64
+	SC    uint64
+	CF    uint64
+	Clock uint64
+}
+
+// Registers returns snapshot of machine registers.
+func (m *Machine) Registers() Registers {
+	return Registers{
+		R:     m.r,
+		IP:    m.ip,
+		SP:    m.sp,
+		FP:    m.fp,
+		SC:    m.sc,
+		CF:    m.cf,
+		Clock: m.clock,
+	}
+}
+
+// Reg returns value of a given register.
+func (m *Machine) Reg(r opc.Register) (uint64, *RuntimeError) {
+	return m.get(r)
+}
+
+// Frames returns copy of call frames stack. Innermost frame goes last.
+func (m *Machine) Frames() []Frame {
+	frames := make([]Frame, len(m.frames))
+	copy(frames, m.frames)
+	return frames
+}
+
+// Memory returns copy of n bytes of machine memory at a given pointer.
+// Pointer must carry memory segment in its highest byte.
+func (m *Machine) Memory(ptr uint64, n uint32) ([]byte, *RuntimeError) {
+	b, err := m.memslice(ptr, n)
+	if err != nil {
+		return nil, err
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c, nil
+}
+
+// SegmentSize returns current size of a given memory segment.
+// Returns 0 for unknown segments.
+func (m *Machine) SegmentSize(segment uint8) uint64 {
+	switch segment {
+	case SegText:
+		return uint64(len(m.text))
+	case SegData:
+		return uint64(len(m.data))
+	case SegGlobal:
+		return uint64(len(m.global))
+	case SegStack:
+		return uint64(len(m.stack))
+	case SegHeap:
+		return uint64(len(m.heap))
+	default:
+		return 0
+	}
+}
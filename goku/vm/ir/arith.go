@@ -0,0 +1,30 @@
+package ir
+
+import "github.com/mebyus/ku/goku/vm/opc"
+
+// BinReg represents instruction with generic layout which takes both
+// source operands from registers.
+type BinReg struct {
+	nodeAtom
+
+	// One of arithmetic or bitwise opcodes.
+	Op opc.Opcode
+
+	Dest opc.Register
+	A    opc.Register
+	B    opc.Register
+}
+
+// BinVal represents instruction with generic layout which takes second
+// source operand from immediate value.
+type BinVal struct {
+	nodeAtom
+
+	Val uint64
+
+	// One of arithmetic or bitwise opcodes.
+	Op opc.Opcode
+
+	Dest opc.Register
+	A    opc.Register
+}
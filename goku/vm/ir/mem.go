@@ -0,0 +1,45 @@
+package ir
+
+import "github.com/mebyus/ku/goku/vm/opc"
+
+// LoadReg represents load from address stored in register plus offset.
+type LoadReg struct {
+	nodeAtom
+
+	Offset uint32
+
+	Size opc.MemSize
+	Dest opc.Register
+	Ptr  opc.Register
+}
+
+// LoadVal represents load from immediate address.
+type LoadVal struct {
+	nodeAtom
+
+	Ptr uint64
+
+	Size opc.MemSize
+	Dest opc.Register
+}
+
+// StoreReg represents store to address stored in register plus offset.
+type StoreReg struct {
+	nodeAtom
+
+	Offset uint32
+
+	Size   opc.MemSize
+	Ptr    opc.Register
+	Source opc.Register
+}
+
+// StoreVal represents store to immediate address.
+type StoreVal struct {
+	nodeAtom
+
+	Ptr uint64
+
+	Size   opc.MemSize
+	Source opc.Register
+}
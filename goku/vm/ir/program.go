@@ -1,5 +1,7 @@
 package ir
 
+import "github.com/mebyus/ku/goku/compiler/sm"
+
 type Program struct {
 	// Valid program must have at least one function.
 	//
@@ -14,6 +16,12 @@ type Program struct {
 
 	// Total number of distinct labels inside the program.
 	LabelsCount uint32
+
+	// Optional. Source names of functions, indexed by function integer name.
+	FunNames []string
+
+	// Optional. Source names of labels, indexed by label integer name.
+	LabelNames []string
 }
 
 // Fun represents a function inside a program.
@@ -22,6 +30,10 @@ type Fun struct {
 	// Atoms constitute function body (code).
 	Atoms []Atom
 
+	// Optional. Source position of each atom, indexed in the same
+	// way as Atoms.
+	Pins []sm.Pin
+
 	Name FunName
 }
 
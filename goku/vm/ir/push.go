@@ -13,3 +13,9 @@ type PopReg struct {
 
 	Reg opc.Register
 }
+
+type PushVal struct {
+	nodeAtom
+
+	Val uint32
+}
@@ -15,3 +15,11 @@ type SetVal struct {
 	Val  uint64
 	Dest opc.Register
 }
+
+// SetData places pointer to data entry into destination register.
+type SetData struct {
+	nodeAtom
+
+	Data Data
+	Dest opc.Register
+}
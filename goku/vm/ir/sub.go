@@ -9,3 +9,11 @@ type TestVal struct {
 	Dest opc.Register
 	Val  uint64
 }
+
+// TestReg compare two registers.
+type TestReg struct {
+	nodeAtom
+
+	Dest   opc.Register
+	Source opc.Register
+}
@@ -65,9 +65,17 @@ func (m *Machine) execJump(lt uint8) (uint64, *RuntimeError) {
 	return size, nil
 }
 
-// Bit flags in CF register.
+// Bit flags in CF register. Set by Test instruction which compares
+// destination operand x with source operand y.
 const (
+	// x == y
 	FlagZero = 1 << 0
+
+	// x < y, signed comparison
+	FlagLess = 1 << 1
+
+	// x < y, unsigned comparison
+	FlagBelow = 1 << 2
 )
 
 func (m *Machine) checkFlag(flag opc.JumpFlag) (bool, *RuntimeError) {
@@ -78,6 +86,22 @@ func (m *Machine) checkFlag(flag opc.JumpFlag) (bool, *RuntimeError) {
 		return m.cf&FlagZero != 0, nil
 	case opc.FlagNZ:
 		return m.cf&FlagZero == 0, nil
+	case opc.FlagL:
+		return m.cf&FlagLess != 0, nil
+	case opc.FlagLE:
+		return m.cf&(FlagLess|FlagZero) != 0, nil
+	case opc.FlagG:
+		return m.cf&(FlagLess|FlagZero) == 0, nil
+	case opc.FlagGE:
+		return m.cf&FlagLess == 0, nil
+	case opc.FlagB:
+		return m.cf&FlagBelow != 0, nil
+	case opc.FlagBE:
+		return m.cf&(FlagBelow|FlagZero) != 0, nil
+	case opc.FlagA:
+		return m.cf&(FlagBelow|FlagZero) == 0, nil
+	case opc.FlagAE:
+		return m.cf&FlagBelow == 0, nil
 	default:
 		return false, &RuntimeError{
 			Code: ErrorBadJumpFlag,
@@ -2,6 +2,7 @@ package kvx
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -17,30 +18,97 @@ func Load(path string) (*Program, error) {
 	return Decode(file)
 }
 
+// Decode reads program from a given input. Checks that all segments
+// are located inside the input and that entry point is inside text
+// segment.
 func Decode(in io.ReadSeeker) (*Program, error) {
-	g := Decoder{in: in}
-	var h Header
+	f, err := DecodeFile(in)
+	if err != nil {
+		return nil, err
+	}
+	return f.Program, nil
+}
+
+// LoadFile is the same as Load, but also returns decoded file header.
+func LoadFile(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
 
-	err := g.header(&h)
+	return DecodeFile(file)
+}
+
+// DecodeFile is the same as Decode, but also returns decoded file header.
+func DecodeFile(in io.ReadSeeker) (*File, error) {
+	size, err := in.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	_, err = in.Seek(0, io.SeekStart)
 	if err != nil {
 		return nil, err
 	}
 
-	text, err := g.data(h.Text.Offset, h.Text.Size)
+	g := Decoder{in: in, size: uint64(size)}
+	var h Header
+
+	err = g.header(&h)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := g.data(h.Data.Offset, h.Data.Size)
+	text, err := g.segment("text", h.Text)
+	if err != nil {
+		return nil, err
+	}
+	data, err := g.segment("data", h.Data)
+	if err != nil {
+		return nil, err
+	}
+	symbols, err := g.segment("symbols", h.Symbols)
+	if err != nil {
+		return nil, err
+	}
+	source, err := g.segment("source map", h.Source)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Program{
-		Text: text,
-		Data: data,
+	if h.EntryPoint != 0 && h.EntryPoint >= h.Text.Size {
+		return nil, fmt.Errorf("entry point (=0x%08X) is outside of text segment (size=0x%08X)", h.EntryPoint, h.Text.Size)
+	}
 
-		GlobalSize: h.Global.Size,
+	var table *SymbolTable
+	if len(symbols) != 0 {
+		table, err = decodeSymbols(symbols, h.Text.Size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var sourceMap *SourceMap
+	if len(source) != 0 {
+		sourceMap, err = decodeSourceMap(source, h.Text.Size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &File{
+		Header: h,
+		Program: &Program{
+			Text:    text,
+			Data:    data,
+			Symbols: table,
+			Source:  sourceMap,
+
+			EntryPoint: h.EntryPoint,
+			GlobalSize: h.Global.Size,
+		},
+		symbols: symbols,
+		source:  source,
 	}, nil
 }
 
@@ -50,12 +118,18 @@ type Decoder struct {
 	in io.ReadSeeker
 
 	pos uint64
+
+	// Total input size in bytes.
+	size uint64
 }
 
 func (g *Decoder) header(h *Header) error {
 	g.buf = make([]byte, HeaderSize)
 	n, err := io.ReadFull(g.in, g.buf)
 	g.pos += uint64(n)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return fmt.Errorf("truncated header: got %d bytes, want %d", n, HeaderSize)
+	}
 	if err != nil {
 		return err
 	}
@@ -66,29 +140,42 @@ func (g *Decoder) header(h *Header) error {
 	}
 
 	version := val32(g.buf[4:8])
-	if version != 0 {
-		return fmt.Errorf("bad version: %d", version)
+	if version != Version {
+		return fmt.Errorf("unsupported version %d (want %d)", version, Version)
 	}
+	h.Version = version
+	h.EntryPoint = val32(g.buf[8:12])
 
-	h.Text = SegmentHeader{
-		Offset: val64(g.buf[8:16]),
-		Size:   val32(g.buf[16:20]),
-		Flags:  val32(g.buf[20:24]),
-	}
+	h.Text = decodeSegmentHeader(g.buf[16:32])
+	h.Data = decodeSegmentHeader(g.buf[32:48])
+	h.Global = decodeSegmentHeader(g.buf[48:64])
+	h.Symbols = decodeSegmentHeader(g.buf[64:80])
+	h.Source = decodeSegmentHeader(g.buf[80:96])
 
-	h.Data = SegmentHeader{
-		Offset: val64(g.buf[24:32]),
-		Size:   val32(g.buf[32:36]),
-		Flags:  val32(g.buf[36:40]),
-	}
+	return nil
+}
 
-	h.Global = SegmentHeader{
-		Offset: val64(g.buf[40:48]),
-		Size:   val32(g.buf[48:52]),
-		Flags:  val32(g.buf[52:56]),
+func decodeSegmentHeader(b []byte) SegmentHeader {
+	return SegmentHeader{
+		Offset: val64(b[0:8]),
+		Size:   val32(b[8:12]),
+		Flags:  val32(b[12:16]),
 	}
+}
 
-	return nil
+// checks segment bounds and reads its contents
+func (g *Decoder) segment(name string, h SegmentHeader) ([]byte, error) {
+	if h.Size == 0 {
+		return nil, nil
+	}
+	if h.Offset < HeaderSize {
+		return nil, fmt.Errorf("%s segment offset (=0x%X) overlaps with header", name, h.Offset)
+	}
+	if h.Offset > g.size || uint64(h.Size) > g.size-h.Offset {
+		return nil, fmt.Errorf("truncated file: %s segment (offset=0x%X, size=0x%X) ends beyond file size (=0x%X)",
+			name, h.Offset, h.Size, g.size)
+	}
+	return g.data(h.Offset, h.Size)
 }
 
 // allocates and reads exactly size bytes at specified offset
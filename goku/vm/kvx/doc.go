@@ -14,18 +14,33 @@ Global segment only has entry inside the Header, because it is
 always initialized to zero, and thus format does not need to
 encode it.
 
+Optional symbol table maps function and label names to their
+offsets in Text segment. It is not used during execution.
+Symbol table with zero size in the Header means that file has
+no symbol table.
+
+Optional source map maps instruction offsets in Text segment to
+line and column in source file. Source map with zero size in the
+Header means that file has no source map.
+
 Each segment with raw binary is aligned by 8-byte boundary.
 
 All integers are stored in little endian.
 
-Byte layout:
+Byte layout (version 2):
+
+	0:  [XX XX XX XX] 4 // Magic
+	4:  [XX XX XX XX] 4 // Version
+	8:  [XX XX XX XX] 4 // Entry point offset in Text segment
+	12: [XX XX XX XX] 4 // Reserved
 
-	0: [XX XX XX XX] 4 // Magic
-	4: [XX XX XX XX] 4 // Version
-	8: [XX XX XX XX] 4 // Entry point offset
+	16: [XX XX XX XX XX XX XX XX] 8 // File offset of Text segment
+	24: [XX XX XX XX]             4 // Text segment size in bytes
+	28: [XX XX XX XX]             4 // Text segment flags
 
-	12: [XX XX XX XX XX XX XX XX] 8 // File offset of Text segment
-	20: [XX XX XX XX]             4 // Text segment size in bytes
-	24: [XX XX XX XX]             4 // Text segment flags
+	32: [...] 16 // Data segment header
+	48: [...] 16 // Global segment header (offset is ignored)
+	64: [...] 16 // Symbol table header
+	80: [...] 16 // Source map header
 */
 package kvx
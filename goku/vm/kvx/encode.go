@@ -22,10 +22,14 @@ func Encode(out io.Writer, prog *Program) error {
 
 	g.bufString(Magic)
 	g.bufVal32(file.Header.Version)
+	g.bufVal32(file.Header.EntryPoint)
+	g.bufVal32(0) // reserved
 
 	g.bufSegmentHeader(file.Header.Text)
 	g.bufSegmentHeader(file.Header.Data)
 	g.bufSegmentHeader(file.Header.Global)
+	g.bufSegmentHeader(file.Header.Symbols)
+	g.bufSegmentHeader(file.Header.Source)
 	err := g.flush()
 	if err != nil {
 		return err
@@ -39,6 +43,18 @@ func Encode(out io.Writer, prog *Program) error {
 	if err != nil {
 		return err
 	}
+	if len(file.symbols) != 0 {
+		err = g.writeAt(file.symbols, file.Header.Symbols.Offset)
+		if err != nil {
+			return err
+		}
+	}
+	if len(file.source) != 0 {
+		err = g.writeAt(file.source, file.Header.Source.Offset)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -2,6 +2,8 @@ package kvx
 
 import (
 	"bytes"
+	"encoding/binary"
+	"reflect"
 	"testing"
 )
 
@@ -12,7 +14,11 @@ func TestEncode(t *testing.T) {
 		text string
 		data string
 
+		symbols *SymbolTable
+		source  *SourceMap
+
 		gsize uint32
+		entry uint32
 	}{
 		{
 			name: "1 empty program",
@@ -49,6 +55,37 @@ func TestEncode(t *testing.T) {
 			data:  "Hello Data Hello Data",
 			gsize: 9230,
 		},
+		{
+			name: "6 entry and symbols",
+
+			text:  "Hello Text Hello Text",
+			data:  "Hello Data",
+			gsize: 8,
+			entry: 6,
+			symbols: &SymbolTable{
+				Funs: []Symbol{
+					{Name: "start", Offset: 0},
+					{Name: "main", Offset: 6},
+				},
+				Labels: []Symbol{
+					{Name: "exit", Offset: 21},
+				},
+			},
+		},
+		{
+			name: "7 source map",
+
+			text: "Hello Text Hello Text",
+			data: "",
+			source: &SourceMap{
+				Path: "main.kasm",
+				Lines: []Line{
+					{Offset: 0, Line: 3, Column: 1},
+					{Offset: 4, Line: 4, Column: 1},
+					{Offset: 20, Line: 7, Column: 8},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -56,6 +93,9 @@ func TestEncode(t *testing.T) {
 				Text:       []byte(tt.text),
 				Data:       []byte(tt.data),
 				GlobalSize: tt.gsize,
+				EntryPoint: tt.entry,
+				Symbols:    tt.symbols,
+				Source:     tt.source,
 			}
 
 			var out bytes.Buffer
@@ -80,6 +120,94 @@ func TestEncode(t *testing.T) {
 			if progOut.GlobalSize != progIn.GlobalSize {
 				t.Errorf("Decode() GlobalSize = %d, want %d", progOut.GlobalSize, progIn.GlobalSize)
 			}
+			if progOut.EntryPoint != progIn.EntryPoint {
+				t.Errorf("Decode() EntryPoint = %d, want %d", progOut.EntryPoint, progIn.EntryPoint)
+			}
+			if !reflect.DeepEqual(progOut.Symbols, progIn.Symbols) {
+				t.Errorf("Decode() Symbols = %+v, want %+v", progOut.Symbols, progIn.Symbols)
+			}
+			if !reflect.DeepEqual(progOut.Source, progIn.Source) {
+				t.Errorf("Decode() Source = %+v, want %+v", progOut.Source, progIn.Source)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	prog := &Program{
+		Text:       []byte("Hello Text"),
+		Data:       []byte("Hello Data"),
+		EntryPoint: 2,
+		Symbols: &SymbolTable{
+			Funs: []Symbol{{Name: "start", Offset: 2}},
+		},
+	}
+	var out bytes.Buffer
+	err := Encode(&out, prog)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	valid := out.Bytes()
+
+	tests := []struct {
+		name string
+
+		// modifies a copy of valid encoded program
+		edit func(b []byte) []byte
+	}{
+		{
+			name: "1 truncated header",
+			edit: func(b []byte) []byte { return b[:HeaderSize-1] },
+		},
+		{
+			name: "2 truncated symbols",
+			edit: func(b []byte) []byte { return b[:len(b)-1] },
+		},
+		{
+			name: "3 bad version",
+			edit: func(b []byte) []byte {
+				binary.LittleEndian.PutUint32(b[4:], 0)
+				return b
+			},
+		},
+		{
+			name: "4 entry point outside text",
+			edit: func(b []byte) []byte {
+				binary.LittleEndian.PutUint32(b[8:], 10)
+				return b
+			},
+		},
+		{
+			name: "5 text overlaps header",
+			edit: func(b []byte) []byte {
+				binary.LittleEndian.PutUint64(b[16:], 8)
+				return b
+			},
+		},
+		{
+			name: "6 data beyond file end",
+			edit: func(b []byte) []byte {
+				binary.LittleEndian.PutUint64(b[32:], uint64(len(b)))
+				return b
+			},
+		},
+		{
+			name: "7 symbol offset outside text",
+			edit: func(b []byte) []byte {
+				// first symbol offset goes right after symbol counts
+				offset := binary.LittleEndian.Uint64(b[64:])
+				binary.LittleEndian.PutUint32(b[offset+8:], 100)
+				return b
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.edit(bytes.Clone(valid))
+			_, err := Decode(bytes.NewReader(b))
+			if err == nil {
+				t.Errorf("Decode() error = <nil>, want error")
+			}
 		})
 	}
 }
@@ -1,17 +1,100 @@
 package kvx
 
+import (
+	"cmp"
+	"slices"
+)
+
 // Program holds program information in a form that is suitable
 // for VM execution.
 type Program struct {
 	Text []byte
 	Data []byte
 
+	// Optional. Equals nil if program was encoded without symbol table.
+	Symbols *SymbolTable
+
+	// Optional. Equals nil if program was encoded without source map.
+	Source *SourceMap
+
 	// Offset into program text.
 	EntryPoint uint32
 
 	GlobalSize uint32
 }
 
+// SymbolTable maps names of functions and labels to their offsets
+// in program text. It is not needed for execution, but allows tools
+// to disassemble program and symbolize text offsets.
+type SymbolTable struct {
+	// Stored in order of ascending offsets.
+	Funs []Symbol
+
+	// Stored in order of ascending offsets. Label names are local to
+	// function which contains them.
+	Labels []Symbol
+}
+
+// Symbol describes named place in program text.
+type Symbol struct {
+	Name string
+
+	// Offset into program text.
+	Offset uint32
+}
+
+// FindFun returns function which contains a given text offset.
+// Returns false if there is no such function.
+func (t *SymbolTable) FindFun(offset uint32) (Symbol, bool) {
+	var s Symbol
+	var ok bool
+	for _, f := range t.Funs {
+		if f.Offset > offset {
+			break
+		}
+		s = f
+		ok = true
+	}
+	return s, ok
+}
+
+// SourceMap maps instruction offsets in program text to positions
+// in source file which program was assembled from.
+type SourceMap struct {
+	// Path to source file.
+	Path string
+
+	// Stored in order of ascending offsets.
+	Lines []Line
+}
+
+// Line describes source position of instruction at text offset.
+type Line struct {
+	// Offset into program text.
+	Offset uint32
+
+	// Contains zero-based value.
+	Line uint32
+
+	// Contains zero-based value.
+	Column uint32
+}
+
+// Find returns source position of instruction which contains a given
+// text offset. Returns false if offset is before the first instruction.
+func (m *SourceMap) Find(offset uint32) (Line, bool) {
+	i, ok := slices.BinarySearchFunc(m.Lines, offset, func(l Line, offset uint32) int {
+		return cmp.Compare(l.Offset, offset)
+	})
+	if ok {
+		return m.Lines[i], true
+	}
+	if i == 0 {
+		return Line{}, false
+	}
+	return m.Lines[i-1], true
+}
+
 type SegmentHeader struct {
 	Offset uint64
 	Size   uint32
@@ -23,21 +106,42 @@ type Header struct {
 	Data   SegmentHeader
 	Global SegmentHeader
 
+	// Size is zero if file has no symbol table.
+	Symbols SegmentHeader
+
+	// Size is zero if file has no source map.
+	Source SegmentHeader
+
 	Version uint32
+
+	EntryPoint uint32
 }
 
 type File struct {
 	Header Header
 
 	Program *Program
+
+	// Encoded symbol table. Empty if program has no symbol table.
+	symbols []byte
+
+	// Encoded source map. Empty if program has no source map.
+	source []byte
 }
 
 const Magic = "KVX\x00"
 
+// Version of format produced by encoder. Decoder only accepts
+// files of this version.
+const Version = 2
+
 const HeaderSize = 4 + 4 + // Magic + Version
+	4 + 4 + // Entry point + Reserved
 	16 + // Text Header
 	16 + // Data Header
-	16 // Global Header
+	16 + // Global Header
+	16 + // Symbols Header
+	16 // Source Map Header
 
 func NewFile(prog *Program) *File {
 	var offset uint64
@@ -59,13 +163,42 @@ func NewFile(prog *Program) *File {
 		Size:   prog.GlobalSize,
 	}
 
+	offset = alignBy8(offset + uint64(dataHeader.Size))
+	var symbols []byte
+	var symbolsHeader SegmentHeader
+	if prog.Symbols != nil {
+		symbols = encodeSymbols(prog.Symbols)
+		symbolsHeader = SegmentHeader{
+			Offset: offset,
+			Size:   uint32(len(symbols)),
+		}
+		offset = alignBy8(offset + uint64(symbolsHeader.Size))
+	}
+
+	var source []byte
+	var sourceHeader SegmentHeader
+	if prog.Source != nil {
+		source = encodeSourceMap(prog.Source)
+		sourceHeader = SegmentHeader{
+			Offset: offset,
+			Size:   uint32(len(source)),
+		}
+	}
+
 	return &File{
 		Header: Header{
-			Text:   textHeader,
-			Data:   dataHeader,
-			Global: globalHeader,
+			Text:    textHeader,
+			Data:    dataHeader,
+			Global:  globalHeader,
+			Symbols: symbolsHeader,
+			Source:  sourceHeader,
+
+			Version:    Version,
+			EntryPoint: prog.EntryPoint,
 		},
 		Program: prog,
+		symbols: symbols,
+		source:  source,
 	}
 }
 
@@ -0,0 +1,70 @@
+package kvx
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Source map encoding:
+//
+//	[XX XX XX XX] 4 // Path length in bytes
+//	[XX ... XX]   N // Path
+//	[XX XX XX XX] 4 // Number of lines
+//
+// Followed by lines. Each line is encoded as:
+//
+//	[XX XX XX XX] 4 // Text offset
+//	[XX XX XX XX] 4 // Line (zero-based)
+//	[XX XX XX XX] 4 // Column (zero-based)
+func encodeSourceMap(m *SourceMap) []byte {
+	var b []byte
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(m.Path)))
+	b = append(b, m.Path...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(m.Lines)))
+	for _, l := range m.Lines {
+		b = binary.LittleEndian.AppendUint32(b, l.Offset)
+		b = binary.LittleEndian.AppendUint32(b, l.Line)
+		b = binary.LittleEndian.AppendUint32(b, l.Column)
+	}
+	return b
+}
+
+// decodeSourceMap decodes source map and checks that line offsets
+// are ascending and inside text segment of a given size.
+func decodeSourceMap(b []byte, textSize uint32) (*SourceMap, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("truncated source map header")
+	}
+	size := val32(b[0:4])
+	b = b[4:]
+	if uint64(size)+4 > uint64(len(b)) {
+		return nil, fmt.Errorf("truncated source map path")
+	}
+	path := string(b[:size])
+	b = b[size:]
+
+	n := val32(b[0:4])
+	b = b[4:]
+	if uint64(n)*12 != uint64(len(b)) {
+		return nil, fmt.Errorf("source map lists %d line(s), but has %d bytes", n, len(b))
+	}
+
+	lines := make([]Line, 0, n)
+	for i := range n {
+		l := Line{
+			Offset: val32(b[0:4]),
+			Line:   val32(b[4:8]),
+			Column: val32(b[8:12]),
+		}
+		b = b[12:]
+
+		if l.Offset >= textSize {
+			return nil, fmt.Errorf("source map line %d offset (=0x%08X) is outside of text segment", i, l.Offset)
+		}
+		if i != 0 && l.Offset <= lines[i-1].Offset {
+			return nil, fmt.Errorf("source map line %d offset (=0x%08X) is not ascending", i, l.Offset)
+		}
+		lines = append(lines, l)
+	}
+	return &SourceMap{Path: path, Lines: lines}, nil
+}
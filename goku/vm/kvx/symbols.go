@@ -0,0 +1,105 @@
+package kvx
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Symbol table encoding:
+//
+//	[XX XX XX XX] 4 // Number of function symbols
+//	[XX XX XX XX] 4 // Number of label symbols
+//
+// Followed by function symbols and then label symbols. Each symbol
+// is encoded as:
+//
+//	[XX XX XX XX] 4 // Text offset
+//	[XX XX XX XX] 4 // Name length in bytes
+//	[XX ... XX]   N // Name
+func encodeSymbols(t *SymbolTable) []byte {
+	var b []byte
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(t.Funs)))
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(t.Labels)))
+	for _, s := range t.Funs {
+		b = appendSymbol(b, s)
+	}
+	for _, s := range t.Labels {
+		b = appendSymbol(b, s)
+	}
+	return b
+}
+
+func appendSymbol(b []byte, s Symbol) []byte {
+	b = binary.LittleEndian.AppendUint32(b, s.Offset)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(s.Name)))
+	return append(b, s.Name...)
+}
+
+// decodeSymbols decodes symbol table and checks that all symbol offsets
+// are inside text segment of a given size.
+func decodeSymbols(b []byte, textSize uint32) (*SymbolTable, error) {
+	if len(b) < 8 {
+		return nil, fmt.Errorf("truncated symbol table header")
+	}
+	nf := val32(b[0:4])
+	nl := val32(b[4:8])
+	b = b[8:]
+
+	// each symbol takes at least 8 bytes, this check guards
+	// allocations below from bogus counts
+	if (uint64(nf)+uint64(nl))*8 > uint64(len(b)) {
+		return nil, fmt.Errorf("symbol table lists %d function(s) and %d label(s), but has only %d bytes", nf, nl, len(b))
+	}
+
+	if nf != 0 && textSize == 0 {
+		return nil, fmt.Errorf("symbol table lists functions, but text segment is empty")
+	}
+
+	var t SymbolTable
+	var err error
+	t.Funs, b, err = decodeSymbolList(b, nf, textSize-1, "function")
+	if err != nil {
+		return nil, err
+	}
+
+	// label may be placed right after the last instruction
+	t.Labels, b, err = decodeSymbolList(b, nl, textSize, "label")
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 0 {
+		return nil, fmt.Errorf("symbol table has %d trailing byte(s)", len(b))
+	}
+	return &t, nil
+}
+
+// decodes n symbols with offsets not greater than limit
+func decodeSymbolList(b []byte, n uint32, limit uint32, kind string) ([]Symbol, []byte, error) {
+	if n == 0 {
+		return nil, b, nil
+	}
+
+	list := make([]Symbol, 0, n)
+	for i := range n {
+		if len(b) < 8 {
+			return nil, nil, fmt.Errorf("truncated %s symbol %d", kind, i)
+		}
+		offset := val32(b[0:4])
+		size := val32(b[4:8])
+		b = b[8:]
+		if uint64(size) > uint64(len(b)) {
+			return nil, nil, fmt.Errorf("truncated %s symbol %d name", kind, i)
+		}
+		if size == 0 {
+			return nil, nil, fmt.Errorf("%s symbol %d has empty name", kind, i)
+		}
+		name := string(b[:size])
+		b = b[size:]
+
+		if offset > limit {
+			return nil, nil, fmt.Errorf("%s symbol \"%s\" offset (=0x%08X) is outside of text segment", kind, name, offset)
+		}
+		list = append(list, Symbol{Name: name, Offset: offset})
+	}
+	return list, b, nil
+}
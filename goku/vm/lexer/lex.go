@@ -39,6 +39,10 @@ func (lx *Lexer) lex() tokens.Token {
 		return lx.label()
 	}
 
+	if lx.Peek() == '"' {
+		return lx.str()
+	}
+
 	return lx.other()
 }
 
@@ -120,6 +124,48 @@ func (lx *Lexer) keyword() (tok tokens.Token) {
 	return tok
 }
 
+// Scan string literal.
+func (lx *Lexer) str() (tok tokens.Token) {
+	tok.Pin = lx.Pin()
+
+	lx.Advance() // skip quote
+
+	lx.Start()
+	for !lx.Eof() && lx.Peek() != '"' && lx.Peek() != '\n' {
+		if lx.Peek() == '\\' && (lx.Next() == '"' || lx.Next() == '\\') {
+			// do not stop if we encounter escape sequence,
+			// escaped backslash must not escape closing quote
+			lx.Advance() // skip "\"
+			lx.Advance() // skip escaped character
+		} else {
+			lx.Advance()
+		}
+	}
+
+	data, ok := lx.Take()
+	if !ok {
+		tok.SetIllegalError(baselex.LengthOverflow)
+		return tok
+	}
+	if lx.Eof() || lx.Peek() != '"' {
+		tok.SetIllegalError(baselex.MalformedString)
+		tok.Data = data
+		return tok
+	}
+
+	lx.Advance() // skip quote
+
+	data, ok = char.Unescape(data)
+	if !ok {
+		tok.SetIllegalError(baselex.BadEscapeInString)
+		return tok
+	}
+
+	tok.Kind = tokens.String
+	tok.Data = data
+	return tok
+}
+
 func (lx *Lexer) label() (tok tokens.Token) {
 	tok.Pin = lx.Pin()
 
@@ -0,0 +1,135 @@
+package vm
+
+import (
+	"encoding/binary"
+
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// memory access operands decoded from instruction
+type memop struct {
+	// pointer to accessed memory
+	ptr uint64
+
+	// value register
+	reg opc.Register
+
+	// number of accessed bytes
+	n uint32
+}
+
+// decode operands of Load or Store instruction
+func (m *Machine) decodeMem(lt uint8) (memop, uint64, *RuntimeError) {
+	s, variant := opc.DecodeMemLayout(lt)
+	if s > opc.Mem64 {
+		return memop{}, 0, &RuntimeError{
+			Code: ErrorBadVariant,
+			Aux:  uint64(lt),
+		}
+	}
+
+	var data []byte // instruction data
+	var size uint64
+	var ptr uint64
+	var err *RuntimeError
+	switch variant {
+	case opc.MemReg:
+		size = 2
+		data, err = m.idata(size)
+		if err != nil {
+			return memop{}, 0, err
+		}
+		ptr, err = m.get(opc.Register(data[1]))
+	case opc.MemRegVal32:
+		size = 6
+		data, err = m.idata(size)
+		if err != nil {
+			return memop{}, 0, err
+		}
+		ptr, err = m.get(opc.Register(data[1]))
+		ptr += uint64(val32(data[2:]))
+	case opc.MemVal64:
+		size = 9
+		data, err = m.idata(size)
+		if err != nil {
+			return memop{}, 0, err
+		}
+		ptr = val64(data[1:])
+	default:
+		return memop{}, 0, &RuntimeError{
+			Code: ErrorBadVariant,
+			Aux:  uint64(variant),
+		}
+	}
+	if err != nil {
+		return memop{}, 0, err
+	}
+
+	return memop{
+		ptr: ptr,
+		reg: opc.Register(data[0]),
+		n:   s.Bytes(),
+	}, size, nil
+}
+
+func (m *Machine) execLoad(lt uint8) (uint64, *RuntimeError) {
+	op, size, err := m.decodeMem(lt)
+	if err != nil {
+		return 0, err
+	}
+	b, err := m.memslice(op.ptr, op.n)
+	if err != nil {
+		return 0, err
+	}
+
+	var val uint64
+	switch op.n {
+	case 1:
+		val = uint64(b[0])
+	case 2:
+		val = uint64(val16(b))
+	case 4:
+		val = uint64(val32(b))
+	case 8:
+		val = val64(b)
+	}
+	err = m.set(op.reg, val)
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (m *Machine) execStore(lt uint8) (uint64, *RuntimeError) {
+	op, size, err := m.decodeMem(lt)
+	if err != nil {
+		return 0, err
+	}
+	segment, _ := getPointerSegmentAndOffset(op.ptr)
+	if segment == SegText || segment == SegData {
+		return 0, &RuntimeError{
+			Code: ErrorReadOnlySegment,
+			Aux:  op.ptr,
+		}
+	}
+	b, err := m.memslice(op.ptr, op.n)
+	if err != nil {
+		return 0, err
+	}
+	val, err := m.get(op.reg)
+	if err != nil {
+		return 0, err
+	}
+
+	switch op.n {
+	case 1:
+		b[0] = uint8(val)
+	case 2:
+		binary.LittleEndian.PutUint16(b, uint16(val))
+	case 4:
+		binary.LittleEndian.PutUint32(b, uint32(val))
+	case 8:
+		binary.LittleEndian.PutUint64(b, val)
+	}
+	return size, nil
+}
@@ -34,10 +34,19 @@ List of instructions with generic layouts:
 	Sub
 	Mul
 	Div
+	Rem
 
-	Or
 	And
+	Or
 	Xor
-	Shift
+	Shl
+	Shr
+
+	SDiv
+	SRem
+	Sar
+
+Arithmetic and bitwise instructions do not change comparison flags,
+only Test does.
 */
 package opc
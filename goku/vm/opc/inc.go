@@ -1,6 +1,6 @@
 package opc
 
-// Inc instruction layouts.
+// Inc instruction layouts. Dec instruction uses the same layouts.
 //
 // Layout of Inc is split into two parts.
 // Low 4 bits specify variant and Data encoding.
@@ -21,7 +21,7 @@ const (
 	//	RR - 1 byte - source
 	IncReg Layout = 0x1
 
-	// Increase destination register by value immediate value
+	// Increase destination register by immediate value
 	// from Data.
 	//
 	// Data:
@@ -29,7 +29,7 @@ const (
 	//	II II II II - 4 bytes - source
 	IncVal32 Layout = 0x2
 
-	// Increase destination register by value immediate value
+	// Increase destination register by immediate value
 	// from Data.
 	//
 	// Data:
@@ -41,3 +41,9 @@ const (
 func EncodeIncTinyLayout(v uint8) Layout {
 	return IncTiny | Layout(v<<4)
 }
+
+func DecodeIncLayout(x uint8) (Layout, uint8) {
+	layout := Layout(x & 0xF)
+	value := x >> 4
+	return layout, value
+}
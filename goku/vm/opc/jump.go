@@ -1,6 +1,11 @@
 package opc
 
-// JumpFlag specifies jump condition.
+// JumpFlag specifies jump condition. Condition is checked against
+// result of the last Test instruction, which compares destination
+// operand x with source operand y.
+//
+// Flags L, LE, G and GE treat compared values as signed integers.
+// Flags B, BE, A and AE treat them as unsigned.
 type JumpFlag uint8
 
 const (
@@ -10,6 +15,10 @@ const (
 	FlagLE JumpFlag = 0x4
 	FlagG  JumpFlag = 0x5
 	FlagGE JumpFlag = 0x6
+	FlagB  JumpFlag = 0x7
+	FlagBE JumpFlag = 0x8
+	FlagA  JumpFlag = 0x9
+	FlagAE JumpFlag = 0xA
 )
 
 // Jump instruction layouts.
@@ -51,6 +60,10 @@ var flagText = [...]string{
 	FlagLE: "le (<= x)",
 	FlagG:  "g (> x)",
 	FlagGE: "ge (>= x)",
+	FlagB:  "b (< x, unsigned)",
+	FlagBE: "be (<= x, unsigned)",
+	FlagA:  "a (> x, unsigned)",
+	FlagAE: "ae (>= x, unsigned)",
 }
 
 func (f JumpFlag) String() string {
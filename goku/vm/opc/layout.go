@@ -5,7 +5,8 @@ package opc
 // jump condition (if any) and where destination is stored.
 type Layout uint8
 
-// Generic layouts.
+// Generic layouts. Immediate values are zero-extended to 8 bytes.
+// Shift amount is taken modulo 64.
 const (
 	// Data:
 	//	RR - 1 byte - destination
@@ -1,7 +1,70 @@
 package opc
 
+// Load and Store instruction layouts.
+//
+// Layout of Load and Store is split into two parts.
+// Low 4 bits specify variant and Data encoding.
+// High 4 bits specify size of accessed memory (see MemSize).
+//
+// Pointer operand must carry memory segment in its highest byte.
+// Load zero-extends loaded value to 8 bytes. Store writes low
+// bytes of register value.
 const (
-	LoadRegReg   Layout = 0x0
-	LoadRegVal32 Layout = 0x1
-	LoadRegVal64 Layout = 0x2
+	// Access memory at address stored in register.
+	//
+	// Data:
+	//	RR - 1 byte - value register (destination for Load, source for Store)
+	//	RR - 1 byte - pointer
+	MemReg Layout = 0x0
+
+	// Access memory at address stored in register plus immediate offset.
+	//
+	// Data:
+	//	RR - 1 byte - value register (destination for Load, source for Store)
+	//	RR - 1 byte - pointer
+	//	II II II II - 4 bytes - offset
+	MemRegVal32 Layout = 0x1
+
+	// Access memory at address stored in instruction immediate value.
+	//
+	// Data:
+	//	RR - 1 byte - value register (destination for Load, source for Store)
+	//	II II II II II II II II - 8 bytes - pointer
+	MemVal64 Layout = 0x2
+)
+
+// MemSize specifies size of memory accessed by Load or Store instruction.
+type MemSize uint8
+
+const (
+	Mem8  MemSize = 0x0
+	Mem16 MemSize = 0x1
+	Mem32 MemSize = 0x2
+	Mem64 MemSize = 0x3
 )
+
+// Bytes returns number of accessed bytes.
+func (s MemSize) Bytes() uint32 {
+	return 1 << s
+}
+
+var memSizeText = [...]string{
+	Mem8:  "u8",
+	Mem16: "u16",
+	Mem32: "u32",
+	Mem64: "u64",
+}
+
+func (s MemSize) String() string {
+	return memSizeText[s]
+}
+
+func EncodeMemLayout(size MemSize, lt Layout) Layout {
+	return lt | Layout(size<<4)
+}
+
+func DecodeMemLayout(x uint8) (MemSize, Layout) {
+	size := MemSize(x >> 4)
+	layout := Layout(x & 0xF)
+	return size, layout
+}
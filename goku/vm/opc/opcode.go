@@ -1,5 +1,7 @@
 package opc
 
+import "strconv"
+
 // Opcode denotes which type of operation instruction performs
 // inside VM.
 type Opcode uint8
@@ -48,6 +50,39 @@ const (
 
 	// Subtract two values.
 	Sub
+
+	// Multiply two values.
+	Mul
+
+	// Unsigned division of two values.
+	Div
+
+	// Remainder of unsigned division of two values.
+	Rem
+
+	// Bitwise AND of two values.
+	And
+
+	// Bitwise OR of two values.
+	Or
+
+	// Bitwise XOR of two values.
+	Xor
+
+	// Logical shift left.
+	Shl
+
+	// Logical shift right.
+	Shr
+
+	// Signed division of two values.
+	SDiv
+
+	// Remainder of signed division of two values.
+	SRem
+
+	// Arithmetic shift right.
+	Sar
 )
 
 var opcodeText = [...]string{
@@ -62,10 +97,25 @@ var opcodeText = [...]string{
 	Store: "store",
 	Test:  "test",
 	Inc:   "inc",
+	Dec:   "dec",
 	Add:   "add",
 	Sub:   "sub",
+	Mul:   "mul",
+	Div:   "div",
+	Rem:   "rem",
+	And:   "and",
+	Or:    "or",
+	Xor:   "xor",
+	Shl:   "shl",
+	Shr:   "shr",
+	SDiv:  "sdiv",
+	SRem:  "srem",
+	Sar:   "sar",
 }
 
 func (c Opcode) String() string {
+	if int(c) >= len(opcodeText) {
+		return "op(0x" + strconv.FormatUint(uint64(c), 16) + ")"
+	}
 	return opcodeText[c]
 }
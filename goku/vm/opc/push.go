@@ -0,0 +1,29 @@
+package opc
+
+// Push instruction layouts.
+//
+// Push always places 8 bytes on top of the stack.
+const (
+	// Push value stored in register.
+	//
+	// Data:
+	//	RR - 1 byte - source
+	PushReg Layout = 0x0
+
+	// Push immediate value from Data. Value is zero-extended to 8 bytes.
+	//
+	// Data:
+	//	II II II II - 4 bytes - source
+	PushVal32 Layout = 0x1
+)
+
+// Pop instruction layouts.
+//
+// Pop always removes 8 bytes from top of the stack.
+const (
+	// Pop value into register.
+	//
+	// Data:
+	//	RR - 1 byte - destination
+	PopReg Layout = 0x0
+)
@@ -1,5 +1,14 @@
 package vm
 
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mebyus/ku/goku/vm/kvx"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
 type ErrorCode uint32
 
 const (
@@ -32,13 +41,170 @@ const (
 	ErrorBadInstructionDataLength
 
 	ErrorNonTextJump
+
+	// Integer division or remainder by zero.
+	ErrorDivByZero
+
+	// Push instruction exceeded stack memory size.
+	ErrorStackOverflow
+
+	// Pop instruction tried to remove value below current frame.
+	ErrorStackUnderflow
+
+	// Memory access outside of segment bounds.
+	ErrorBadAddress
+
+	// Store into read-only memory segment.
+	ErrorReadOnlySegment
+
+	// Syscall register contains unknown syscall number.
+	ErrorBadSyscall
+
+	// Heap free syscall received pointer which does not point
+	// to live heap block.
+	ErrorBadFree
+
+	// Memory access inside heap block which was already freed.
+	ErrorFreedAddress
 )
 
+var errorText = [...]string{
+	0: "<nil>",
+
+	ErrorTextEnd:                  "text end",
+	ErrorTrap:                     "trap",
+	ErrorBadOpcode:                "bad opcode",
+	ErrorBadSegment:               "bad segment",
+	ErrorBadSpecialRegister:       "bad special register",
+	ErrorBadRegister:              "bad register",
+	ErrorBadJumpAddress:           "bad jump address",
+	ErrorBadCallAddress:           "bad call address",
+	ErrorBadVariant:               "bad variant",
+	ErrorBadJumpFlag:              "bad jump flag",
+	ErrorEmptyFrameStack:          "empty frame stack",
+	ErrorReadOnlyRegister:         "read-only register",
+	ErrorBadInstructionDataLength: "bad instruction data length",
+	ErrorNonTextJump:              "non-text jump",
+	ErrorDivByZero:                "division by zero",
+	ErrorStackOverflow:            "stack overflow",
+	ErrorStackUnderflow:           "stack underflow",
+	ErrorBadAddress:               "bad address",
+	ErrorReadOnlySegment:          "read-only segment",
+	ErrorBadSyscall:               "bad syscall",
+	ErrorBadFree:                  "bad free",
+	ErrorFreedAddress:             "freed address",
+}
+
+func (c ErrorCode) String() string {
+	if int(c) >= len(errorText) {
+		return fmt.Sprintf("error(%d)", uint32(c))
+	}
+	return errorText[c]
+}
+
 type RuntimeError struct {
-	Aux  uint64
+	// Call frames at the moment of error, innermost frame is the last.
+	Backtrace []Frame
+
+	// Additional error information, meaning depends on error code.
+	// For memory access errors contains faulting pointer.
+	Aux uint64
+
+	// Instruction pointer at the moment of error.
+	IP uint64
+
 	Code ErrorCode
+
+	// Opcode of faulting instruction. Equals zero if ip is outside of text.
+	Opcode opc.Opcode
+
+	// Layout of faulting instruction. Equals zero if ip is outside of text.
+	Layout uint8
+}
+
+// Pointer returns faulting pointer for memory access errors.
+// Returns false for errors not related to memory access.
+func (r *RuntimeError) Pointer() (uint64, bool) {
+	switch r.Code {
+	case ErrorBadSegment, ErrorBadAddress, ErrorReadOnlySegment, ErrorBadFree, ErrorFreedAddress:
+		return r.Aux, true
+	default:
+		return 0, false
+	}
 }
 
 func (r *RuntimeError) Error() string {
-	return ""
+	var b strings.Builder
+	b.WriteString(r.Code.String())
+	ptr, ok := r.Pointer()
+	if ok {
+		fmt.Fprintf(&b, " (ptr=0x%016X)", ptr)
+	} else if r.Aux != 0 {
+		fmt.Fprintf(&b, " (aux=0x%X)", r.Aux)
+	}
+	fmt.Fprintf(&b, " at 0x%08X [%s, layout=0x%02X]", r.IP, r.Opcode, r.Layout)
+	return b.String()
+}
+
+// Render writes error with source position and backtrace. Program is
+// used to map text offsets to source positions and symbol names, positions
+// and names are omitted when program has no source map or symbol table.
+func (r *RuntimeError) Render(w io.Writer, prog *kvx.Program) error {
+	pos := sourcePos(prog, r.IP)
+	if pos != "" {
+		pos += " "
+	}
+	_, err := fmt.Fprintf(w, "%sruntime error: %s\n", pos, r.Error())
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "    at %s\n", location(prog, r.IP, r.IP))
+	if err != nil {
+		return err
+	}
+	for i := len(r.Backtrace) - 1; i >= 0; i -= 1 {
+		// return address points to instruction after the call,
+		// call instruction itself is located before it
+		ret := uint64(r.Backtrace[i].Ret)
+		site := ret
+		if site != 0 {
+			site -= 1
+		}
+		_, err = fmt.Fprintf(w, "    at %s\n", location(prog, ret, site))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// location formats text offset with symbol name and source position
+// of instruction at site offset when they are available.
+func location(prog *kvx.Program, offset uint64, site uint64) string {
+	s := fmt.Sprintf("0x%08X", offset)
+	if prog.Symbols != nil {
+		f, ok := prog.Symbols.FindFun(uint32(offset))
+		if ok {
+			s += fmt.Sprintf(" %s+0x%X", f.Name, offset-uint64(f.Offset))
+		}
+	}
+	pos := sourcePos(prog, site)
+	if pos != "" {
+		s += " (" + pos + ")"
+	}
+	return s
+}
+
+// sourcePos formats source position of instruction which contains
+// text offset. Returns empty string if program has no source map.
+func sourcePos(prog *kvx.Program, offset uint64) string {
+	if prog.Source == nil || offset >= uint64(len(prog.Text)) {
+		return ""
+	}
+	l, ok := prog.Source.Find(uint32(offset))
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", prog.Source.Path, l.Line+1, l.Column+1)
 }
@@ -0,0 +1,200 @@
+package ssa
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Block represents basic block. Block consists of parameters, list of
+// instructions and exactly one terminator which transfers control out
+// of the block.
+type Block struct {
+	// Values received from incoming edges.
+	Params []*Value
+
+	// Instructions in order of execution.
+	Insts []*Value
+
+	// Predecessor blocks. Filled when edge to this block is created.
+	Preds []*Block
+
+	// Equals nil until block is terminated.
+	Term Terminator
+
+	Fun *Fun
+
+	// Unique inside function.
+	ID int
+}
+
+func (b *Block) String() string {
+	return "b" + strconv.Itoa(b.ID)
+}
+
+// Edge represents control flow transfer to block with arguments
+// for block parameters.
+type Edge struct {
+	Args []*Value
+
+	Block *Block
+}
+
+func (e Edge) String() string {
+	if len(e.Args) == 0 {
+		return e.Block.String()
+	}
+	return fmt.Sprintf("%s(%s)", e.Block, joinValues(e.Args))
+}
+
+// Terminator represents last instruction of the block.
+//
+// Terminator is one of:
+//   - *Jump
+//   - *Branch
+//   - *Return
+//   - *Trap
+type Terminator interface {
+	// Returns outgoing edges.
+	Edges() []Edge
+
+	String() string
+}
+
+// Jump represents unconditional control transfer.
+type Jump struct {
+	Target Edge
+}
+
+// Explicit interface implementation check.
+var _ Terminator = &Jump{}
+
+func (j *Jump) Edges() []Edge {
+	return []Edge{j.Target}
+}
+
+func (j *Jump) String() string {
+	return "jump " + j.Target.String()
+}
+
+// Branch represents conditional control transfer. Control goes to
+// Then edge if condition value is not zero and to Else edge otherwise.
+type Branch struct {
+	Cond *Value
+
+	Then Edge
+	Else Edge
+}
+
+// Explicit interface implementation check.
+var _ Terminator = &Branch{}
+
+func (b *Branch) Edges() []Edge {
+	return []Edge{b.Then, b.Else}
+}
+
+func (b *Branch) String() string {
+	return fmt.Sprintf("branch %s, %s, %s", b.Cond, b.Then, b.Else)
+}
+
+// Return represents return from function.
+type Return struct {
+	// Equals nil if function does not return value.
+	Value *Value
+}
+
+// Explicit interface implementation check.
+var _ Terminator = &Return{}
+
+func (r *Return) Edges() []Edge {
+	return nil
+}
+
+func (r *Return) String() string {
+	if r.Value == nil {
+		return "ret"
+	}
+	return "ret " + r.Value.String()
+}
+
+// Trap represents abnormal program termination.
+type Trap struct{}
+
+// Explicit interface implementation check.
+var _ Terminator = &Trap{}
+
+func (t *Trap) Edges() []Edge {
+	return nil
+}
+
+func (t *Trap) String() string {
+	return "trap"
+}
+
+func (b *Block) value(op Op, args ...*Value) *Value {
+	if b.Term != nil {
+		panic(fmt.Sprintf("block %s is already terminated", b))
+	}
+	v := b.Fun.newValue(op, b, args)
+	b.Insts = append(b.Insts, v)
+	return v
+}
+
+// Param adds block parameter.
+func (b *Block) Param() *Value {
+	v := b.Fun.newValue(OpBlockParam, b, nil)
+	v.Aux = uint64(len(b.Params))
+	b.Params = append(b.Params, v)
+	return v
+}
+
+// Const adds integer constant.
+func (b *Block) Const(c uint64) *Value {
+	v := b.value(OpConst)
+	v.Aux = c
+	return v
+}
+
+// Bin adds binary operation or comparison.
+func (b *Block) Bin(op Op, x, y *Value) *Value {
+	if !op.Binary() && !op.Compare() {
+		panic(fmt.Sprintf("%s is not a binary operation", op))
+	}
+	return b.value(op, x, y)
+}
+
+// Call adds call to function.
+func (b *Block) Call(fun *Fun, args ...*Value) *Value {
+	v := b.value(OpCall, args...)
+	v.Fun = fun
+	return v
+}
+
+func (b *Block) terminate(t Terminator) {
+	if b.Term != nil {
+		panic(fmt.Sprintf("block %s is already terminated", b))
+	}
+	b.Term = t
+	for _, e := range t.Edges() {
+		e.Block.Preds = append(e.Block.Preds, b)
+	}
+}
+
+// Jump terminates block with unconditional jump.
+func (b *Block) Jump(target *Block, args ...*Value) {
+	b.terminate(&Jump{Target: Edge{Block: target, Args: args}})
+}
+
+// Branch terminates block with conditional branch.
+func (b *Block) Branch(cond *Value, then Edge, els Edge) {
+	b.terminate(&Branch{Cond: cond, Then: then, Else: els})
+}
+
+// Return terminates block with return. Value may be nil.
+func (b *Block) Return(v *Value) {
+	b.terminate(&Return{Value: v})
+}
+
+// Trap terminates block with trap.
+func (b *Block) Trap() {
+	b.terminate(&Trap{})
+}
@@ -0,0 +1,172 @@
+package ssa
+
+import (
+	"fmt"
+	"io"
+)
+
+// Fun represents function in SSA form.
+type Fun struct {
+	Name string
+
+	// Function parameters in order of declaration.
+	Params []*Value
+
+	// First block is function entry. Blocks are laid out in
+	// this order when function is lowered.
+	Blocks []*Block
+
+	// Counters for assigning IDs.
+	values int
+	blocks int
+}
+
+// NewFun creates function with a given number of parameters.
+func NewFun(name string, params int) *Fun {
+	f := &Fun{Name: name}
+	for i := range params {
+		v := f.newValue(OpParam, nil, nil)
+		v.Aux = uint64(i)
+		f.Params = append(f.Params, v)
+	}
+	return f
+}
+
+func (f *Fun) newValue(op Op, b *Block, args []*Value) *Value {
+	v := &Value{
+		Op:    op,
+		Args:  args,
+		Block: b,
+		ID:    f.values,
+	}
+	f.values += 1
+	return v
+}
+
+// NewBlock creates new block and appends it to function blocks.
+func (f *Fun) NewBlock() *Block {
+	b := &Block{Fun: f, ID: f.blocks}
+	f.blocks += 1
+	f.Blocks = append(f.Blocks, b)
+	return b
+}
+
+// Entry returns function entry block.
+func (f *Fun) Entry() *Block {
+	return f.Blocks[0]
+}
+
+// Verify checks structural correctness of function: all blocks are
+// terminated, edges pass correct number of arguments, values are used
+// only inside the function which defines them.
+func (f *Fun) Verify() error {
+	if len(f.Blocks) == 0 {
+		return fmt.Errorf("function %s has no blocks", f.Name)
+	}
+	if len(f.Entry().Params) != 0 {
+		return fmt.Errorf("function %s entry block has parameters", f.Name)
+	}
+
+	check := func(b *Block, v *Value) error {
+		if v == nil {
+			return fmt.Errorf("%s: block %s uses nil value", f.Name, b)
+		}
+		if v.Op == OpParam {
+			if int(v.Aux) >= len(f.Params) || f.Params[v.Aux] != v {
+				return fmt.Errorf("%s: block %s uses parameter %s of another function", f.Name, b, v)
+			}
+			return nil
+		}
+		if v.Block == nil || v.Block.Fun != f {
+			return fmt.Errorf("%s: block %s uses value %s of another function", f.Name, b, v)
+		}
+		return nil
+	}
+
+	for _, b := range f.Blocks {
+		if b.Fun != f {
+			return fmt.Errorf("%s: block %s belongs to another function", f.Name, b)
+		}
+		if b.Term == nil {
+			return fmt.Errorf("%s: block %s is not terminated", f.Name, b)
+		}
+		for _, v := range b.Insts {
+			for _, a := range v.Args {
+				err := check(b, a)
+				if err != nil {
+					return err
+				}
+			}
+			if v.Op == OpCall && len(v.Args) != len(v.Fun.Params) {
+				return fmt.Errorf("%s: call %s passes %d argument(s), want %d",
+					f.Name, v.Fun.Name, len(v.Args), len(v.Fun.Params))
+			}
+		}
+		for _, e := range b.Term.Edges() {
+			if e.Block.Fun != f {
+				return fmt.Errorf("%s: block %s jumps into another function", f.Name, b)
+			}
+			if len(e.Args) != len(e.Block.Params) {
+				return fmt.Errorf("%s: edge %s -> %s passes %d argument(s), want %d",
+					f.Name, b, e.Block, len(e.Args), len(e.Block.Params))
+			}
+			for _, a := range e.Args {
+				err := check(b, a)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		switch t := b.Term.(type) {
+		case *Branch:
+			err := check(b, t.Cond)
+			if err != nil {
+				return err
+			}
+		case *Return:
+			if t.Value != nil {
+				err := check(b, t.Value)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Render writes function in text form.
+func (f *Fun) Render(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "fun %s(%s) {\n", f.Name, joinValues(f.Params))
+	if err != nil {
+		return err
+	}
+	for _, b := range f.Blocks {
+		_, err = fmt.Fprintf(w, "%s(%s):\n", b, joinValues(b.Params))
+		if err != nil {
+			return err
+		}
+		for _, v := range b.Insts {
+			_, err = fmt.Fprintf(w, "\t%s\n", v.LongString())
+			if err != nil {
+				return err
+			}
+		}
+		if b.Term != nil {
+			_, err = fmt.Fprintf(w, "\t%s\n", b.Term)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
+
+// Program is a set of functions with designated entrypoint.
+type Program struct {
+	Funs []*Fun
+
+	// Entry function. Its return value becomes program exit status.
+	Entry *Fun
+}
@@ -0,0 +1,337 @@
+package ssa
+
+import (
+	"fmt"
+
+	"github.com/mebyus/ku/goku/vm/ir"
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// StartName is name of synthesized program entrypoint. It calls entry
+// function and exits with its return value as status.
+const StartName = "_start"
+
+// Calling convention: arguments are passed in registers #:r0, #:r1, ...
+// and result is returned in #:r0. All registers are caller-saved,
+// thus caller pushes registers of values live across call.
+
+// Lower translates program into VM assembly representation.
+func Lower(prog *Program) (*ir.Program, error) {
+	if prog.Entry == nil {
+		return nil, fmt.Errorf("program has no entry function")
+	}
+
+	l := lowerer{
+		funs: make(map[*Fun]ir.FunName, len(prog.Funs)),
+	}
+
+	// synthesized entrypoint goes first
+	l.prog.FunNames = append(l.prog.FunNames, StartName)
+	for i, f := range prog.Funs {
+		_, ok := l.funs[f]
+		if ok {
+			return nil, fmt.Errorf("function %s is listed twice", f.Name)
+		}
+		if f.Name == StartName {
+			return nil, fmt.Errorf("function name %s is reserved", f.Name)
+		}
+		l.funs[f] = ir.FunName(i + 1)
+		l.prog.FunNames = append(l.prog.FunNames, f.Name)
+	}
+	entry, ok := l.funs[prog.Entry]
+	if !ok {
+		return nil, fmt.Errorf("entry function %s is not listed in program", prog.Entry.Name)
+	}
+
+	l.prog.Functions = append(l.prog.Functions, ir.Fun{
+		Name: 0,
+		Atoms: []ir.Atom{
+			ir.CallFun{Fun: entry},
+			ir.SetReg{Dest: opc.RegSC, Source: 0},
+			ir.Halt{},
+		},
+	})
+	for _, f := range prog.Funs {
+		err := f.Verify()
+		if err != nil {
+			return nil, err
+		}
+		err = l.lowerFun(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &l.prog, nil
+}
+
+type lowerer struct {
+	prog ir.Program
+
+	funs map[*Fun]ir.FunName
+
+	// state of currently lowered function
+
+	alloc *Allocation
+
+	// block labels
+	labels map[*Block]ir.Label
+
+	atoms []ir.Atom
+}
+
+func (l *lowerer) label(name string) ir.Label {
+	label := ir.Label(l.prog.LabelsCount)
+	l.prog.LabelsCount += 1
+	l.prog.LabelNames = append(l.prog.LabelNames, name)
+	return label
+}
+
+func (l *lowerer) emit(atoms ...ir.Atom) {
+	l.atoms = append(l.atoms, atoms...)
+}
+
+func (l *lowerer) lowerFun(f *Fun) error {
+	for _, b := range f.Blocks {
+		for _, v := range b.Insts {
+			if v.Op != OpCall {
+				continue
+			}
+			_, ok := l.funs[v.Fun]
+			if !ok {
+				return fmt.Errorf("%s: called function %s is not listed in program", f.Name, v.Fun.Name)
+			}
+		}
+	}
+
+	alloc, err := Allocate(f)
+	if err != nil {
+		return err
+	}
+	l.alloc = alloc
+	l.atoms = nil
+	l.labels = make(map[*Block]ir.Label, len(f.Blocks))
+	for _, b := range f.Blocks {
+		l.labels[b] = l.label(b.String())
+	}
+
+	// move parameters from convention registers
+	var srcs, dsts []opc.Register
+	for i, p := range f.Params {
+		srcs = append(srcs, opc.Register(i))
+		dsts = append(dsts, alloc.Reg(p))
+	}
+	l.move(srcs, dsts)
+
+	for i, b := range f.Blocks {
+		var next *Block
+		if i+1 < len(f.Blocks) {
+			next = f.Blocks[i+1]
+		}
+		l.lowerBlock(b, next)
+	}
+
+	l.prog.Functions = append(l.prog.Functions, ir.Fun{
+		Name:  l.funs[f],
+		Atoms: l.atoms,
+	})
+	return nil
+}
+
+// move performs parallel move of register values
+func (l *lowerer) move(srcs, dsts []opc.Register) {
+	var s, d []opc.Register
+	for i := range srcs {
+		if srcs[i] != dsts[i] {
+			s = append(s, srcs[i])
+			d = append(d, dsts[i])
+		}
+	}
+	switch len(s) {
+	case 0:
+		return
+	case 1:
+		l.emit(ir.SetReg{Dest: d[0], Source: s[0]})
+		return
+	}
+
+	// use stack to avoid overwriting sources before they are read
+	for _, r := range s {
+		l.emit(ir.PushReg{Reg: r})
+	}
+	for i := len(d) - 1; i >= 0; i -= 1 {
+		l.emit(ir.PopReg{Reg: d[i]})
+	}
+}
+
+// edge moves block arguments into successor parameter registers
+func (l *lowerer) edge(e Edge) {
+	var srcs, dsts []opc.Register
+	for i, arg := range e.Args {
+		srcs = append(srcs, l.alloc.Reg(arg))
+		dsts = append(dsts, l.alloc.Reg(e.Block.Params[i]))
+	}
+	l.move(srcs, dsts)
+}
+
+func (l *lowerer) lowerBlock(b *Block, next *Block) {
+	l.emit(ir.Place{Label: l.labels[b]})
+	for _, v := range b.Insts {
+		l.lowerValue(v)
+	}
+
+	switch t := b.Term.(type) {
+	case *Return:
+		if t.Value != nil {
+			l.move([]opc.Register{l.alloc.Reg(t.Value)}, []opc.Register{0})
+		}
+		l.emit(ir.Ret{})
+	case *Jump:
+		l.edge(t.Target)
+		if t.Target.Block != next {
+			l.emit(ir.JumpLabel{Label: l.labels[t.Target.Block]})
+		}
+	case *Branch:
+		l.lowerBranch(b, t, next)
+	case *Trap:
+		l.emit(ir.Trap{})
+	default:
+		panic(fmt.Sprintf("unexpected terminator (%T)", t))
+	}
+}
+
+func (l *lowerer) lowerBranch(b *Block, t *Branch, next *Block) {
+	flag := l.test(t.Cond)
+
+	// when then edge has moves, jump to intermediate place which
+	// performs them
+	then := l.labels[t.Then.Block]
+	stub := len(t.Then.Args) != 0
+	if stub {
+		then = l.label(b.String() + "_then")
+	}
+	l.emit(ir.JumpLabel{Label: then, Flag: flag})
+
+	l.edge(t.Else)
+	if stub || t.Else.Block != next {
+		l.emit(ir.JumpLabel{Label: l.labels[t.Else.Block]})
+	}
+	if !stub {
+		return
+	}
+	l.emit(ir.Place{Label: then})
+	l.edge(t.Then)
+	if t.Then.Block != next {
+		l.emit(ir.JumpLabel{Label: l.labels[t.Then.Block]})
+	}
+}
+
+var compareFlags = [...]opc.JumpFlag{
+	OpEq:  opc.FlagZ,
+	OpNe:  opc.FlagNZ,
+	OpLt:  opc.FlagL,
+	OpLe:  opc.FlagLE,
+	OpGt:  opc.FlagG,
+	OpGe:  opc.FlagGE,
+	OpULt: opc.FlagB,
+	OpULe: opc.FlagBE,
+	OpUGt: opc.FlagA,
+	OpUGe: opc.FlagAE,
+}
+
+// emit test of branch condition, returns jump flag which
+// is satisfied when condition holds
+func (l *lowerer) test(cond *Value) opc.JumpFlag {
+	if l.alloc.Fused(cond) {
+		l.emit(ir.TestReg{
+			Dest:   l.alloc.Reg(cond.Args[0]),
+			Source: l.alloc.Reg(cond.Args[1]),
+		})
+		return compareFlags[cond.Op]
+	}
+	l.emit(ir.TestVal{Dest: l.alloc.Reg(cond), Val: 0})
+	return opc.FlagNZ
+}
+
+var binOpcodes = [...]opc.Opcode{
+	OpAdd: opc.Add,
+	OpSub: opc.Sub,
+	OpMul: opc.Mul,
+	OpDiv: opc.Div,
+	OpRem: opc.Rem,
+	OpAnd: opc.And,
+	OpOr:  opc.Or,
+	OpXor: opc.Xor,
+	OpShl: opc.Shl,
+	OpShr: opc.Shr,
+
+	OpSDiv: opc.SDiv,
+	OpSRem: opc.SRem,
+	OpSar:  opc.Sar,
+}
+
+func (l *lowerer) lowerValue(v *Value) {
+	switch {
+	case v.Op == OpConst:
+		if v.Aux == 0 {
+			l.emit(ir.ClearReg{Reg: l.alloc.Reg(v)})
+			return
+		}
+		l.emit(ir.SetVal{Dest: l.alloc.Reg(v), Val: v.Aux})
+	case v.Op.Binary():
+		l.emit(ir.BinReg{
+			Op:   binOpcodes[v.Op],
+			Dest: l.alloc.Reg(v),
+			A:    l.alloc.Reg(v.Args[0]),
+			B:    l.alloc.Reg(v.Args[1]),
+		})
+	case v.Op.Compare():
+		if l.alloc.Fused(v) {
+			// lowered as part of branch
+			return
+		}
+		l.lowerCompare(v)
+	case v.Op == OpCall:
+		l.lowerCall(v)
+	default:
+		panic(fmt.Sprintf("unexpected value operation %s", v.Op))
+	}
+}
+
+// materialize comparison result as 0 or 1
+func (l *lowerer) lowerCompare(v *Value) {
+	dest := l.alloc.Reg(v)
+	done := l.label(fmt.Sprintf("%s_v%d", v.Block, v.ID))
+	l.emit(
+		ir.TestReg{
+			Dest:   l.alloc.Reg(v.Args[0]),
+			Source: l.alloc.Reg(v.Args[1]),
+		},
+		ir.SetVal{Dest: dest, Val: 1},
+		ir.JumpLabel{Label: done, Flag: compareFlags[v.Op]},
+		ir.ClearReg{Reg: dest},
+		ir.Place{Label: done},
+	)
+}
+
+func (l *lowerer) lowerCall(v *Value) {
+	saved := l.alloc.LiveAcross(v)
+	for _, r := range saved {
+		l.emit(ir.PushReg{Reg: r})
+	}
+
+	var srcs, dsts []opc.Register
+	for i, arg := range v.Args {
+		srcs = append(srcs, l.alloc.Reg(arg))
+		dsts = append(dsts, opc.Register(i))
+	}
+	l.move(srcs, dsts)
+	l.emit(ir.CallFun{Fun: l.funs[v.Fun]})
+
+	dest := l.alloc.Reg(v)
+	if dest != 0 {
+		l.emit(ir.SetReg{Dest: dest, Source: 0})
+	}
+	for i := len(saved) - 1; i >= 0; i -= 1 {
+		l.emit(ir.PopReg{Reg: saved[i]})
+	}
+}
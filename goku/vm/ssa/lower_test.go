@@ -0,0 +1,147 @@
+package ssa
+
+import (
+	"testing"
+
+	"github.com/mebyus/ku/goku/vm"
+	"github.com/mebyus/ku/goku/vm/asm"
+)
+
+// fib(n) = n <= 1 ? n : fib(n-1) + fib(n-2)
+func progFib(n uint64) *Program {
+	fib := NewFun("fib", 1)
+	entry := fib.NewBlock()
+	rec := fib.NewBlock()
+	exit := fib.NewBlock()
+
+	x := fib.Params[0]
+	one := entry.Const(1)
+	entry.Branch(entry.Bin(OpLe, x, one), Edge{Block: exit}, Edge{Block: rec})
+
+	one = rec.Const(1)
+	two := rec.Const(2)
+	a := rec.Call(fib, rec.Bin(OpSub, x, one))
+	b := rec.Call(fib, rec.Bin(OpSub, x, two))
+	rec.Return(rec.Bin(OpAdd, a, b))
+
+	exit.Return(x)
+
+	main := NewFun("main", 0)
+	m := main.NewBlock()
+	m.Return(m.Call(fib, m.Const(n)))
+
+	return &Program{Funs: []*Fun{main, fib}, Entry: main}
+}
+
+// sum of integers from 1 to n with loop over block parameters
+func progSum(n uint64) *Program {
+	f := NewFun("main", 0)
+	entry := f.NewBlock()
+	loop := f.NewBlock()
+	body := f.NewBlock()
+	exit := f.NewBlock()
+
+	entry.Jump(loop, entry.Const(1), entry.Const(0))
+
+	i := loop.Param()
+	s := loop.Param()
+	loop.Branch(loop.Bin(OpUGt, i, loop.Const(n)), Edge{Block: exit, Args: []*Value{s}}, Edge{Block: body})
+
+	one := body.Const(1)
+	body.Jump(loop, body.Bin(OpAdd, i, one), body.Bin(OpAdd, s, i))
+
+	r := exit.Param()
+	exit.Return(r)
+
+	return &Program{Funs: []*Fun{f}, Entry: f}
+}
+
+// swaps block arguments on each iteration, materializes comparisons
+func progSwap() *Program {
+	f := NewFun("main", 0)
+	entry := f.NewBlock()
+	loop := f.NewBlock()
+	exit := f.NewBlock()
+
+	entry.Jump(loop, entry.Const(3), entry.Const(10), entry.Const(0))
+
+	a := loop.Param()
+	b := loop.Param()
+	k := loop.Param()
+	k1 := loop.Bin(OpAdd, k, loop.Const(1))
+	lt := loop.Bin(OpLt, a, b)
+	done := loop.Bin(OpEq, k1, loop.Const(5))
+	loop.Branch(done, Edge{Block: exit, Args: []*Value{a, lt}}, Edge{Block: loop, Args: []*Value{b, a, k1}})
+
+	x := exit.Param()
+	c := exit.Param()
+	exit.Return(exit.Bin(OpAdd, exit.Bin(OpMul, x, exit.Const(10)), c))
+
+	return &Program{Funs: []*Fun{f}, Entry: f}
+}
+
+// passes parameters to callee in rotated order
+func progRotate() *Program {
+	g := NewFun("g", 3)
+	gb := g.NewBlock()
+	p := g.Params
+	gb.Return(gb.Bin(OpAdd, gb.Bin(OpMul, p[0], gb.Const(100)), gb.Bin(OpAdd, gb.Bin(OpMul, p[1], gb.Const(10)), p[2])))
+
+	f := NewFun("main", 0)
+	b := f.NewBlock()
+	x := b.Const(1)
+	y := b.Const(2)
+	z := b.Const(3)
+	r := b.Call(g, z, x, y)
+	b.Return(b.Bin(OpAdd, r, x))
+
+	return &Program{Funs: []*Fun{f, g}, Entry: f}
+}
+
+func TestLower(t *testing.T) {
+	tests := []struct {
+		name   string
+		prog   *Program
+		status uint64
+	}{
+		{
+			name:   "1 fib",
+			prog:   progFib(10),
+			status: 55,
+		},
+		{
+			name:   "2 loop sum",
+			prog:   progSum(100),
+			status: 5050,
+		},
+		{
+			name:   "3 swap",
+			prog:   progSwap(),
+			status: 31,
+		},
+		{
+			name:   "4 rotate arguments",
+			prog:   progRotate(),
+			status: 313,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Lower(tt.prog)
+			if err != nil {
+				t.Errorf("Lower() error = %v", err)
+				return
+			}
+
+			var m vm.Machine
+			exit := m.Exec(asm.Assemble(p))
+			if exit.Error != nil {
+				t.Errorf("exit.Error = %v", exit.Error)
+				return
+			}
+			if exit.Status != tt.status {
+				t.Errorf("exit.Status = %d, want %d", exit.Status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package ssa
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+// NumRegs is number of general-purpose registers available to allocator.
+const NumRegs = 64
+
+// interval is a range of instruction positions where value is live.
+// Both ends are inclusive.
+type interval struct {
+	v *Value
+
+	start int
+	end   int
+}
+
+// Allocation maps function values to registers.
+type Allocation struct {
+	// Register assigned to each value. Fused comparisons
+	// have no register.
+	regs map[*Value]opc.Register
+
+	// Live intervals of values with registers.
+	intervals map[*Value]interval
+
+	// Comparisons which are used only as branch condition in
+	// their own block. They are lowered directly into jump flags.
+	fused map[*Value]bool
+
+	// Position of each instruction and block terminator.
+	pos map[any]int
+
+	// Block position ranges.
+	starts map[*Block]int
+	ends   map[*Block]int
+}
+
+// Reg returns register assigned to value.
+func (a *Allocation) Reg(v *Value) opc.Register {
+	r, ok := a.regs[v]
+	if !ok {
+		panic(fmt.Sprintf("value %s has no register", v))
+	}
+	return r
+}
+
+// Fused reports whether comparison is lowered into branch jump flag.
+func (a *Allocation) Fused(v *Value) bool {
+	return a.fused[v]
+}
+
+// LiveAcross returns registers of values which are live before and
+// after instruction, excluding value defined by instruction itself.
+func (a *Allocation) LiveAcross(v *Value) []opc.Register {
+	p := a.pos[v]
+	var regs []opc.Register
+	for u, i := range a.intervals {
+		if u != v && i.start < p && i.end > p {
+			regs = append(regs, a.regs[u])
+		}
+	}
+	slices.Sort(regs)
+	return regs
+}
+
+// Allocate assigns registers to function values with linear scan over
+// live intervals. Intervals are conservative: each covers all positions
+// from value definition to its last use, including whole blocks where
+// value is live on entry or exit.
+//
+// All registers are caller-saved. Returns error if function needs more
+// than NumRegs registers at some point, spilling is not supported.
+func Allocate(f *Fun) (*Allocation, error) {
+	a := &Allocation{
+		regs:      make(map[*Value]opc.Register),
+		intervals: make(map[*Value]interval),
+		fused:     make(map[*Value]bool),
+		pos:       make(map[any]int),
+		starts:    make(map[*Block]int),
+		ends:      make(map[*Block]int),
+	}
+	a.fuse(f)
+	a.number(f)
+	a.build(f)
+	return a, a.scan(f)
+}
+
+// find comparisons which can be fused into branch
+func (a *Allocation) fuse(f *Fun) {
+	uses := make(map[*Value]int)
+	for _, b := range f.Blocks {
+		for _, v := range b.Insts {
+			for _, u := range v.Args {
+				uses[u] += 1
+			}
+		}
+		forTermUses(b.Term, func(u *Value) {
+			uses[u] += 1
+		})
+	}
+	for _, b := range f.Blocks {
+		br, ok := b.Term.(*Branch)
+		if !ok {
+			continue
+		}
+		c := br.Cond
+		if c.Op.Compare() && c.Block == b && uses[c] == 1 {
+			a.fused[c] = true
+		}
+	}
+}
+
+// calls fn for each value used by terminator
+func forTermUses(t Terminator, fn func(*Value)) {
+	switch t := t.(type) {
+	case *Branch:
+		fn(t.Cond)
+	case *Return:
+		if t.Value != nil {
+			fn(t.Value)
+		}
+	}
+	for _, e := range t.Edges() {
+		for _, u := range e.Args {
+			fn(u)
+		}
+	}
+}
+
+// assign positions to instructions, function parameters are
+// defined at position 0
+func (a *Allocation) number(f *Fun) {
+	p := 1
+	for _, b := range f.Blocks {
+		a.starts[b] = p
+		p += 1
+		for _, v := range b.Insts {
+			a.pos[v] = p
+			p += 1
+		}
+		a.pos[b.Term] = p
+		a.ends[b] = p
+		p += 1
+	}
+}
+
+func (a *Allocation) defPos(v *Value) int {
+	switch v.Op {
+	case OpParam:
+		return 0
+	case OpBlockParam:
+		return a.starts[v.Block]
+	default:
+		return a.pos[v]
+	}
+}
+
+// compute liveness and build intervals
+func (a *Allocation) build(f *Fun) {
+	type set = map[*Value]struct{}
+
+	// upward-exposed uses and definitions of each block
+	uses := make(map[*Block]set, len(f.Blocks))
+	defs := make(map[*Block]set, len(f.Blocks))
+	for _, b := range f.Blocks {
+		u := make(set)
+		d := make(set)
+		use := func(v *Value) {
+			_, ok := d[v]
+			if !ok {
+				u[v] = struct{}{}
+			}
+		}
+		for _, v := range b.Params {
+			d[v] = struct{}{}
+		}
+		for _, v := range b.Insts {
+			for _, arg := range v.Args {
+				use(arg)
+			}
+			d[v] = struct{}{}
+		}
+		forTermUses(b.Term, use)
+		uses[b] = u
+		defs[b] = d
+	}
+
+	liveIn := make(map[*Block]set, len(f.Blocks))
+	liveOut := make(map[*Block]set, len(f.Blocks))
+	for _, b := range f.Blocks {
+		liveIn[b] = make(set)
+		liveOut[b] = make(set)
+	}
+	for changed := true; changed; {
+		changed = false
+		for i := len(f.Blocks) - 1; i >= 0; i -= 1 {
+			b := f.Blocks[i]
+			out := liveOut[b]
+			for _, e := range b.Term.Edges() {
+				for v := range liveIn[e.Block] {
+					out[v] = struct{}{}
+				}
+			}
+			in := liveIn[b]
+			n := len(in)
+			for v := range uses[b] {
+				in[v] = struct{}{}
+			}
+			for v := range out {
+				_, ok := defs[b][v]
+				if !ok {
+					in[v] = struct{}{}
+				}
+			}
+			if len(in) != n {
+				changed = true
+			}
+		}
+	}
+
+	extend := func(v *Value, p int) {
+		if a.fused[v] {
+			return
+		}
+		i, ok := a.intervals[v]
+		if !ok {
+			d := a.defPos(v)
+			i = interval{v: v, start: d, end: d}
+		}
+		i.start = min(i.start, p)
+		i.end = max(i.end, p)
+		a.intervals[v] = i
+	}
+
+	for _, v := range f.Params {
+		extend(v, 0)
+	}
+	for _, b := range f.Blocks {
+		for _, v := range b.Params {
+			extend(v, a.starts[b])
+		}
+		for _, v := range b.Insts {
+			extend(v, a.pos[v])
+
+			// arguments of fused comparison are used by terminator
+			p := a.pos[v]
+			if a.fused[v] {
+				p = a.pos[b.Term]
+			}
+			for _, arg := range v.Args {
+				extend(arg, p)
+			}
+		}
+		forTermUses(b.Term, func(v *Value) {
+			extend(v, a.pos[b.Term])
+		})
+		for v := range liveIn[b] {
+			extend(v, a.starts[b])
+		}
+		for v := range liveOut[b] {
+			extend(v, a.ends[b])
+		}
+	}
+}
+
+func (a *Allocation) scan(f *Fun) error {
+	list := make([]interval, 0, len(a.intervals))
+	for _, i := range a.intervals {
+		list = append(list, i)
+	}
+	slices.SortFunc(list, func(x, y interval) int {
+		if x.start != y.start {
+			return x.start - y.start
+		}
+		return x.v.ID - y.v.ID
+	})
+
+	var free [NumRegs]bool
+	for r := range free {
+		free[r] = true
+	}
+	var active []interval
+	for _, i := range list {
+		// expire intervals which ended before current one starts
+		k := 0
+		for _, j := range active {
+			if j.end < i.start {
+				free[a.regs[j.v]] = true
+				continue
+			}
+			active[k] = j
+			k += 1
+		}
+		active = active[:k]
+
+		r := slices.Index(free[:], true)
+		if r < 0 {
+			return fmt.Errorf("%s: more than %d values are live at position %d, spilling is not implemented",
+				f.Name, NumRegs, i.start)
+		}
+		free[r] = false
+		a.regs[i.v] = opc.Register(r)
+		active = append(active, i)
+	}
+	return nil
+}
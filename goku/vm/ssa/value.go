@@ -0,0 +1,174 @@
+// Package ssa implements compiler intermediate representation in static
+// single assignment form. Each value is defined exactly once. Phi nodes
+// are expressed as block parameters: predecessor passes arguments along
+// control flow edge and successor receives them as parameter values.
+package ssa
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Op denotes operation which produces value.
+type Op uint8
+
+const (
+	// Zero value of Op. Should not be used explicitly.
+	empty Op = iota
+
+	// Integer constant.
+	OpConst
+
+	// Function parameter.
+	OpParam
+
+	// Block parameter. Receives argument from incoming edge.
+	OpBlockParam
+
+	// Call to function. Arguments are call arguments.
+	OpCall
+
+	// Binary operations. Have exactly two arguments.
+
+	OpAdd
+	OpSub
+	OpMul
+
+	// Unsigned division and remainder.
+	OpDiv
+	OpRem
+
+	OpAnd
+	OpOr
+	OpXor
+	OpShl
+	OpShr
+
+	// Signed division, remainder and arithmetic shift right.
+	OpSDiv
+	OpSRem
+	OpSar
+
+	// Comparisons. Produce 1 if comparison holds and 0 otherwise.
+
+	OpEq
+	OpNe
+
+	// Signed comparisons.
+	OpLt
+	OpLe
+	OpGt
+	OpGe
+
+	// Unsigned comparisons.
+	OpULt
+	OpULe
+	OpUGt
+	OpUGe
+)
+
+var opText = [...]string{
+	empty: "<nil>",
+
+	OpConst:      "const",
+	OpParam:      "param",
+	OpBlockParam: "bparam",
+	OpCall:       "call",
+
+	OpAdd: "add",
+	OpSub: "sub",
+	OpMul: "mul",
+	OpDiv: "div",
+	OpRem: "rem",
+	OpAnd: "and",
+	OpOr:  "or",
+	OpXor: "xor",
+	OpShl: "shl",
+	OpShr: "shr",
+
+	OpSDiv: "sdiv",
+	OpSRem: "srem",
+	OpSar:  "sar",
+
+	OpEq:  "eq",
+	OpNe:  "ne",
+	OpLt:  "lt",
+	OpLe:  "le",
+	OpGt:  "gt",
+	OpGe:  "ge",
+	OpULt: "ult",
+	OpULe: "ule",
+	OpUGt: "ugt",
+	OpUGe: "uge",
+}
+
+func (op Op) String() string {
+	return opText[op]
+}
+
+// Binary reports whether operation takes exactly two arguments and
+// produces arithmetic or bitwise result.
+func (op Op) Binary() bool {
+	return OpAdd <= op && op <= OpSar
+}
+
+// Compare reports whether operation is a comparison.
+func (op Op) Compare() bool {
+	return OpEq <= op && op <= OpUGe
+}
+
+// Value represents result of operation. All values are 64-bit integers.
+type Value struct {
+	// Operation arguments.
+	Args []*Value
+
+	// Block which defines this value.
+	Block *Block
+
+	// Called function. Only for OpCall.
+	Fun *Fun
+
+	// Optional name used for printing.
+	Name string
+
+	// Constant value for OpConst. Parameter index for OpParam
+	// and OpBlockParam.
+	Aux uint64
+
+	// Unique inside function.
+	ID int
+
+	Op Op
+}
+
+func (v *Value) String() string {
+	if v.Name != "" {
+		return "%" + v.Name
+	}
+	return "%v" + strconv.Itoa(v.ID)
+}
+
+// LongString returns value definition in text form.
+func (v *Value) LongString() string {
+	switch v.Op {
+	case OpConst:
+		return fmt.Sprintf("%s = const %d", v, v.Aux)
+	case OpParam:
+		return fmt.Sprintf("%s = param %d", v, v.Aux)
+	case OpCall:
+		return fmt.Sprintf("%s = call %s(%s)", v, v.Fun.Name, joinValues(v.Args))
+	default:
+		return fmt.Sprintf("%s = %s %s", v, v.Op, joinValues(v.Args))
+	}
+}
+
+func joinValues(list []*Value) string {
+	var s string
+	for i, v := range list {
+		if i != 0 {
+			s += ", "
+		}
+		s += v.String()
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package vm
+
+import (
+	"encoding/binary"
+
+	"github.com/mebyus/ku/goku/vm/opc"
+)
+
+func (m *Machine) execPush(lt uint8) (uint64, *RuntimeError) {
+	var size uint64
+	var val uint64 // pushed value
+	switch opc.Layout(lt) {
+	case opc.PushReg:
+		size = 1
+		data, err := m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		val, err = m.get(opc.Register(data[0]))
+		if err != nil {
+			return 0, err
+		}
+	case opc.PushVal32:
+		size = 4
+		data, err := m.idata(size)
+		if err != nil {
+			return 0, err
+		}
+		val = uint64(val32(data))
+	default:
+		return 0, &RuntimeError{
+			Code: ErrorBadVariant,
+			Aux:  uint64(lt),
+		}
+	}
+
+	err := m.push(val)
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (m *Machine) execPop(lt uint8) (uint64, *RuntimeError) {
+	if opc.Layout(lt) != opc.PopReg {
+		return 0, &RuntimeError{
+			Code: ErrorBadVariant,
+			Aux:  uint64(lt),
+		}
+	}
+
+	const size = 1
+	data, err := m.idata(size)
+	if err != nil {
+		return 0, err
+	}
+	val, err := m.pop()
+	if err != nil {
+		return 0, err
+	}
+	err = m.set(opc.Register(data[0]), val)
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// place 8-byte value on top of the stack
+func (m *Machine) push(val uint64) *RuntimeError {
+	if m.sp+8 > uint64(len(m.stack)) {
+		return &RuntimeError{
+			Code: ErrorStackOverflow,
+			Aux:  m.sp,
+		}
+	}
+	binary.LittleEndian.PutUint64(m.stack[m.sp:], val)
+	m.sp += 8
+
+	if m.sp > m.stats.MaxStackSize {
+		m.stats.MaxStackSize = m.sp
+	}
+	return nil
+}
+
+// remove 8-byte value from top of the stack, values placed
+// by previous frames cannot be removed
+func (m *Machine) pop() (uint64, *RuntimeError) {
+	if m.sp < m.fp+8 {
+		return 0, &RuntimeError{
+			Code: ErrorStackUnderflow,
+			Aux:  m.sp,
+		}
+	}
+	m.sp -= 8
+	return val64(m.stack[m.sp:]), nil
+}
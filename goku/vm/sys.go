@@ -2,6 +2,47 @@ package vm
 
 import "github.com/mebyus/ku/goku/vm/opc"
 
+// Syscall numbers. Syscall is selected by placing its number into
+// #:sc register before executing syscall instruction. Arguments are
+// passed in registers #:r0, #:r1, #:r2. Syscall result is placed
+// into #:sc register. Failed syscall places SysFail into #:sc.
+const (
+	// Exit program with status from #:r0.
+	SysExit = iota
+
+	// Write #:r2 bytes from memory at pointer #:r1 to file
+	// descriptor #:r0. Returns number of written bytes.
+	SysWrite
+
+	// Read at most #:r2 bytes from file descriptor #:r0 into memory
+	// at pointer #:r1. Returns number of read bytes, 0 means end of input.
+	SysRead
+
+	// Returns host monotonic time in nanoseconds.
+	SysClock
+
+	// Grow heap segment by #:r0 bytes. Returns pointer to start
+	// of allocated heap region. Region is not managed by allocator
+	// and cannot be freed.
+	SysHeapGrow
+
+	// Allocate heap block of at least #:r0 bytes. Returns pointer to
+	// zeroed block aligned to HeapAlign. Pointer remains valid until
+	// block is freed.
+	SysHeapAlloc
+
+	// Free heap block at pointer #:r0. Pointer must be obtained from
+	// SysHeapAlloc and not freed before, otherwise runtime error occurs.
+	// Returns 0.
+	SysHeapFree
+)
+
+// SysFail is placed into #:sc register when syscall fails.
+const SysFail = ^uint64(0)
+
+// MaxHeapSize is maximum size of heap memory available to program.
+const MaxHeapSize = 1 << 30
+
 func (m *Machine) execSys(lt uint8) *RuntimeError {
 	switch lt {
 	case opc.Trap:
@@ -13,7 +54,7 @@ func (m *Machine) execSys(lt uint8) *RuntimeError {
 	case opc.Ret:
 		return m.execRet()
 	case opc.SysCall:
-		panic("stub")
+		return m.syscall()
 	default:
 		return &RuntimeError{
 			Code: ErrorTrap,
@@ -23,3 +64,107 @@ func (m *Machine) execSys(lt uint8) *RuntimeError {
 
 	return nil
 }
+
+func (m *Machine) syscall() *RuntimeError {
+	if m.Host == nil {
+		m.Host = NewOSHost()
+	}
+
+	switch m.sc {
+	case SysExit:
+		m.sc = m.r[0]
+		m.halt = true
+	case SysWrite:
+		return m.sysWrite(m.r[0], m.r[1], m.r[2])
+	case SysRead:
+		return m.sysRead(m.r[0], m.r[1], m.r[2])
+	case SysClock:
+		m.sc = m.Host.Clock()
+	case SysHeapGrow:
+		m.sysHeapGrow(m.r[0])
+	case SysHeapAlloc:
+		ptr, ok := m.heapAlloc(m.r[0])
+		if !ok {
+			m.sc = SysFail
+			return nil
+		}
+		m.sc = ptr
+	case SysHeapFree:
+		err := m.heapFree(m.r[0])
+		if err != nil {
+			return err
+		}
+		m.sc = 0
+	default:
+		return &RuntimeError{
+			Code: ErrorBadSyscall,
+			Aux:  m.sc,
+		}
+	}
+	return nil
+}
+
+func (m *Machine) sysWrite(fd, ptr, n uint64) *RuntimeError {
+	if n == 0 {
+		m.sc = 0
+		return nil
+	}
+	if n > 0xFFFFFFFF {
+		return &RuntimeError{
+			Code: ErrorBadAddress,
+			Aux:  ptr,
+		}
+	}
+	b, err := m.memslice(ptr, uint32(n))
+	if err != nil {
+		return err
+	}
+	k, herr := m.Host.Write(fd, b)
+	if herr != nil {
+		m.sc = SysFail
+		return nil
+	}
+	m.sc = uint64(k)
+	return nil
+}
+
+func (m *Machine) sysRead(fd, ptr, n uint64) *RuntimeError {
+	if n == 0 {
+		m.sc = 0
+		return nil
+	}
+	segment, _ := getPointerSegmentAndOffset(ptr)
+	if segment == SegText || segment == SegData {
+		return &RuntimeError{
+			Code: ErrorReadOnlySegment,
+			Aux:  ptr,
+		}
+	}
+	if n > 0xFFFFFFFF {
+		return &RuntimeError{
+			Code: ErrorBadAddress,
+			Aux:  ptr,
+		}
+	}
+	b, err := m.memslice(ptr, uint32(n))
+	if err != nil {
+		return err
+	}
+	k, herr := m.Host.Read(fd, b)
+	if herr != nil {
+		m.sc = SysFail
+		return nil
+	}
+	m.sc = uint64(k)
+	return nil
+}
+
+func (m *Machine) sysHeapGrow(n uint64) {
+	size := uint64(len(m.heap))
+	if n > MaxHeapSize-size {
+		m.sc = SysFail
+		return
+	}
+	m.heap = append(m.heap, make([]byte, n)...)
+	m.sc = uint64(SegHeap)<<56 | size
+}
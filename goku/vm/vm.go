@@ -34,7 +34,14 @@ const (
 	SegHeap   = 0x04
 )
 
+// StackSize is size of stack memory available to program.
+const StackSize = 1 << 20
+
 type Machine struct {
+	// Host which serves syscalls. If nil, host connected to
+	// process standard streams is created on first syscall.
+	Host Host
+
 	// Instruction pointer. Index in text memory.
 	ip uint64
 
@@ -67,11 +74,18 @@ type Machine struct {
 	global []byte
 
 	// Stack memory, size cannot change during execution.
+	//
+	// Stack grows upwards, values are placed at sp index.
 	stack []byte
 
 	// Heap memory, size can change during execution.
+	//
+	// Grows and shrinks via heap syscalls.
 	heap []byte
 
+	// Tracks live and free blocks in heap memory.
+	alloc heapAllocator
+
 	// Stack for storing frames in procedure calls.
 	frames []Frame
 
@@ -93,12 +107,33 @@ type Stats struct {
 }
 
 func (m *Machine) Exec(prog *kvx.Program) *Exit {
+	err := m.Load(prog)
+	if err != nil {
+		return &Exit{Error: err}
+	}
+
+	start := time.Now()
+
+	for !m.halt {
+		m.step()
+		m.clock += 1
+	}
+
+	return m.exit(time.Since(start))
+}
+
+// Load prepares machine for execution of a given program. Resets all
+// machine state left from previous executions.
+func (m *Machine) Load(prog *kvx.Program) *RuntimeError {
 	if len(prog.Text) == 0 {
-		// TODO: fill this errors
-		return &Exit{Error: &RuntimeError{}}
+		return &RuntimeError{Code: ErrorTextEnd}
 	}
 	if int(prog.EntryPoint) >= len(prog.Text) {
-		return &Exit{Error: &RuntimeError{}}
+		return &RuntimeError{
+			Code: ErrorBadCallAddress,
+			Aux:  uint64(prog.EntryPoint),
+			IP:   uint64(prog.EntryPoint),
+		}
 	}
 
 	m.ip = uint64(prog.EntryPoint)
@@ -120,19 +155,40 @@ func (m *Machine) Exec(prog *kvx.Program) *Exit {
 	m.sc = 0
 	m.cf = 0
 	m.clock = 0
-	m.stack = m.stack[:0]
+	m.stats = Stats{}
+	if cap(m.stack) < StackSize {
+		m.stack = make([]byte, StackSize)
+	}
+	m.stack = m.stack[:StackSize]
+	clear(m.stack)
 	m.frames = m.frames[:0]
 	m.heap = m.heap[:0]
+	m.alloc.reset()
 	clear(m.r[:])
+	return nil
+}
 
-	start := time.Now()
-
-	for !m.halt {
-		m.step()
-		m.clock += 1
+// Step executes one instruction of loaded program. Does nothing if
+// machine is already halted. Returns true if execution may continue.
+func (m *Machine) Step() bool {
+	if m.halt {
+		return false
 	}
+	m.step()
+	m.clock += 1
+	return !m.halt
+}
 
-	return m.exit(time.Since(start))
+// Halted returns true if machine was halted by instruction or
+// runtime error.
+func (m *Machine) Halted() bool {
+	return m.halt
+}
+
+// State returns current exit state of the machine. Exit status and
+// error are only meaningful after machine was halted.
+func (m *Machine) State() *Exit {
+	return m.exit(0)
 }
 
 func (m *Machine) step() {
@@ -158,26 +214,27 @@ func (m *Machine) step() {
 		size, err = m.execJump(lt)
 	case opc.Call:
 		size, err = m.execCall(lt)
+	case opc.Push:
+		size, err = m.execPush(lt)
+	case opc.Pop:
+		size, err = m.execPop(lt)
 	case opc.Clear:
-		// m.clear()
+		size, err = m.execClear(lt)
 	case opc.Set:
 		size, err = m.execSet(lt)
 	case opc.Load:
+		size, err = m.execLoad(lt)
 	case opc.Store:
-		// err = m.loadValReg()
-		// case LoadRegReg:
-		// 	err = m.loadRegReg()
-		// case LoadValSysReg:
-		// 	m.loadValSysReg()
-	case opc.Inc:
-	case opc.Add:
-	case opc.Sub:
-		// err = m.addRegReg()
-		// err = m.incReg()
+		size, err = m.execStore(lt)
 	case opc.Test:
-		// err = m.testRegVal()
-	// case JumpFlagAddr:
-	// 	err = m.jumpFlagAddr()
+		size, err = m.execTest(lt)
+	case opc.Inc:
+		size, err = m.execInc(lt, false)
+	case opc.Dec:
+		size, err = m.execInc(lt, true)
+	case opc.Add, opc.Sub, opc.Mul, opc.Div, opc.Rem, opc.And, opc.Or, opc.Xor, opc.Shl, opc.Shr,
+		opc.SDiv, opc.SRem, opc.Sar:
+		size, err = m.execArith(op, lt)
 	default:
 		m.stop(&RuntimeError{
 			Code: ErrorBadOpcode,
@@ -198,8 +255,18 @@ func (m *Machine) step() {
 	}
 }
 
-// switch to halt state with runtime error
+// switch to halt state with runtime error, fills error with
+// current instruction and backtrace
 func (m *Machine) stop(err *RuntimeError) {
+	err.IP = m.ip
+	if m.ip < uint64(len(m.text)) {
+		err.Opcode = opc.Opcode(m.text[m.ip])
+		if m.ip+1 < uint64(len(m.text)) {
+			err.Layout = m.text[m.ip+1]
+		}
+	}
+	err.Backtrace = m.Frames()
+
 	m.err = err
 	m.halt = true
 }
@@ -223,9 +290,11 @@ func getPointerSegmentAndOffset(ptr uint64) (uint8, uint32) {
 	return segment, offset
 }
 
-func (m *Machine) memslice(ptr uint64, n uint32) ([]byte, error) {
+// get n bytes of memory at a given pointer, checks that pointer segment
+// is valid and that all bytes lie within segment bounds
+func (m *Machine) memslice(ptr uint64, n uint32) ([]byte, *RuntimeError) {
 	if n == 0 {
-		return nil, fmt.Errorf("empty slice")
+		panic("empty slice")
 	}
 
 	segment, offset := getPointerSegmentAndOffset(ptr)
@@ -242,17 +311,30 @@ func (m *Machine) memslice(ptr uint64, n uint32) ([]byte, error) {
 	case SegHeap:
 		b = m.heap
 	default:
-		return nil, fmt.Errorf("unknown segment (=0x%02X) in pointer (=0x%016X)", segment, ptr)
+		return nil, &RuntimeError{
+			Code: ErrorBadSegment,
+			Aux:  ptr,
+		}
 	}
 
-	if offset >= uint32(len(b)) {
-		return nil, fmt.Errorf("offset (=0x%08X) is out of (=0x%02X) segment range", offset, segment)
+	// segment sizes fit into 32 bits, thus any pointer with non-zero
+	// high offset bits is out of range
+	const high = 0x00FFFFFF00000000
+
+	// compare in 64 bits to avoid overflow of offset + n
+	if ptr&high != 0 || uint64(offset)+uint64(n) > uint64(len(b)) {
+		return nil, &RuntimeError{
+			Code: ErrorBadAddress,
+			Aux:  ptr,
+		}
 	}
-	end := offset + n
-	if end > uint32(len(b)) {
-		return nil, fmt.Errorf("end (=0x%08X) is out of (=0x%02X) segment range", end, segment)
+	if segment == SegHeap && m.alloc.freed(offset, n) {
+		return nil, &RuntimeError{
+			Code: ErrorFreedAddress,
+			Aux:  ptr,
+		}
 	}
-	return b[offset:end], nil
+	return b[offset : offset+n], nil
 }
 
 // get register value
@@ -363,6 +445,16 @@ func (e *Exit) Render(w io.Writer) error {
 		return err
 	}
 
+	_, err = io.WriteString(w, fmt.Sprintf("vm.stack: %d\n", e.MaxStackSize))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, fmt.Sprintf("vm.depth: %d\n", e.MaxFrames))
+	if err != nil {
+		return err
+	}
+
 	s := e.String()
 	_, err = io.WriteString(w, s)
 	if err != nil {
@@ -381,7 +473,7 @@ func (e *Exit) String() string {
 		return fmt.Sprintf("vm: normal exit (at 0x%08X) with status %d", e.IP, e.Status)
 	}
 
-	return fmt.Sprintf("vm: abnormal exit (at 0x%08X) with runtime error: %v", e.IP, e.Error)
+	return fmt.Sprintf("vm: abnormal exit with runtime error: %v", e.Error)
 }
 
 func (m *Machine) exit(dur time.Duration) *Exit {
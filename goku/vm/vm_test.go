@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/mebyus/ku/goku/compiler/sm"
 	"github.com/mebyus/ku/goku/vm/asm"
 )
 
@@ -72,6 +73,67 @@ func TestMachine_Exec(t *testing.T) {
 				Status: 8,
 			},
 		},
+		{
+			name: "9 arith",
+			code: code9,
+			want: &Exit{
+				Error:  nil,
+				Status: 0x3F,
+			},
+		},
+		{
+			name: "10 div by zero",
+			code: code10,
+			want: &Exit{
+				Error: &RuntimeError{Code: ErrorDivByZero},
+			},
+		},
+		{
+			name: "11 load store",
+			code: code11,
+			want: &Exit{
+				Error:  nil,
+				Status: 0x1234,
+			},
+		},
+		{
+			name: "12 bad address",
+			code: code12,
+			want: &Exit{
+				Error: &RuntimeError{Code: ErrorBadAddress},
+			},
+		},
+		{
+			name: "13 signed compare",
+			code: code13,
+			want: &Exit{
+				Error:  nil,
+				Status: 2,
+			},
+		},
+		{
+			name: "14 pop underflow",
+			code: code14,
+			want: &Exit{
+				Error: &RuntimeError{Code: ErrorStackUnderflow},
+			},
+		},
+		{
+			name: "15 entry after other function",
+			code: code15,
+			want: &Exit{
+				Error:  nil,
+				Status: 5,
+			},
+		},
+		{
+			name: "16 signed arith",
+			code: code16,
+			want: &Exit{
+				Error:  nil,
+				Status: 8,
+			},
+		},
 	}
 
 	var m Machine
@@ -92,6 +154,10 @@ func TestMachine_Exec(t *testing.T) {
 				t.Errorf("exit.Error = <nil>, want (%d) %s", tt.want.Error.Code, tt.want.Error)
 				return
 			}
+			if tt.want.Error != nil && exit.Error.Code != tt.want.Error.Code {
+				t.Errorf("exit.Error = (%d) %s, want (%d) %s", exit.Error.Code, exit.Error, tt.want.Error.Code, tt.want.Error)
+				return
+			}
 			if exit.Status != tt.want.Status {
 				t.Errorf("exit.Status = %d, want %d", exit.Status, tt.want.Status)
 			}
@@ -207,3 +273,558 @@ const code8 = `
 	ret;
 }
 `
+
+const code9 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 7;
+	set		#:r1, 6;
+	mul		#:r2, #:r0, #:r1;	// 42
+	div		#:r3, #:r2, 5;		// 8
+	rem		#:r4, #:r2, 5;		// 2
+	shl		#:r3, #:r3, #:r4;	// 32
+	or		#:r3, 0xF;			// 47
+	xor		#:r3, 0x10;			// 63
+	and		#:r3, 0xFF;
+	set		#:sc, #:r3;
+	halt;
+}
+`
+
+const code10 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 7;
+	div		#:r0, #:r1;
+	halt;
+}
+`
+
+const code11 = `
+#entry start;
+
+#fun start {
+	set		#:r1, 0x0300000000000000;
+	set		#:r0, 0x1234;
+	store.u16	#:r1, #:r0, 8;
+	load.u16	#:r2, #:r1, 8;
+	load.u8		#:r3, #:r1, 9;
+	set		#:sc, #:r2;
+	test	#:r3, 0x12;
+	jump.z	@.exit;
+	trap;
+
+@.exit:
+	halt;
+}
+`
+
+const code12 = `
+#entry start;
+
+#fun start {
+	load	#:r0, 0x0100000000000000;
+	halt;
+}
+`
+
+const code13 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 0;
+	dec		#:r0;
+	test	#:r0, 1;
+	jump.ae	@.unsigned;
+	trap;
+
+@.unsigned:
+	test	#:r0, 1;
+	jump.l	@.signed;
+	trap;
+
+@.signed:
+	set		#:sc, 2;
+	halt;
+}
+`
+
+const code14 = `
+#entry start;
+
+#fun start {
+	push	#:r0;
+	call	main;
+	halt;
+}
+
+#fun main {
+	pop		#:r0;
+	ret;
+}
+`
+
+const code15 = `
+#entry start;
+
+#fun main {
+	set		#:sc, 5;
+	ret;
+}
+
+#fun start {
+	call	main;
+	halt;
+}
+`
+
+const code16 = `
+#entry start;
+
+#fun start {
+	clear	#:r0;
+	sub		#:r0, 7;			// -7
+	sdiv	#:r1, #:r0, 2;		// -3
+	srem	#:r2, #:r0, 2;		// -1
+	sar		#:r3, #:r0, 1;		// -4
+	add		#:r4, #:r1, #:r2;
+	add		#:r4, #:r3;			// -8
+	clear	#:r5;
+	sub		#:r5, #:r4;			// 8
+	set		#:sc, #:r5;
+	halt;
+}
+`
+
+func TestMachine_Syscall(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		stdin  string
+		stdout string
+		stderr string
+		status uint64
+	}{
+		{
+			name:   "1 exit",
+			code:   syscode1,
+			status: 7,
+		},
+		{
+			name:   "2 hello",
+			code:   syscode2,
+			stdout: "Hello, world!\n",
+			stderr: "bye\n",
+		},
+		{
+			name:   "3 echo",
+			code:   syscode3,
+			stdin:  "abcdefghij",
+			stdout: "abcdefghij",
+			status: 10,
+		},
+		{
+			name:   "4 heap grow",
+			code:   syscode4,
+			stdout: "ok",
+			status: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := asm.Compile(strings.NewReader(tt.code))
+			if err != nil {
+				t.Errorf("asm.Compile() error = %v", err)
+				return
+			}
+			var stdout, stderr strings.Builder
+			m := Machine{
+				Host: &IOHost{
+					Stdin:  strings.NewReader(tt.stdin),
+					Stdout: &stdout,
+					Stderr: &stderr,
+				},
+			}
+			exit := m.Exec(prog)
+			if exit.Error != nil {
+				t.Errorf("exit.Error = (%d) %s", exit.Error.Code, exit.Error)
+				return
+			}
+			if exit.Status != tt.status {
+				t.Errorf("exit.Status = %d, want %d", exit.Status, tt.status)
+			}
+			if stdout.String() != tt.stdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.stdout)
+			}
+			if stderr.String() != tt.stderr {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.stderr)
+			}
+		})
+	}
+}
+
+const syscode1 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 7;
+	set		#:sc, 0;
+	syscall;
+	trap;
+}
+`
+
+const syscode2 = `
+#data hello "Hello, world!\n";
+#data bye "bye\n";
+
+#entry start;
+
+#fun start {
+	set		#:r0, 1;
+	set		#:r1, hello;
+	set		#:r2, 14;
+	set		#:sc, 1;
+	syscall;
+
+	set		#:r0, 2;
+	set		#:r1, bye;
+	set		#:r2, 4;
+	set		#:sc, 1;
+	syscall;
+
+	clear	#:sc;
+	halt;
+}
+`
+
+// Reads up to 16 bytes from stdin into buffer on stack
+// and writes them back to stdout. Exits with number of read bytes.
+const syscode3 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 0;
+	set		#:r1, 0x0300000000000000;
+	set		#:r2, 16;
+	set		#:sc, 2;
+	syscall;
+
+	set		#:r2, #:sc;
+	set		#:r3, #:sc;
+	set		#:r0, 1;
+	set		#:sc, 1;
+	syscall;
+
+	set		#:sc, #:r3;
+	halt;
+}
+`
+
+// Allocates 2 bytes on heap, stores text there and prints it.
+const syscode4 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 2;
+	set		#:sc, 4;
+	syscall;
+
+	set		#:r1, #:sc;
+	set		#:r4, 0x6B6F;
+	store.u16	#:r1, #:r4;
+
+	set		#:r0, 1;
+	set		#:r2, 2;
+	set		#:sc, 1;
+	syscall;
+	halt;
+}
+`
+
+func TestMachine_Heap(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+
+		// expected error code, 0 means normal exit
+		err ErrorCode
+
+		// expected faulting pointer
+		ptr uint64
+
+		status uint64
+
+		// expected heap segment size after exit
+		heap uint64
+	}{
+		{
+			name:   "1 reuse freed block",
+			code:   heapcode1,
+			status: 1,
+			heap:   16,
+		},
+		{
+			name: "2 use after free",
+			code: heapcode2,
+			err:  ErrorFreedAddress,
+			ptr:  0x0400000000000000,
+			heap: 32,
+		},
+		{
+			name: "3 double free",
+			code: heapcode3,
+			err:  ErrorBadFree,
+			ptr:  0x0400000000000000,
+			heap: 32,
+		},
+		{
+			name: "4 out of block bounds",
+			code: heapcode4,
+			err:  ErrorBadAddress,
+			ptr:  0x0400000000000008,
+			heap: 8,
+		},
+		{
+			name: "5 empty global segment",
+			code: heapcode5,
+			err:  ErrorBadAddress,
+			ptr:  0x0200000000000000,
+		},
+		{
+			name: "6 bad segment",
+			code: heapcode6,
+			err:  ErrorBadSegment,
+			ptr:  0x0500000000000000,
+		},
+		{
+			name: "7 free last block shrinks heap",
+			code: heapcode7,
+			heap: 0,
+		},
+	}
+	var m Machine
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := asm.Compile(strings.NewReader(tt.code))
+			if err != nil {
+				t.Errorf("asm.Compile() error = %v", err)
+				return
+			}
+			exit := m.Exec(prog)
+			if tt.err == 0 && exit.Error != nil {
+				t.Errorf("exit.Error = (%d) %s", exit.Error.Code, exit.Error)
+				return
+			}
+			if tt.err != 0 {
+				if exit.Error == nil {
+					t.Errorf("exit.Error = <nil>, want (%d)", tt.err)
+					return
+				}
+				if exit.Error.Code != tt.err {
+					t.Errorf("exit.Error = (%d) %s, want (%d)", exit.Error.Code, exit.Error, tt.err)
+					return
+				}
+				ptr, ok := exit.Error.Pointer()
+				if !ok || ptr != tt.ptr {
+					t.Errorf("exit.Error.Pointer() = 0x%016X, %v, want 0x%016X", ptr, ok, tt.ptr)
+				}
+			}
+			if exit.Status != tt.status {
+				t.Errorf("exit.Status = %d, want %d", exit.Status, tt.status)
+			}
+			heap := m.SegmentSize(SegHeap)
+			if heap != tt.heap {
+				t.Errorf("heap size = %d, want %d", heap, tt.heap)
+			}
+		})
+	}
+}
+
+// Allocates two blocks, frees the first one and allocates block
+// of the same size again. Exits with 1 if freed block was reused.
+const heapcode1 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 5;
+	set		#:sc, 5;
+	syscall;
+	set		#:r5, #:sc;
+
+	set		#:r0, 8;
+	set		#:sc, 5;
+	syscall;
+
+	set		#:r0, #:r5;
+	set		#:sc, 6;
+	syscall;
+
+	set		#:r0, 8;
+	set		#:sc, 5;
+	syscall;
+
+	test	#:r5, #:sc;
+	jump.nz	@.fail;
+	set		#:sc, 1;
+	halt;
+
+@.fail:
+	clear	#:sc;
+	halt;
+}
+`
+
+const heapcode2 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 16;
+	set		#:sc, 5;
+	syscall;
+	set		#:r5, #:sc;
+
+	set		#:r0, 16;
+	set		#:sc, 5;
+	syscall;
+
+	set		#:r0, #:r5;
+	set		#:sc, 6;
+	syscall;
+
+	load.u64	#:r1, #:r5;
+	halt;
+}
+`
+
+const heapcode3 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 16;
+	set		#:sc, 5;
+	syscall;
+	set		#:r5, #:sc;
+
+	set		#:r0, 16;
+	set		#:sc, 5;
+	syscall;
+
+	set		#:r0, #:r5;
+	set		#:sc, 6;
+	syscall;
+
+	set		#:r0, #:r5;
+	set		#:sc, 6;
+	syscall;
+	halt;
+}
+`
+
+const heapcode4 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 8;
+	set		#:sc, 5;
+	syscall;
+	set		#:r5, #:sc;
+
+	store.u64	#:r5, #:r0;
+	load.u64	#:r1, #:r5, 8;
+	halt;
+}
+`
+
+const heapcode5 = `
+#entry start;
+
+#fun start {
+	set		#:r5, 0x0200000000000000;
+	load.u8	#:r1, #:r5;
+	halt;
+}
+`
+
+const heapcode6 = `
+#entry start;
+
+#fun start {
+	set		#:r5, 0x0500000000000000;
+	load.u8	#:r1, #:r5;
+	halt;
+}
+`
+
+const heapcode7 = `
+#entry start;
+
+#fun start {
+	set		#:r0, 8;
+	set		#:sc, 5;
+	syscall;
+
+	set		#:r0, 24;
+	set		#:sc, 5;
+	syscall;
+	set		#:r5, #:sc;
+
+	set		#:r0, #:sc;
+	set		#:sc, 6;
+	syscall;
+
+	set		#:r0, 0x0400000000000000;
+	set		#:sc, 6;
+	syscall;
+	halt;
+}
+`
+
+func TestRuntimeError_Render(t *testing.T) {
+	prog, err := asm.CompileText(sm.NewText("bad.kasm", []byte(errcode1)))
+	if err != nil {
+		t.Fatalf("asm.CompileText() error = %v", err)
+	}
+
+	var m Machine
+	exit := m.Exec(prog)
+	if exit.Error == nil {
+		t.Fatalf("exit.Error = <nil>, want (%d)", ErrorDivByZero)
+	}
+
+	var out strings.Builder
+	err = exit.Error.Render(&out, prog)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := `bad.kasm:16:2 runtime error: division by zero at 0x00000016 [div, layout=0x00]
+    at 0x00000016 g+0x3 (bad.kasm:16:2)
+    at 0x00000011 f+0x6 (bad.kasm:10:2)
+    at 0x00000009 start+0x9 (bad.kasm:5:2)
+`
+	if out.String() != want {
+		t.Errorf("Render() = \n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+const errcode1 = `#entry start;
+
+#fun start {
+	set		#:r0, 3;
+	call	f;
+	halt;
+}
+
+#fun f {
+	call	g;
+	ret;
+}
+
+#fun g {
+	clear	#:r1;
+	div		#:r0, #:r0, #:r1;
+	ret;
+}
+`
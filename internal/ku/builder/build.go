@@ -2,13 +2,17 @@ package builder
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/mebyus/ku/goku/compiler/cc"
 	"github.com/mebyus/ku/goku/compiler/enums/bk"
+	"github.com/mebyus/ku/goku/compiler/pkgs"
+	"github.com/mebyus/ku/internal/ku/enums/symk"
 	"github.com/mebyus/ku/internal/ku/genc"
 	"github.com/mebyus/ku/internal/ku/stg"
 	"github.com/mebyus/ku/internal/ku/sx"
@@ -37,15 +41,33 @@ type Config struct {
 	// Filled based on Unit field.
 	unit sx.Path
 
+	// Base name of output artifacts. Last element of unit path.
+	name string
+
 	// System path to directory which will be used as output directory for various
 	// intermediate files and/or build result artifacts.
 	OutDir string
 
 	// Path to output translated C code, object file or executable produced as build result.
-	// Note that different build types produce different kind of artifacts.
+	// Note that different build phases produce different kind of artifacts.
 	//
-	// Default value will be used if empty.
+	// Default value is based on unit name and build phase. For example,
+	// for unit "foo/bar" object file is placed into "<OutDir>/obj/bar.o".
 	OutPath string
+
+	// Which artifact build produces. Defaults to PhaseObj.
+	Phase Phase
+
+	// Specifies optimizations and safety checks of produced artifact.
+	// Defaults to bk.Debug.
+	BuildKind bk.Kind
+
+	// Human readable build output (diagnostics, progress) is written here.
+	// Output is discarded if nil.
+	Output io.Writer
+
+	// Format of diagnostics written to output. Defaults to sx.TextErrors.
+	DiagFormat sx.ErrorFormat
 }
 
 // Result describes build result.
@@ -53,10 +75,21 @@ type Result struct {
 	// May be empty if build resulted in error.
 	OutPath string
 
+	// Diagnostics found in program source code. Listed in order of
+	// discovery: walker errors go first, then parser and typer errors
+	// of units in import order.
+	Diagnostics []Diagnostic
+
+	// Number of errors among diagnostics.
+	ErrorCount int
+
 	// Not nil if build was interrupted by error.
 	Error error
 }
 
+// Build builds a given unit. Build errors (including diagnostics found in
+// source code) are reported in result, this function never terminates
+// the process.
 func Build(config *Config) *Result {
 	r := &Result{}
 
@@ -66,36 +99,41 @@ func Build(config *Config) *Result {
 		return r
 	}
 
+	resolver, err := pkgs.NewResolver(config.RootDir)
+	if err != nil {
+		r.Error = err
+		return r
+	}
+
 	w := walker{
 		std: filepath.Join(config.LangDir, "src", "std"),
 		loc: filepath.Join(config.RootDir, "src"),
+		pkg: resolver,
 	}
 	w.init(witem{path: config.unit})
 	w.walk()
+	units := w.rank()
 
-	n := 0 // total number of errors
-
-	// TODO: bring back these errors into total counter
-	// n += len(w.errors)
+	rep := reporter{
+		pool:   w.pool,
+		out:    config.Output,
+		format: config.DiagFormat,
+	}
 	for _, e := range w.errors {
-		sx.FormatError(w.pool, os.Stderr, e)
+		rep.add(StageWalk, e)
 	}
-
-	for _, u := range w.units {
-		n += len(u.errors)
+	for _, u := range units {
 		for _, e := range u.errors {
-			// TODO: refactor into sx.Error
-			pos := w.pool.DecodePin(e.Pin)
-			fmt.Fprintf(os.Stderr, "%s: %s\n", pos, e.Short)
+			rep.add(StageParse, &sx.Error{
+				Short: e.Short,
+				Pin:   e.Pin,
+			})
 		}
 	}
 
-	// TODO: rank units based on imports
-	// TODO: output parsing errors based on rank order
-
 	var prog stg.Program
 	pool := stg.NewPool(w.pool)
-	for _, u := range w.units {
+	for _, u := range units {
 		typer := pool.Get()
 		unit := &stg.Unit{
 			Path: u.path,
@@ -105,17 +143,32 @@ func Build(config *Config) *Result {
 		typer.Do(unit, u.texts)
 		pool.Put(typer)
 
-		n += len(unit.Errors)
 		for _, e := range unit.Errors {
-			pos := w.pool.DecodePin(e.Pin)
-			fmt.Fprintf(os.Stderr, "%s: %s\n", pos, e.Short)
+			rep.add(StageType, &sx.Error{
+				Short: e.Short,
+				Pin:   e.Pin,
+			})
 		}
 
 		prog.Units = append(prog.Units, unit)
 	}
 
-	if n != 0 {
-		os.Exit(1)
+	r.Diagnostics = rep.list
+	r.ErrorCount = len(rep.list)
+	if r.ErrorCount != 0 {
+		r.Error = rep.err()
+		return r
+	}
+
+	if config.Phase == PhaseExe {
+		// entry unit imports all other units, thus it is always ranked last
+		entry := prog.Units[len(prog.Units)-1]
+		main := entry.Scope.Get("main")
+		if main == nil || main.Kind != symk.Fun {
+			r.Error = fmt.Errorf("unit \"%s\" has no main function", config.Unit)
+			return r
+		}
+		prog.Main = main
 	}
 
 	stg.AssignLinkNames(prog.Units)
@@ -123,39 +176,52 @@ func Build(config *Config) *Result {
 	// TODO: should we pass Common to pool by pointer from Program instead?
 	prog.Common = pool.Common
 
-	progName := filepath.Join(config.OutDir, "genc", "out.c")
+	src := config.OutPath
+	if config.Phase != PhaseC {
+		src = filepath.Join(config.OutDir, PhaseC.String(), config.name+PhaseC.Suffix())
+	}
 
 	const debug = false
 
 	start := time.Now()
-	err = genProg(progName, &prog)
+	err = genProg(src, config.LangDir, &prog)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "genc: %s\n", err)
-		os.Exit(1)
+		r.Error = fmt.Errorf("genc: %w", err)
+		return r
 	}
 	if debug {
 		fmt.Printf("genc:  %s\n", time.Since(start))
 	}
 
-	{
-		dir := filepath.Dir(config.OutPath)
-		err := os.MkdirAll(dir, 0o755)
-		if err != nil {
-			r.Error = err
-			return r
-		}
+	if config.Phase == PhaseC {
+		r.OutPath = config.OutPath
+		return r
+	}
+
+	err = os.MkdirAll(filepath.Dir(config.OutPath), 0o755)
+	if err != nil {
+		r.Error = err
+		return r
 	}
 
 	start = time.Now()
-	err = cc.CompileObj(config.OutPath, progName, bk.Debug)
+	switch config.Phase {
+	case PhaseObj:
+		err = cc.CompileObj(config.OutPath, src, config.BuildKind, nil)
+	case PhaseExe:
+		err = cc.CompileExe(config.OutPath, src, config.BuildKind, nil)
+	default:
+		panic(fmt.Sprintf("unexpected %s phase", config.Phase))
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "cc: %s\n", err)
-		os.Exit(1)
+		r.Error = fmt.Errorf("cc: %w", err)
+		return r
 	}
 	if debug {
 		fmt.Printf("cc:    %s\n", time.Since(start))
 	}
 
+	r.OutPath = config.OutPath
 	return r
 }
 
@@ -191,16 +257,28 @@ func (c *Config) setDefaults() error {
 		c.RootDir = dir
 	}
 
+	if c.Output == nil {
+		c.Output = io.Discard
+	}
+
 	if c.OutDir == "" {
 		c.OutDir = filepath.Join(c.RootDir, ".kub")
 	}
 
+	if c.Phase == 0 {
+		c.Phase = PhaseObj
+	}
+	if c.BuildKind == 0 {
+		c.BuildKind = bk.Debug
+	}
+
+	_, s := c.unit.Import()
+	c.name = path.Base(s)
+	if c.name == "" || c.name == "." || c.name == "/" {
+		panic("empty out name")
+	}
 	if c.OutPath == "" {
-		name := filepath.Base(c.Unit)
-		if name == "" {
-			panic("empty out name")
-		}
-		c.OutPath = filepath.Join(c.OutDir, "obj", "out.o")
+		c.OutPath = filepath.Join(c.OutDir, c.Phase.String(), c.name+c.Phase.Suffix())
 	}
 
 	return nil
@@ -221,7 +299,7 @@ func GetLangDir() (string, error) {
 }
 
 // genProg generates C code into specified output file.
-func genProg(out string, prog *stg.Program) error {
+func genProg(out string, langDir string, prog *stg.Program) error {
 	dir := filepath.Dir(out)
 	err := os.MkdirAll(dir, 0o755)
 	if err != nil {
@@ -234,5 +312,5 @@ func genProg(out string, prog *stg.Program) error {
 	}
 	defer file.Close()
 
-	return genc.Gen(file, prog)
+	return genc.Gen(file, langDir, prog)
 }
@@ -0,0 +1,98 @@
+package builder
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mebyus/ku/internal/ku/sx"
+)
+
+// Stage indicates which build stage produced diagnostic.
+type Stage uint8
+
+const (
+	// Unit discovery, package resolution and import ranking.
+	StageWalk Stage = iota + 1
+
+	// Parsing unit source texts.
+	StageParse
+
+	// Type checking and translation of unit texts.
+	StageType
+)
+
+var stageText = [...]string{
+	0: "<nil>",
+
+	StageWalk:  "walk",
+	StageParse: "parse",
+	StageType:  "type",
+}
+
+func (s Stage) String() string {
+	return stageText[s]
+}
+
+// Diagnostic describes a single problem found in program source code during build.
+type Diagnostic struct {
+	// Source position where diagnostic should be attributed.
+	//
+	// Has empty path if diagnostic is not attributed to a specific place.
+	Pos sx.FilePos
+
+	// Short message that describes what is wrong.
+	Short string
+
+	// Possibly long description with additional info.
+	// May be empty.
+	Note string
+
+	Stage Stage
+}
+
+// collects diagnostics and writes them to output in specified format
+type reporter struct {
+	list []Diagnostic
+
+	pool *sx.Pool
+
+	out io.Writer
+
+	format sx.ErrorFormat
+}
+
+func (r *reporter) add(stage Stage, e *sx.Error) {
+	d := Diagnostic{
+		Short: e.Short,
+		Note:  e.Note,
+		Stage: stage,
+	}
+	if e.Pin != 0 {
+		d.Pos = r.pool.DecodePin(e.Pin)
+	}
+	r.list = append(r.list, d)
+
+	if e.Code == "" {
+		e.Code = stage.String()
+	}
+	sx.WriteError(r.pool, r.out, r.format, e)
+}
+
+// build error which summarizes reported diagnostics
+func (r *reporter) err() error {
+	if len(r.list) == 0 {
+		return nil
+	}
+	return &ReportError{Count: len(r.list)}
+}
+
+// ReportError indicates that build failed due to diagnostics which were
+// already written to output.
+type ReportError struct {
+	// Number of reported diagnostics.
+	Count int
+}
+
+func (e *ReportError) Error() string {
+	return fmt.Sprintf("build failed with %d error(s)", e.Count)
+}
@@ -0,0 +1,53 @@
+package builder
+
+import "fmt"
+
+// Phase specifies which artifact build produces.
+type Phase uint8
+
+const (
+	// Only generate C code.
+	PhaseC Phase = iota + 1
+
+	// Compile generated C code into object file.
+	PhaseObj
+
+	// Link executable with entrypoint which calls main function of the unit.
+	PhaseExe
+)
+
+var phaseText = [...]string{
+	0: "<nil>",
+
+	PhaseC:   "c",
+	PhaseObj: "obj",
+	PhaseExe: "exe",
+}
+
+func (p Phase) String() string {
+	return phaseText[p]
+}
+
+// Suffix returns extension of output file produced by this phase.
+func (p Phase) Suffix() string {
+	switch p {
+	case PhaseC:
+		return ".gen.c"
+	case PhaseObj:
+		return ".o"
+	case PhaseExe:
+		return ""
+	default:
+		panic(fmt.Sprintf("unexpected phase (=%d)", p))
+	}
+}
+
+// ParsePhase returns phase by its name.
+func ParsePhase(s string) (Phase, error) {
+	for p := PhaseC; p <= PhaseExe; p += 1 {
+		if phaseText[p] == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown build phase \"%s\"", s)
+}
@@ -0,0 +1,102 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mebyus/ku/goku/graphs"
+	"github.com/mebyus/ku/internal/ku/sx"
+)
+
+// rank orders loaded units by their imports. Imported units always go before
+// units which import them. Units inside one cohort are ordered by their id.
+//
+// Reports import cycles as walker errors. Returns nil if cycle was found.
+func (w *walker) rank() []*unit {
+	var g graphs.Graph
+	g.Nodes = make([]graphs.Node, len(w.units))
+	g.Rank = make([]uint32, len(w.units))
+
+	for i, u := range w.units {
+		for _, s := range u.imports {
+			id, ok := w.m[s.path]
+			if !ok {
+				// unit failed to load, error is already reported
+				continue
+			}
+			if id == u.id {
+				w.addError(&sx.Error{
+					Pin:   s.pin,
+					Short: fmt.Sprintf("unit \"%s\" imports itself", u.path),
+				})
+				continue
+			}
+
+			g.Nodes[i].AddAnc(uint32(id))
+			g.Nodes[id].AddDes(uint32(i))
+		}
+
+		if len(g.Nodes[i].Anc) == 0 {
+			g.Roots = append(g.Roots, uint32(i))
+		}
+	}
+
+	var s graphs.Scout
+	c := s.RankOrFindCycle(&g)
+	if c != nil {
+		w.addError(w.cycleError(c))
+		return nil
+	}
+
+	units := make([]*unit, 0, len(w.units))
+	for _, cohort := range g.Cohorts {
+		for _, i := range cohort {
+			units = append(units, w.units[i])
+		}
+	}
+	return units
+}
+
+// Creates error which lists every import site of a given cycle.
+//
+// In cycle each node imports the next one, the last node imports
+// the first one.
+func (w *walker) cycleError(c *graphs.Cycle) *sx.Error {
+	n := len(c.Nodes)
+	sites := make([]isite, 0, n)
+	for i := range n {
+		u := w.units[c.Nodes[i]]
+		imported := w.units[c.Nodes[(i+1)%n]]
+		s, ok := u.findImportSite(imported.path)
+		if !ok {
+			panic(fmt.Sprintf("unable to find \"%s\" import inside \"%s\"", imported.path, u.path))
+		}
+		sites = append(sites, s)
+	}
+
+	var g strings.Builder
+	for i, s := range sites {
+		if i != 0 {
+			g.WriteByte('\n')
+		}
+		g.WriteString(w.pool.FormatPin(s.pin))
+		g.WriteString(": imports \"")
+		g.WriteString(s.path.String())
+		g.WriteString("\"")
+	}
+
+	return &sx.Error{
+		Pin:   sites[0].pin,
+		Short: fmt.Sprintf("import cycle of %d units", n),
+		Note:  g.String(),
+	}
+}
+
+func (u *unit) findImportSite(path sx.Path) (isite, bool) {
+	for _, s := range u.imports {
+		if s.path == path {
+			return s, true
+		}
+	}
+	return isite{}, false
+}
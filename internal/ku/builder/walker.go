@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/mebyus/ku/goku/compiler/pkgs"
 	"github.com/mebyus/ku/internal/ku/ast"
 	"github.com/mebyus/ku/internal/ku/parser"
 	"github.com/mebyus/ku/internal/ku/sx"
@@ -25,6 +26,10 @@ type walker struct {
 	// Base directory for local units lookup.
 	loc string
 
+	// Resolves units with "pkg" origin. Such units cannot be imported
+	// if left nil.
+	pkg *pkgs.Resolver
+
 	// maps unit path to its unique id (which equals to its index inside units list)
 	m map[sx.Path]uid
 
@@ -101,7 +106,7 @@ func (w *walker) walk() {
 
 // returns true if unit was loaded successfully (there may still be some errors)
 func (w *walker) load(item witem, u *unit) bool {
-	dir := w.resolve(item.path)
+	dir := w.resolve(item)
 	if dir == "" {
 		return false
 	}
@@ -150,8 +155,8 @@ func (w *walker) load(item witem, u *unit) bool {
 
 // translate unit path into system path of directory where
 // source code for this unit is stored
-func (w *walker) resolve(path sx.Path) string {
-	o, s := path.Import()
+func (w *walker) resolve(item witem) string {
+	o, s := item.path.Import()
 	if s == "" {
 		// should be already reported during parsing
 		return ""
@@ -163,7 +168,22 @@ func (w *walker) resolve(path sx.Path) string {
 	case sx.Std:
 		return filepath.Join(w.std, s)
 	case sx.Pkg:
-		panic("stub")
+		if w.pkg == nil {
+			w.addError(&sx.Error{
+				Pin:   item.pin,
+				Short: fmt.Sprintf("unable to resolve unit \"%s\" without package manifest", item.path),
+			})
+			return ""
+		}
+		dir, err := w.pkg.Resolve(s)
+		if err != nil {
+			w.addError(&sx.Error{
+				Pin:   item.pin,
+				Short: err.Error(),
+			})
+			return ""
+		}
+		return dir
 	case sx.Loc:
 		// TODO: use absolute path for src?
 		return filepath.Join(w.loc, s)
@@ -1,6 +1,9 @@
 package genc
 
-import "github.com/mebyus/ku/internal/ku/stg"
+import (
+	"github.com/mebyus/ku/goku/compiler/genc"
+	"github.com/mebyus/ku/internal/ku/stg"
+)
 
 func (g *Buffer) funsig(name string, f *stg.FunDef) {
 	g.puts("static ")
@@ -43,3 +46,8 @@ func (g *Buffer) funstub(s *stg.Symbol) {
 	g.semi()
 	g.nl()
 }
+
+// Generates C entrypoint which calls program main function.
+func (g *Buffer) entry(main *stg.Symbol) {
+	g.puts(genc.Entry(g.getName(main), main.Def.(*stg.FunDef).Result != nil))
+}
@@ -3,13 +3,16 @@ package genc
 import (
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 
 	"github.com/mebyus/ku/internal/ku/stg"
 )
 
-func Gen(w io.Writer, prog *stg.Program) error {
-	err := copyFile(w, "src/std/core/prelude.c")
+// Gen writes C code of a given program into w. Output starts with contents
+// of C prelude located inside language root directory.
+func Gen(w io.Writer, langDir string, prog *stg.Program) error {
+	err := copyFile(w, filepath.Join(langDir, "src", "std", "core", "prelude.c"))
 	if err != nil {
 		return err
 	}
@@ -67,6 +70,10 @@ func (g *Buffer) Gen(prog *stg.Program) {
 			g.fun(f)
 			g.nl()
 		}
+
+		if prog.Main != nil && prog.Main.Scope == &u.Scope {
+			g.entry(prog.Main)
+		}
 	}
 }
 
@@ -11,6 +11,10 @@ type Program struct {
 	Common
 
 	Units []*Unit
+
+	// Main function of the program. Nil if program has no entrypoint
+	// (for example when it is compiled into object file).
+	Main *Symbol
 }
 
 type Unit struct {
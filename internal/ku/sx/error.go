@@ -1,6 +1,7 @@
 package sx
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 )
@@ -20,6 +21,46 @@ type Error struct {
 	//
 	// Equals 0 if such place cannot be specified.
 	Pin Pin
+
+	// Identifies kind of error in machine-readable output.
+	//
+	// Generic code is used if this field is empty.
+	Code string
+}
+
+// ErrorFormat specifies how errors are written to output.
+type ErrorFormat uint8
+
+const (
+	// Human readable text.
+	TextErrors ErrorFormat = iota
+
+	// One JSON object per line for each error.
+	JSONErrors
+)
+
+// ParseErrorFormat returns error format by its name.
+func ParseErrorFormat(s string) (ErrorFormat, error) {
+	switch s {
+	case "text":
+		return TextErrors, nil
+	case "json":
+		return JSONErrors, nil
+	default:
+		return 0, fmt.Errorf("unknown diagnostics format \"%s\"", s)
+	}
+}
+
+// WriteError writes error to output in a given format.
+func WriteError(pool *Pool, out io.Writer, format ErrorFormat, e *Error) {
+	switch format {
+	case TextErrors:
+		FormatError(pool, out, e)
+	case JSONErrors:
+		EncodeError(pool, out, e)
+	default:
+		panic(fmt.Sprintf("unexpected error format (=%d)", format))
+	}
 }
 
 func FormatError(pool *Pool, out io.Writer, e *Error) {
@@ -34,3 +75,46 @@ func FormatError(pool *Pool, out io.Writer, e *Error) {
 		fmt.Fprintf(out, "======\n%s\n======\n", e.Note)
 	}
 }
+
+// errorRecord is a machine-readable form of Error.
+//
+// Line and column are one-based, they are equal to 0 (along with
+// empty file) if error is not attributed to a place in source code.
+// Len equals 0 if length of source span is unknown.
+type errorRecord struct {
+	File     string `json:"file"`
+	Line     uint32 `json:"line"`
+	Column   uint32 `json:"column"`
+	Offset   uint32 `json:"offset"`
+	Len      uint32 `json:"len"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Note     string `json:"note"`
+	Code     string `json:"code"`
+}
+
+// EncodeError writes error to output as a single line JSON object.
+func EncodeError(pool *Pool, out io.Writer, e *Error) {
+	r := errorRecord{
+		Severity: "error",
+		Message:  e.Short,
+		Note:     e.Note,
+		Code:     e.Code,
+	}
+	if r.Code == "" {
+		r.Code = "generic"
+	}
+	if e.Pin != 0 {
+		pos := pool.DecodePin(e.Pin)
+		if pos.Path != "" {
+			r.File = pos.Path
+			r.Line = pos.Pos.Line + 1
+			r.Column = pos.Pos.Column + 1
+			r.Offset = e.Pin.Pos().Offset
+		}
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(r)
+}